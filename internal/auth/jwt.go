@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned for a token that is malformed, badly signed,
+// expired or issued for someone else.
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier checks HS256 and RS256 bearer tokens against a KeySet.
+type Verifier struct {
+	keys   *KeySet
+	parser *jwt.Parser
+}
+
+// NewVerifier returns a Verifier for keys. Tokens must carry an expiry, and
+// must name issuer and audience when those are non-empty.
+func NewVerifier(keys *KeySet, issuer, audience string) *Verifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+	return &Verifier{keys: keys, parser: jwt.NewParser(opts...)}
+}
+
+// claims are the registered claims plus the caller's roles, e.g. "admin".
+type claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Verify checks a raw token and returns the principal named by its subject,
+// which must be a user ID.
+func (v *Verifier) Verify(raw string) (Principal, error) {
+	var claims claims
+	if _, err := v.parser.ParseWithClaims(raw, &claims, v.key); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: subject is not a user ID", ErrInvalidToken)
+	}
+	return Principal{UserID: userID, Roles: claims.Roles}, nil
+}
+
+// key picks the verification key by algorithm family, so an RSA public key is
+// never used as an HMAC secret.
+func (v *Verifier) key(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	switch t.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return lookup(v.keys.hmac, kid)
+	case *jwt.SigningMethodRSA:
+		return lookup(v.keys.rsa, kid)
+	default:
+		return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+	}
+}
@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// APIKeyVerifier resolves the credential of an "Authorization: ApiKey"
+// header. It returns ErrInvalidToken for an unknown, revoked or expired key.
+type APIKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, key string) (Principal, error)
+}
+
+// Middleware authenticates requests with a bearer token or, when apiKeys is
+// not nil, an API key, and stores the caller's Principal in the request
+// context. Missing or invalid credentials get 401. A bearer token may only
+// use its own user's /users/{userId} routes; an API key may use any user's,
+// within its scopes, and nothing else. Other requests get 403.
+//
+// It reads route variables, so it must run after routing, e.g. via
+// mux.Router.Use.
+func Middleware(jwt *Verifier, apiKeys APIKeyVerifier) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scheme, credential, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+			credential = strings.TrimSpace(credential)
+
+			var p Principal
+			var err error
+			switch {
+			case credential == "":
+				unauthorized(w, "Missing credentials")
+				return
+			case strings.EqualFold(scheme, "Bearer"):
+				p, err = jwt.Verify(credential)
+			case strings.EqualFold(scheme, "ApiKey") && apiKeys != nil:
+				p, err = apiKeys.VerifyAPIKey(r.Context(), credential)
+			default:
+				unauthorized(w, "Unsupported authorization scheme")
+				return
+			}
+			if errors.Is(err, ErrInvalidToken) {
+				unauthorized(w, "Invalid credentials")
+				return
+			}
+			if err != nil {
+				log.Println("Failed to verify credentials:", err)
+				http.Error(w, "Could not verify credentials", http.StatusServiceUnavailable)
+				return
+			}
+
+			if !allowed(r, p) {
+				http.Error(w, "Credentials do not grant access to this resource", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+		})
+	}
+}
+
+// allowed applies the route rules of Middleware.
+func allowed(r *http.Request, p Principal) bool {
+	_, isUserRoute := userRoute(r)
+	if p.IsAPIKey() {
+		return isUserRoute && p.HasScope(RequiredScope(r))
+	}
+	if !isUserRoute {
+		return true
+	}
+	id, err := uuid.Parse(mux.Vars(r)["userId"])
+	return err == nil && id == p.UserID
+}
+
+// RequireRole rejects callers without role with 403. It must run after
+// Middleware.
+func RequireRole(role string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := FromContext(r.Context())
+			if !ok {
+				unauthorized(w, "Missing credentials")
+				return
+			}
+			if !p.HasRole(role) {
+				http.Error(w, "The "+role+" role is required", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Add("WWW-Authenticate", `Bearer realm="assets"`)
+	w.Header().Add("WWW-Authenticate", `ApiKey realm="assets"`)
+	http.Error(w, msg, http.StatusUnauthorized)
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated caller of a request: a user with a bearer
+// token, or a service with an API key.
+type Principal struct {
+	UserID uuid.UUID // the token subject; zero for API keys
+	Roles  []string  // from the token's "roles" claim
+
+	APIKeyID uuid.UUID // zero for bearer tokens
+	Scopes   []string  // what an API key may do, e.g. "assets:read"
+}
+
+// IsAPIKey reports whether p authenticated with an API key.
+func (p Principal) IsAPIKey() bool {
+	return p.APIKeyID != uuid.Nil
+}
+
+func (p Principal) HasRole(role string) bool {
+	return slices.Contains(p.Roles, role)
+}
+
+func (p Principal) HasScope(scope string) bool {
+	return scope != "" && slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying p.
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal the auth middleware stored in ctx.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// The scopes an API key can be granted. Each covers one resource under
+// /users/{userId}; read allows GET and HEAD, write everything else.
+const (
+	ScopeAssetsRead      = "assets:read"
+	ScopeAssetsWrite     = "assets:write"
+	ScopeFavouritesRead  = "favourites:read"
+	ScopeFavouritesWrite = "favourites:write"
+)
+
+// Scopes lists every valid scope.
+var Scopes = []string{ScopeAssetsRead, ScopeAssetsWrite, ScopeFavouritesRead, ScopeFavouritesWrite}
+
+func ValidScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+// resourceScopes maps the path segment after {userId} to the resource its
+// routes belong to. Shares, shared-with-me listings, collections and the tag
+// cloud count as assets.
+var resourceScopes = map[string]string{
+	"assets":      "assets",
+	"shared":      "assets",
+	"collections": "assets",
+	"tags":        "assets",
+	"favourites":  "favourites",
+}
+
+// RequiredScope returns the scope an API key needs for the matched route, or
+// "" if API keys may not use it.
+func RequiredScope(r *http.Request) string {
+	segments, ok := userRoute(r)
+	if !ok || len(segments) < 3 {
+		return ""
+	}
+	resource, ok := resourceScopes[segments[2]]
+	if !ok {
+		return ""
+	}
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return resource + ":read"
+	}
+	return resource + ":write"
+}
+
+// userRoute returns the path template segments of the matched route if it
+// is one of the /users/{userId}/... routes a user's token is bound to.
+func userRoute(r *http.Request) ([]string, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return nil, false
+	}
+	tmpl, err := route.GetPathTemplate()
+	if err != nil {
+		return nil, false
+	}
+	segments := strings.Split(strings.Trim(tmpl, "/"), "/")
+	if len(segments) < 2 || segments[0] != "users" || segments[1] != "{userId}" {
+		return nil, false
+	}
+	return segments, true
+}
@@ -4,13 +4,65 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	PostgresURL string
-	RedisAddr   string
+
+	// Redis: one server, a master found through Sentinels or a cluster; see
+	// storage.RedisOptions. RedisAddrs lists the server, the Sentinels or
+	// some of the cluster's nodes.
+	RedisAddrs            []string
+	RedisSentinelMaster   string
+	RedisCluster          bool
+	RedisUsername         string
+	RedisPassword         string
+	RedisSentinelUsername string
+	RedisSentinelPassword string
+	RedisDB               int
+	RedisTLS              bool
+	RedisTLSCAFile        string
+	RedisTLSServerName    string
+	RedisPoolSize         int // 0 leaves it to go-redis
+	RedisDialTimeout      time.Duration
+	RedisReadTimeout      time.Duration
+	RedisWriteTimeout     time.Duration
+
+	// How long Redis keeps cached entries.
+	CacheTTL time.Duration
+
+	// How long cached entries stay fresh before they are refreshed in the
+	// background, and how eagerly they are refreshed early; see
+	// storage.CacheOptions.
+	CacheSoftTTL     time.Duration
+	CacheEarlyExpiry float64
+
+	// Size of the in-process cache in front of Redis, in entries, and how
+	// long it keeps an entry. A size of 0 disables it.
+	CacheLocalSize int
+	CacheLocalTTL  time.Duration
+
+	// Circuit breaker around Redis: how many failed calls in a row open it,
+	// how long it stays open and how long a call may take before it counts
+	// as failed. Invalidations that fail are retried every
+	// CacheRepairInterval.
+	CacheBreakerFailures int
+	CacheBreakerCooldown time.Duration
+	CacheTimeout         time.Duration
+	CacheRepairInterval  time.Duration
+
+	// Bearer token verification. At least one of JWTSecret (HS256),
+	// JWTPublicKeyFile (RS256, PEM) and JWKSFile must be set.
+	JWTSecret        string
+	JWTPublicKeyFile string
+	JWKSFile         string
+	JWTIssuer        string // required "iss" claim, if set
+	JWTAudience      string // required "aud" claim, if set
 }
 
 func LoadConfig() *Config {
@@ -22,15 +74,49 @@ func LoadConfig() *Config {
 	dbName := os.Getenv("POSTGRES_DB")
 	host := os.Getenv("POSTGRES_HOST")
 	port := os.Getenv("POSTGRES_PORT")
-	redisAddr := os.Getenv("REDIS_ADDR")
+	redisAddrs := splitList(os.Getenv("REDIS_ADDR"))
+	jwtSecret := os.Getenv("JWT_HMAC_SECRET")
+	jwtPublicKeyFile := os.Getenv("JWT_PUBLIC_KEY_FILE")
+	jwksFile := os.Getenv("JWT_JWKS_FILE")
 
 	// Validate
 	if user == "" || password == "" || dbName == "" || host == "" || port == "" {
 		log.Fatal("Postgres environment variables are not set properly")
 	}
-	if redisAddr == "" {
+	if len(redisAddrs) == 0 {
 		log.Fatal("REDIS_ADDR is not set")
 	}
+	if jwtSecret == "" && jwtPublicKeyFile == "" && jwksFile == "" {
+		log.Fatal("No JWT keys configured: set JWT_HMAC_SECRET, JWT_PUBLIC_KEY_FILE or JWT_JWKS_FILE")
+	}
+
+	cacheTTL := positiveDuration("CACHE_TTL", 5*time.Minute)
+	cacheSoftTTL := time.Minute
+	if v := os.Getenv("CACHE_SOFT_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			log.Fatalf("CACHE_SOFT_TTL must be a non-negative duration such as 30s, got %q", v)
+		}
+		cacheSoftTTL = d
+	}
+	// Past CACHE_TTL entries are gone before they go stale
+	if cacheSoftTTL >= cacheTTL {
+		log.Fatalf("CACHE_SOFT_TTL (%v) must be shorter than CACHE_TTL (%v)", cacheSoftTTL, cacheTTL)
+	}
+	cacheEarlyExpiry := 1.0
+	if v := os.Getenv("CACHE_EARLY_EXPIRY"); v != "" {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 {
+			log.Fatalf("CACHE_EARLY_EXPIRY must be a non-negative number, got %q", v)
+		}
+		cacheEarlyExpiry = f
+	}
+	cacheLocalSize := intAtLeast("CACHE_LOCAL_SIZE", 0, 0)
+	cacheLocalTTL := positiveDuration("CACHE_LOCAL_TTL", 10*time.Second)
+	cacheBreakerFailures := intAtLeast("CACHE_BREAKER_FAILURES", 5, 1)
+	cacheBreakerCooldown := positiveDuration("CACHE_BREAKER_COOLDOWN", 5*time.Second)
+	cacheTimeout := positiveDuration("CACHE_TIMEOUT", 250*time.Millisecond)
+	cacheRepairInterval := positiveDuration("CACHE_REPAIR_INTERVAL", 5*time.Second)
 
 	// Build connection string dynamically
 	postgresURL := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
@@ -38,6 +124,92 @@ func LoadConfig() *Config {
 
 	return &Config{
 		PostgresURL: postgresURL,
-		RedisAddr:   redisAddr,
+
+		RedisAddrs:            redisAddrs,
+		RedisSentinelMaster:   os.Getenv("REDIS_SENTINEL_MASTER"),
+		RedisCluster:          boolean("REDIS_CLUSTER"),
+		RedisUsername:         os.Getenv("REDIS_USERNAME"),
+		RedisPassword:         os.Getenv("REDIS_PASSWORD"),
+		RedisSentinelUsername: os.Getenv("REDIS_SENTINEL_USERNAME"),
+		RedisSentinelPassword: os.Getenv("REDIS_SENTINEL_PASSWORD"),
+		RedisDB:               intAtLeast("REDIS_DB", 0, 0),
+		RedisTLS:              boolean("REDIS_TLS"),
+		RedisTLSCAFile:        os.Getenv("REDIS_TLS_CA_FILE"),
+		RedisTLSServerName:    os.Getenv("REDIS_TLS_SERVER_NAME"),
+		RedisPoolSize:         intAtLeast("REDIS_POOL_SIZE", 0, 0),
+		RedisDialTimeout:      positiveDuration("REDIS_DIAL_TIMEOUT", 5*time.Second),
+		RedisReadTimeout:      positiveDuration("REDIS_READ_TIMEOUT", 3*time.Second),
+		RedisWriteTimeout:     positiveDuration("REDIS_WRITE_TIMEOUT", 3*time.Second),
+
+		CacheTTL: cacheTTL,
+
+		CacheSoftTTL:     cacheSoftTTL,
+		CacheEarlyExpiry: cacheEarlyExpiry,
+		CacheLocalSize:   cacheLocalSize,
+		CacheLocalTTL:    cacheLocalTTL,
+
+		CacheBreakerFailures: cacheBreakerFailures,
+		CacheBreakerCooldown: cacheBreakerCooldown,
+		CacheTimeout:         cacheTimeout,
+		CacheRepairInterval:  cacheRepairInterval,
+
+		JWTSecret:        jwtSecret,
+		JWTPublicKeyFile: jwtPublicKeyFile,
+		JWKSFile:         jwksFile,
+		JWTIssuer:        os.Getenv("JWT_ISSUER"),
+		JWTAudience:      os.Getenv("JWT_AUDIENCE"),
+	}
+}
+
+// positiveDuration reads a duration such as 5s from the environment, or
+// returns def if the variable is unset.
+func positiveDuration(name string, def time.Duration) time.Duration {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Fatalf("%s must be a positive duration such as %v, got %q", name, def, v)
+	}
+	return d
+}
+
+// intAtLeast reads a whole number no smaller than min from the environment,
+// or returns def if the variable is unset.
+func intAtLeast(name string, def, min int) int {
+	v := os.Getenv(name)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min {
+		log.Fatalf("%s must be a whole number of at least %d, got %q", name, min, v)
+	}
+	return n
+}
+
+// boolean reads a flag such as true or 1 from the environment; unset is
+// false.
+func boolean(name string) bool {
+	v := os.Getenv(name)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		log.Fatalf("%s must be true or false, got %q", name, v)
+	}
+	return b
+}
+
+// splitList splits a comma-separated list, dropping blanks.
+func splitList(v string) []string {
+	var items []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler serves the /admin operations that span users. Reading a
+// single user's assets and favourites reuses AssetHandler and
+// FavouriteHandler under /admin/users/{userId}.
+type AdminHandler struct {
+	service *assetServices.AssetService
+}
+
+func NewAdminHandler(service *assetServices.AssetService) *AdminHandler {
+	return &AdminHandler{service: service}
+}
+
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	opts, err := parseUserListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.ListUsers(r.Context(), opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []models.User{}
+	}
+	writeListing(w, r, page)
+}
+
+// ReassignAsset makes the user in the body, {"user_id": "..."}, the owner of
+// the asset.
+func (h *AdminHandler) ReassignAsset(w http.ResponseWriter, r *http.Request) {
+	assetID := mux.Vars(r)["assetId"]
+	var body struct {
+		UserID uuid.UUID `json:"user_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.UserID == uuid.Nil {
+		writeError(w, &models.ValidationError{Violations: []models.FieldError{{Field: "user_id", Message: "is required"}}})
+		return
+	}
+
+	previous, err := h.service.ReassignAsset(r.Context(), assetID, body.UserID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Asset %s reassigned from %v to %v", assetID, previous, body.UserID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"asset_id":          assetID,
+		"owner_id":          body.UserID,
+		"previous_owner_id": previous,
+	})
+}
+
+// PurgeUser deletes the user, their assets and favourites, and every share
+// to or from them.
+func (h *AdminHandler) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.PurgeUser(r.Context(), userID); err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("User %v purged", userID)
+	w.WriteHeader(http.StatusOK)
+}
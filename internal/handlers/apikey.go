@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	apiKeyServices "assetsApp/internal/services/apikey"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	service *apiKeyServices.APIKeyService
+}
+
+func NewAPIKeyHandler(service *apiKeyServices.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// CreateAPIKey issues a key. The response is the only time the full key is
+// shown.
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name      string     `json:"name"`
+		Scopes    []string   `json:"scopes"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	key, secret, err := h.service.CreateKey(r.Context(), body.Name, body.Scopes, body.ExpiresAt)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("API key %s (%s) created with scopes %v", key.Prefix, key.Name, key.Scopes)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/admin/api-keys/"+key.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(struct {
+		models.APIKey
+		Key string `json:"key"`
+	}{key, secret})
+}
+
+func (h *APIKeyHandler) GetAPIKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.ListKeys(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey stops a key from being accepted. The key stays listed, with
+// its revoked_at time.
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(mux.Vars(r)["keyId"])
+	if err != nil {
+		http.Error(w, "Invalid API key ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RevokeKey(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("API key %v revoked", id)
+	w.WriteHeader(http.StatusOK)
+}
@@ -5,7 +5,9 @@ import (
 	assetServices "assetsApp/internal/services/asset"
 	"encoding/json"
 	"log"
+	"mime"
 	"net/http"
+	"net/url"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -28,13 +30,82 @@ func (h *AssetHandler) GetAssets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := parseAssetFilters(r, &opts); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	log.Printf("GetAssets called for user %v", userID)
-	favs := h.service.GetAssets(userID)
-	json.NewEncoder(w).Encode(favs)
+	page, err := h.service.GetAssets(r.Context(), userID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []models.Asset{}
+	}
+	writeListing(w, r, page)
 	log.Printf("GetAssets completed for user %v", userID)
 
 }
 
+// SearchAssets runs a full-text search over the user's assets. It takes the
+// query in q and an optional limit, and answers an items envelope of results.
+func (h *AssetHandler) SearchAssets(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	results, err := h.service.SearchAssets(r.Context(), userID, r.URL.Query().Get("q"), limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if results == nil {
+		results = []models.SearchResult{}
+	}
+	writeListing(w, r, struct {
+		Items []models.SearchResult `json:"items"`
+	}{results})
+}
+
+// GetAsset returns one asset, with a "type" field naming its asset type.
+func (h *AssetHandler) GetAsset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.service.GetAsset(r.Context(), userID, assetID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if notModified(r, assetETag(asset)) {
+		w.Header().Set("ETag", assetETag(asset))
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	writeAsset(w, asset)
+}
+
 func (h *AssetHandler) AddAsset(w http.ResponseWriter, r *http.Request) {
 	userIDStr := mux.Vars(r)["userId"]
 	userID, err := uuid.Parse(userIDStr)
@@ -57,16 +128,23 @@ func (h *AssetHandler) AddAsset(w http.ResponseWriter, r *http.Request) {
 	}
 	// log.Printf("Adding asset of type %s for user %v", assetType, userID)
 
+	// Clients may leave the ID to the server.
+	if id, present := body["id"]; !present || id == nil || id == "" {
+		body["id"] = uuid.NewString()
+	}
+
 	asset, err := models.CreateAsset(assetType, body)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, err)
 		return
 	}
 
-	h.service.AddAsset(userID, asset)
-	w.WriteHeader(http.StatusCreated)
-
-	json.NewEncoder(w).Encode(asset)
+	if err := h.service.AddAsset(r.Context(), userID, asset); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Location", assetLocation(userID, asset.GetID()))
+	writeAssetStatus(w, http.StatusCreated, asset)
 }
 
 func (h *AssetHandler) RemoveAsset(w http.ResponseWriter, r *http.Request) {
@@ -79,36 +157,145 @@ func (h *AssetHandler) RemoveAsset(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	log.Printf("RemoveAsset called for user %v, asset %s", userID, assetID)
-	if !h.service.RemoveAsset(userID, assetID) {
-		http.Error(w, "Asset not found", http.StatusNotFound)
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		writePreconditionFailed(w)
+		return
+	}
+	if err := h.service.RemoveAsset(r.Context(), userID, assetID, version); err != nil {
+		writeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
 	log.Printf("RemoveAsset completed for user %v, asset %s", userID, assetID)
 }
 
-func (h *AssetHandler) EditAsset(w http.ResponseWriter, r *http.Request) {
+// ReplaceAsset handles PUT: the body is a complete asset of the same type,
+// as accepted by AddAsset. The id may be omitted but must otherwise match
+// the URL.
+func (h *AssetHandler) ReplaceAsset(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
-	userIDStr := vars["userId"]
 	assetID := vars["assetId"]
-	userID, err := uuid.Parse(userIDStr)
+	userID, err := uuid.Parse(vars["userId"])
 	if err != nil {
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
-	log.Printf("EditAsset called for user %v, asset %s", userID, assetID)
-	var body struct {
-		Description string `json:"description"`
+	log.Printf("ReplaceAsset called for user %v, asset %s", userID, assetID)
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		writePreconditionFailed(w)
+		return
 	}
+
+	var body map[string]interface{}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	assetType, ok := body["type"].(string)
+	if !ok {
+		http.Error(w, "Asset type required", http.StatusBadRequest)
+		return
+	}
+	if id, present := body["id"]; !present || id == nil {
+		body["id"] = assetID
+	} else if id != assetID {
+		writeError(w, &models.ValidationError{Violations: []models.FieldError{
+			{Field: "id", Message: "must match the asset ID in the URL"},
+		}})
+		return
+	}
 
-	if !h.service.EditDescription(userID, assetID, body.Description) {
-		http.Error(w, "Asset not found", http.StatusNotFound)
+	asset, err := models.CreateAsset(assetType, body)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
-	w.WriteHeader(http.StatusOK)
-	log.Printf("EditAsset completed for user %v, asset %s", userID, assetID)
+	asset.SetVersion(version)
+	if err := h.service.ReplaceAsset(r.Context(), userID, asset); err != nil {
+		writeError(w, err)
+		return
+	}
+	writeAsset(w, asset)
+	log.Printf("ReplaceAsset completed for user %v, asset %s", userID, assetID)
+}
+
+// PatchAsset handles PATCH with an application/merge-patch+json body
+// (RFC 7386). Fields set to null are cleared; nested objects are merged and
+// arrays are replaced.
+func (h *AssetHandler) PatchAsset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	log.Printf("PatchAsset called for user %v, asset %s", userID, assetID)
+	version, ok := ifMatchVersion(r)
+	if !ok {
+		writePreconditionFailed(w)
+		return
+	}
+
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != mergePatchType {
+		w.Header().Set("Accept-Patch", mergePatchType)
+		http.Error(w, "Content-Type must be "+mergePatchType, http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.service.PatchAsset(r.Context(), userID, assetID, patch, version)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeAsset(w, asset)
+	log.Printf("PatchAsset completed for user %v, asset %s", userID, assetID)
+}
+
+const mergePatchType = "application/merge-patch+json"
+
+// writeAsset responds with an asset, its "type" field and its ETag.
+func writeAsset(w http.ResponseWriter, asset models.Asset) {
+	writeAssetStatus(w, http.StatusOK, asset)
+}
+
+// writeAssetStatus is writeAsset with another status than 200 OK.
+func writeAssetStatus(w http.ResponseWriter, status int, asset models.Asset) {
+	body, err := withTypeField(asset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", assetETag(asset))
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// assetLocation is the URL of a single asset.
+func assetLocation(userID uuid.UUID, assetID string) string {
+	return "/users/" + userID.String() + "/assets/" + url.PathEscape(assetID)
+}
+
+// withTypeField encodes an asset as a JSON object with its type discriminator
+// added under "type", mirroring the body accepted by AddAsset.
+func withTypeField(asset models.Asset) ([]byte, error) {
+	raw, err := json.Marshal(asset)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(asset.GetType())
+	return json.Marshal(fields)
 }
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	collectionServices "assetsApp/internal/services/collection"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type CollectionHandler struct {
+	service *collectionServices.CollectionService
+}
+
+func NewCollectionHandler(service *collectionServices.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+func (h *CollectionHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	collections, err := h.service.GetCollections(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if collections == nil {
+		collections = []models.Collection{}
+	}
+	json.NewEncoder(w).Encode(collections)
+}
+
+func (h *CollectionHandler) GetCollection(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+
+	collection, err := h.service.GetCollection(r.Context(), userID, collectionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(collection)
+}
+
+// CreateCollection adds an empty collection. The body is {"name": "..."}.
+func (h *CollectionHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.service.CreateCollection(r.Context(), userID, body.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Collection %v (%s) created for user %v", collection.ID, collection.Name, userID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Location", "/users/"+userID.String()+"/collections/"+collection.ID.String())
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(collection)
+}
+
+// RenameCollection changes a collection's name. The body is {"name": "..."}.
+func (h *CollectionHandler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collection, err := h.service.RenameCollection(r.Context(), userID, collectionID, body.Name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(collection)
+}
+
+func (h *CollectionHandler) RemoveCollection(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveCollection(r.Context(), userID, collectionID); err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Collection %v removed for user %v", collectionID, userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCollectionAssets lists the assets in a collection, in collection order.
+func (h *CollectionHandler) GetCollectionAssets(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+
+	assets, err := h.service.GetCollectionAssets(r.Context(), userID, collectionID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if assets == nil {
+		assets = []models.Asset{}
+	}
+	writeListing(w, r, struct {
+		Items []models.Asset `json:"items"`
+	}{assets})
+}
+
+// SetCollectionAssets replaces the collection's members. The body is
+// {"asset_ids": [...]}; the order given becomes the collection order.
+func (h *CollectionHandler) SetCollectionAssets(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		AssetIDs []string `json:"asset_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.AssetIDs == nil {
+		body.AssetIDs = []string{}
+	}
+
+	collection, err := h.service.SetAssets(r.Context(), userID, collectionID, body.AssetIDs)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(collection)
+}
+
+// AddCollectionAsset appends an asset to a collection. Adding an asset that
+// is already a member leaves the collection as it is.
+func (h *CollectionHandler) AddCollectionAsset(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+	assetID := mux.Vars(r)["assetId"]
+
+	collection, err := h.service.AddAsset(r.Context(), userID, collectionID, assetID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Asset %s added to collection %v for user %v", assetID, collectionID, userID)
+	json.NewEncoder(w).Encode(collection)
+}
+
+func (h *CollectionHandler) RemoveCollectionAsset(w http.ResponseWriter, r *http.Request) {
+	userID, collectionID, ok := collectionVars(w, r)
+	if !ok {
+		return
+	}
+	assetID := mux.Vars(r)["assetId"]
+
+	if err := h.service.RemoveAsset(r.Context(), userID, collectionID, assetID); err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Asset %s removed from collection %v for user %v", assetID, collectionID, userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// collectionVars parses the user and collection IDs from the URL, answering
+// 400 if either is malformed.
+func collectionVars(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	collectionID, err := uuid.Parse(vars["collectionId"])
+	if err != nil {
+		http.Error(w, "Invalid collection ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, collectionID, true
+}
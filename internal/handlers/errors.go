@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// writeError maps a service/store error onto an HTTP status code. Asset
+// validation errors are reported as a JSON list of violations.
+func writeError(w http.ResponseWriter, err error) {
+	var verr *models.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(verr)
+	case errors.Is(err, storage.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, storage.ErrConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, storage.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, storage.ErrPreconditionFailed):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, storage.ErrValidation):
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, storage.ErrUnavailable), errors.Is(err, context.DeadlineExceeded):
+		log.Printf("Store unavailable: %v", err)
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+	default:
+		log.Printf("Unexpected error: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// assetETag is the strong entity tag of an asset: its stored version.
+func assetETag(asset models.Asset) string {
+	return `"` + strconv.FormatInt(asset.GetVersion(), 10) + `"`
+}
+
+// ifMatchVersion returns the asset version a request's If-Match header
+// requires, or storage.AnyVersion when there is no header or it is "*". The
+// result is false when the header can never match an asset's ETag (a weak,
+// malformed or multi-valued tag), in which case the precondition fails.
+func ifMatchVersion(r *http.Request) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return storage.AnyVersion, true
+	}
+	if len(header) < 2 || header[0] != '"' || header[len(header)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseInt(header[1:len(header)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, false
+	}
+	return version, true
+}
+
+// writePreconditionFailed rejects a request whose If-Match header can't match.
+func writePreconditionFailed(w http.ResponseWriter) {
+	http.Error(w, "If-Match does not match the current asset version", http.StatusPreconditionFailed)
+}
+
+// notModified reports whether the request's If-None-Match header matches
+// etag, using the weak comparison RFC 9110 prescribes for GET.
+func notModified(r *http.Request, etag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" {
+		return false
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// writeListing encodes a page of results with a weak ETag computed over the
+// body, and answers 304 Not Modified if the client already has it.
+func writeListing(w http.ResponseWriter, r *http.Request, page interface{}) {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(page); err != nil {
+		writeError(w, err)
+		return
+	}
+	sum := sha256.Sum256(body.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if notModified(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Write(body.Bytes())
+}
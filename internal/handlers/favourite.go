@@ -25,12 +25,21 @@ func (h *FavouriteHandler) GetFavourites(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Invalid user ID", http.StatusBadRequest)
 		return
 	}
-	favs := h.service.GetFavourites(userID)
-	if favs == nil {
-		json.NewEncoder(w).Encode([]models.Favourite{})
+	opts, err := parseFavouriteListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetFavourites(r.Context(), userID, opts)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
-	json.NewEncoder(w).Encode(favs)
+	if page.Items == nil {
+		page.Items = []models.Favourite{}
+	}
+	writeListing(w, r, page)
 }
 
 func (h *FavouriteHandler) AddFavourite(w http.ResponseWriter, r *http.Request) {
@@ -52,12 +61,17 @@ func (h *FavouriteHandler) AddFavourite(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if !h.service.AddFavourite(userID, assetID, body.AssetType) {
-		http.Error(w, "Could not add favourite", http.StatusNotFound)
+	created, err := h.service.AddFavourite(r.Context(), userID, assetID, body.AssetType)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
 
 func (h *FavouriteHandler) RemoveFavourite(w http.ResponseWriter, r *http.Request) {
@@ -71,8 +85,57 @@ func (h *FavouriteHandler) RemoveFavourite(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	if !h.service.RemoveFavourite(userID, assetID) {
-		http.Error(w, "Asset not found", http.StatusNotFound)
+	if err := h.service.RemoveFavourite(r.Context(), userID, assetID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UpdateFavourite pins or unpins a favourite and sets its private note. The
+// body is {"pinned": true, "note": "..."}; fields left out are unchanged.
+func (h *FavouriteHandler) UpdateFavourite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var update models.FavouriteUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UpdateFavourite(r.Context(), userID, vars["assetId"], update); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// MoveFavourite reorders a favourite relative to another one. The body is
+// {"before": "<assetId>"} or {"after": "<assetId>"}.
+func (h *FavouriteHandler) MoveFavourite(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Before string `json:"before"`
+		After  string `json:"after"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.MoveFavourite(r.Context(), userID, vars["assetId"], body.Before, body.After); err != nil {
+		writeError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusOK)
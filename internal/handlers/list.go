@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// parseListOptions reads the limit, cursor and sort query parameters shared
+// by the listing endpoints.
+func parseListOptions(r *http.Request) (storage.ListOptions, error) {
+	return parseSortedListOptions(r, storage.ParseSort, "id, title, type, created_at")
+}
+
+// parseFavouriteListOptions is parseListOptions for favourites, which can
+// also be sorted by position.
+func parseFavouriteListOptions(r *http.Request) (storage.ListOptions, error) {
+	return parseSortedListOptions(r, storage.ParseFavouriteSort, "position, id, title, type, created_at")
+}
+
+// parseUserListOptions is parseListOptions for users, which can only be
+// sorted by id.
+func parseUserListOptions(r *http.Request) (storage.ListOptions, error) {
+	return parseSortedListOptions(r, storage.ParseUserSort, "id")
+}
+
+func parseSortedListOptions(r *http.Request, parseSort func(string) (storage.SortField, bool, error), sorts string) (storage.ListOptions, error) {
+	q := r.URL.Query()
+	var opts storage.ListOptions
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		return opts, err
+	}
+	opts.Limit = limit
+
+	sort, desc, err := parseSort(q.Get("sort"))
+	if err != nil {
+		return opts, fmt.Errorf("sort must be one of %s (prefix with - for descending)", sorts)
+	}
+	opts.Sort, opts.Desc = sort, desc
+	opts.Cursor = q.Get("cursor")
+
+	if err := opts.Validate(); err != nil {
+		return opts, err
+	}
+	return opts, nil
+}
+
+// parseLimit reads the limit query parameter, returning 0 if it is absent.
+func parseLimit(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit < 1 || limit > storage.MaxPageLimit {
+		return 0, fmt.Errorf("limit must be an integer between 1 and %d", storage.MaxPageLimit)
+	}
+	return limit, nil
+}
+
+// listingParams are the query parameters of the asset listing that aren't
+// attribute filters.
+var listingParams = map[string]bool{
+	"limit": true, "cursor": true, "sort": true,
+	"collection": true, "tag": true, "tag_match": true, "type": true,
+}
+
+// parseAssetFilters reads the query parameters that only the asset listing
+// takes: collection, tag (repeatable), tag_match, type and the attribute
+// filters of that type, such as country=GR or purchases_lt=10.
+func parseAssetFilters(r *http.Request, opts *storage.ListOptions) error {
+	q := r.URL.Query()
+
+	if v := q.Get("collection"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return fmt.Errorf("collection must be a collection ID")
+		}
+		opts.Collection = id
+	}
+
+	for _, tag := range q["tag"] {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return fmt.Errorf("tag must not be empty")
+		}
+		opts.Tags = append(opts.Tags, tag)
+	}
+	switch q.Get("tag_match") {
+	case "", "all":
+	case "any":
+		opts.AnyTag = true
+	default:
+		return fmt.Errorf("tag_match must be all or any")
+	}
+
+	if v := q.Get("type"); v != "" {
+		if _, ok := models.LookupType(v); !ok {
+			return fmt.Errorf("type must be one of %s", strings.Join(models.TypeNames(), ", "))
+		}
+		opts.Type = v
+	}
+
+	// Once a filterable type is chosen every other parameter must be one of
+	// its filters, so a misspelt one isn't silently ignored.
+	strict := storage.HasFilterFields(opts.Type)
+	keys := make([]string, 0, len(q))
+	for key := range q {
+		if !listingParams[key] && (strict || storage.IsFilterKey(key)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cond, err := storage.ParseCondition(opts.Type, key, q[key])
+		if err != nil {
+			return err
+		}
+		opts.Where = append(opts.Where, cond)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	shareServices "assetsApp/internal/services/share"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type ShareHandler struct {
+	service *shareServices.ShareService
+}
+
+func NewShareHandler(service *shareServices.ShareService) *ShareHandler {
+	return &ShareHandler{service: service}
+}
+
+func (h *ShareHandler) GetShares(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	shares, err := h.service.GetShares(r.Context(), userID, vars["assetId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if shares == nil {
+		shares = []models.Share{}
+	}
+	json.NewEncoder(w).Encode(shares)
+}
+
+// ShareAsset grants the user in the URL access to the asset. The body is
+// {"permission": "viewer"} or {"permission": "editor"}; sending it again
+// changes the permission.
+func (h *ShareHandler) ShareAsset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	granteeID, err := uuid.Parse(vars["granteeId"])
+	if err != nil {
+		http.Error(w, "Invalid grantee user ID", http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Permission models.Permission `json:"permission"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	share, err := h.service.ShareAsset(r.Context(), userID, assetID, granteeID, body.Permission)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Asset %s shared by %v with %v as %s", assetID, userID, granteeID, share.Permission)
+	json.NewEncoder(w).Encode(share)
+}
+
+func (h *ShareHandler) UnshareAsset(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	granteeID, err := uuid.Parse(vars["granteeId"])
+	if err != nil {
+		http.Error(w, "Invalid grantee user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.UnshareAsset(r.Context(), userID, assetID, granteeID); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetSharedWithMe lists the assets other users have shared with the user,
+// with the same paging parameters as GetAssets.
+func (h *ShareHandler) GetSharedWithMe(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+	opts, err := parseListOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.service.GetSharedWithMe(r.Context(), userID, opts)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if page.Items == nil {
+		page.Items = []models.SharedAsset{}
+	}
+	writeListing(w, r, page)
+}
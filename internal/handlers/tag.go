@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"assetsApp/internal/models"
+	tagServices "assetsApp/internal/services/tag"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type TagHandler struct {
+	service *tagServices.TagService
+}
+
+func NewTagHandler(service *tagServices.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := h.service.GetTags(r.Context(), userID, vars["assetId"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	json.NewEncoder(w).Encode(tags)
+}
+
+// AddTag tags the asset with the tag in the URL and responds with all its
+// tags. Adding a tag the asset already has changes nothing.
+func (h *TagHandler) AddTag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	tags, err := h.service.AddTag(r.Context(), userID, assetID, vars["tag"])
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Asset %s tagged %q by user %v", assetID, vars["tag"], userID)
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *TagHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	assetID := vars["assetId"]
+	userID, err := uuid.Parse(vars["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RemoveTag(r.Context(), userID, assetID, vars["tag"]); err != nil {
+		writeError(w, err)
+		return
+	}
+	log.Printf("Tag %q removed from asset %s by user %v", vars["tag"], assetID, userID)
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetTagCloud lists every tag on the user's assets with how many assets
+// carry it, most used first.
+func (h *TagHandler) GetTagCloud(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["userId"])
+	if err != nil {
+		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		return
+	}
+
+	cloud, err := h.service.GetTagCloud(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if cloud == nil {
+		cloud = []models.TagCount{}
+	}
+	json.NewEncoder(w).Encode(cloud)
+}
@@ -0,0 +1,266 @@
+// Package migrations applies the versioned SQL files embedded under sql/ to
+// the Postgres database. Each migration is a pair of files named
+// NNNN_name.up.sql and NNNN_name.down.sql; applied versions are recorded in
+// the schema_migrations table together with a checksum of the up script.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// lockID is the pg_advisory_lock key that serialises concurrent migrators.
+const lockID = 7_246_518_390
+
+// ErrChecksumMismatch is returned when an applied migration no longer matches
+// the embedded script it was applied from.
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+// Migration is one embedded schema change.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// Load reads and validates the embedded migrations, ordered by version.
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, e := range entries {
+		name := e.Name()
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migrations: unexpected file %s", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		prefix, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migrations: %s must be named NNNN_name.%s.sql", name, direction)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migrations: %s has an invalid version", name)
+		}
+
+		body, err := files.ReadFile(path.Join("sql", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migrations: version %d is used by both %s and %s", version, m.Name, label)
+		}
+		if direction == "up" {
+			m.Up = string(body)
+			sum := sha256.Sum256(body)
+			m.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrations: version %d (%s) needs both an up and a down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migrations: expected version %d, found %d (%s)", i+1, m.Version, m.Name)
+		}
+	}
+	return migrations, nil
+}
+
+// Migrator applies migrations to one database.
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Up applies every pending migration in order. Each migration runs in its own
+// transaction, and previously applied ones are checked against their checksum
+// first so that edited migrations are caught instead of silently skipped.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; ok {
+				continue
+			}
+			log.Printf("migrations: applying %04d_%s", mig.Version, mig.Name)
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx,
+					"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
+					mig.Version, mig.Name, mig.Checksum,
+				)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrations: applying %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Down reverts the most recently applied migrations, at most steps of them.
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			log.Printf("migrations: reverting %04d_%s", mig.Version, mig.Name)
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, "DELETE FROM schema_migrations WHERE version=$1", mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrations: reverting %04d_%s: %w", mig.Version, mig.Name, err)
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// Version returns the highest applied migration version, or 0 if none.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.verify(ctx, conn)
+		for v := range applied {
+			version = max(version, v)
+		}
+		return err
+	})
+	return version, err
+}
+
+// withLock runs fn on a dedicated connection holding the migration advisory
+// lock, after making sure the schema_migrations table exists.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migrations: acquiring connection: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", lockID); err != nil {
+		return fmt.Errorf("migrations: acquiring lock: %w", err)
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return fmt.Errorf("migrations: creating schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+// verify loads the applied versions and checks them against the embedded
+// migrations.
+func (m *Migrator) verify(ctx context.Context, conn *pgxpool.Conn) (map[int]string, error) {
+	rows, err := conn.Query(ctx, "SELECT version, checksum FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migrations: reading schema_migrations: %w", err)
+	}
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	known := make(map[int]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	for version, checksum := range applied {
+		mig, ok := known[version]
+		if !ok {
+			return nil, fmt.Errorf("migrations: database has version %d, which this binary does not know about", version)
+		}
+		if mig.Checksum != checksum {
+			return nil, fmt.Errorf("migrations: %04d_%s: %w", version, mig.Name, ErrChecksumMismatch)
+		}
+	}
+	return applied, nil
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS audiences;
+DROP TABLE IF EXISTS insights;
+DROP TABLE IF EXISTS chart_data;
+DROP TABLE IF EXISTS charts;
+DROP TABLE IF EXISTS favourites;
+DROP TABLE IF EXISTS assets;
+DROP TABLE IF EXISTS users;
@@ -1,11 +1,11 @@
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(255)
 );
 
-CREATE TABLE assets (
+CREATE TABLE IF NOT EXISTS assets (
     asset_id VARCHAR(255) PRIMARY KEY,
     title VARCHAR(255),
     description TEXT,
@@ -13,14 +13,14 @@ CREATE TABLE assets (
     user_id UUID REFERENCES users(id)
 );
 
-CREATE TABLE favourites (
+CREATE TABLE IF NOT EXISTS favourites (
     user_id UUID REFERENCES users(id),
     asset_id VARCHAR(255) REFERENCES assets(asset_id),
     asset_type VARCHAR(50),
     PRIMARY KEY (user_id, asset_id)
 );
 
-CREATE TABLE charts (
+CREATE TABLE IF NOT EXISTS charts (
     id VARCHAR(255) PRIMARY KEY,
     title VARCHAR(255),
     description TEXT,
@@ -28,18 +28,18 @@ CREATE TABLE charts (
     y_axis_title VARCHAR(255)
 );
 
-CREATE TABLE chart_data (
+CREATE TABLE IF NOT EXISTS chart_data (
     chart_id VARCHAR(255),
     datapoint_code VARCHAR(255),
     value NUMERIC
 );
 
-CREATE TABLE insights (
+CREATE TABLE IF NOT EXISTS insights (
     id VARCHAR(255) PRIMARY KEY,
     description TEXT
 );
 
-CREATE TABLE audiences (
+CREATE TABLE IF NOT EXISTS audiences (
     id VARCHAR(255) PRIMARY KEY,
     gender VARCHAR(50),
     country VARCHAR(255),
@@ -47,4 +47,4 @@ CREATE TABLE audiences (
     social_hours INT,
     purchases INT,
     description TEXT
-);
\ No newline at end of file
+);
@@ -0,0 +1,11 @@
+ALTER TABLE audiences DROP CONSTRAINT IF EXISTS audiences_id_fkey;
+ALTER TABLE insights DROP CONSTRAINT IF EXISTS insights_id_fkey;
+ALTER TABLE charts DROP CONSTRAINT IF EXISTS charts_id_fkey;
+
+ALTER TABLE chart_data
+    DROP CONSTRAINT IF EXISTS chart_data_chart_id_fkey,
+    DROP CONSTRAINT IF EXISTS chart_data_pkey,
+    ALTER COLUMN datapoint_code DROP NOT NULL,
+    ALTER COLUMN chart_id DROP NOT NULL;
+
+ALTER TABLE assets DROP COLUMN IF EXISTS created_at;
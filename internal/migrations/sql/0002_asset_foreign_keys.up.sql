@@ -0,0 +1,29 @@
+-- Databases created from the baseline schema have no creation time; rows
+-- that predate this migration get the time it runs.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- The baseline schema let type-specific rows outlive their asset; nothing
+-- can reach them, and they would break the foreign keys below.
+DELETE FROM charts WHERE id NOT IN (SELECT asset_id FROM assets);
+DELETE FROM insights WHERE id NOT IN (SELECT asset_id FROM assets);
+DELETE FROM audiences WHERE id NOT IN (SELECT asset_id FROM assets);
+
+-- Chart data points belong to exactly one chart and are keyed by code. Of
+-- repeated data points, the one written last is kept.
+DELETE FROM chart_data WHERE chart_id IS NULL OR datapoint_code IS NULL
+    OR chart_id NOT IN (SELECT id FROM charts);
+DELETE FROM chart_data a USING chart_data b
+    WHERE a.chart_id = b.chart_id AND a.datapoint_code = b.datapoint_code AND a.ctid < b.ctid;
+ALTER TABLE chart_data
+    ALTER COLUMN chart_id SET NOT NULL,
+    ALTER COLUMN datapoint_code SET NOT NULL,
+    ADD CONSTRAINT chart_data_pkey PRIMARY KEY (chart_id, datapoint_code),
+    ADD CONSTRAINT chart_data_chart_id_fkey FOREIGN KEY (chart_id) REFERENCES charts(id) ON DELETE CASCADE;
+
+-- Type-specific rows hang off the generic assets row.
+ALTER TABLE charts
+    ADD CONSTRAINT charts_id_fkey FOREIGN KEY (id) REFERENCES assets(asset_id) ON DELETE CASCADE;
+ALTER TABLE insights
+    ADD CONSTRAINT insights_id_fkey FOREIGN KEY (id) REFERENCES assets(asset_id) ON DELETE CASCADE;
+ALTER TABLE audiences
+    ADD CONSTRAINT audiences_id_fkey FOREIGN KEY (id) REFERENCES assets(asset_id) ON DELETE CASCADE;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS favourites_asset_id_idx;
+DROP INDEX IF EXISTS assets_user_id_created_at_idx;
+DROP INDEX IF EXISTS assets_user_id_idx;
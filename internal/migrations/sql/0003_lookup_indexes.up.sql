@@ -0,0 +1,5 @@
+-- Listings filter by owner; favourites are also looked up by asset when an
+-- asset is removed.
+CREATE INDEX IF NOT EXISTS assets_user_id_idx ON assets (user_id);
+CREATE INDEX IF NOT EXISTS assets_user_id_created_at_idx ON assets (user_id, created_at);
+CREATE INDEX IF NOT EXISTS favourites_asset_id_idx ON favourites (asset_id);
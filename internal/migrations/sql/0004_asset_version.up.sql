@@ -0,0 +1,3 @@
+-- Optimistic concurrency: every change to an asset bumps its version, which
+-- clients send back in If-Match.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
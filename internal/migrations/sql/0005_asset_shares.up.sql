@@ -0,0 +1,12 @@
+-- Grants from an asset's owner to other users. Ownership itself stays in
+-- assets.user_id and is never stored here.
+CREATE TABLE IF NOT EXISTS asset_shares (
+    asset_id VARCHAR(255) NOT NULL REFERENCES assets(asset_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id),
+    permission VARCHAR(16) NOT NULL CHECK (permission IN ('viewer', 'editor')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (asset_id, user_id)
+);
+
+-- "Shared with me" listings look grants up by grantee.
+CREATE INDEX IF NOT EXISTS asset_shares_user_id_idx ON asset_shares (user_id);
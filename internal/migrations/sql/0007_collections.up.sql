@@ -0,0 +1,19 @@
+-- Named groups of a user's assets. Membership is many-to-many and ordered by
+-- position within each collection.
+CREATE TABLE IF NOT EXISTS collections (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id),
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS collection_assets (
+    collection_id UUID NOT NULL REFERENCES collections(id) ON DELETE CASCADE,
+    asset_id VARCHAR(255) NOT NULL REFERENCES assets(asset_id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    PRIMARY KEY (collection_id, asset_id)
+);
+
+-- Deleting or reassigning an asset looks its memberships up by asset.
+CREATE INDEX IF NOT EXISTS collection_assets_asset_id_idx ON collection_assets (asset_id);
@@ -0,0 +1,10 @@
+-- Free-form labels on assets. Tags belong to the asset, so they follow it
+-- when it is reassigned and go when it is deleted.
+CREATE TABLE IF NOT EXISTS asset_tags (
+    asset_id VARCHAR(255) NOT NULL REFERENCES assets(asset_id) ON DELETE CASCADE,
+    tag VARCHAR(64) NOT NULL,
+    PRIMARY KEY (asset_id, tag)
+);
+
+-- Filtering a listing by tag looks assets up by tag.
+CREATE INDEX IF NOT EXISTS asset_tags_tag_idx ON asset_tags (tag);
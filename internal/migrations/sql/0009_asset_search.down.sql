@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS assets_search_vector_idx;
+ALTER TABLE assets DROP COLUMN IF EXISTS search_vector;
+DROP FUNCTION IF EXISTS asset_search_vector(VARCHAR);
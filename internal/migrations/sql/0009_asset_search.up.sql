@@ -0,0 +1,23 @@
+-- Full-text search over a user's assets. The document of an asset is built
+-- by asset_search_vector from the assets row and its type-specific rows,
+-- weighted title (A) > descriptions (B) > chart axis titles (C) > datapoint
+-- codes (D). The store recomputes it whenever it writes an asset.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION asset_search_vector(VARCHAR) RETURNS tsvector AS $$
+    SELECT
+        setweight(to_tsvector('english', COALESCE(a.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(i.description, a.description, '')), 'B') ||
+        setweight(to_tsvector('english', concat_ws(' ', c.x_axis_title, c.y_axis_title)), 'C') ||
+        setweight(to_tsvector('simple', COALESCE(
+            (SELECT string_agg(d.datapoint_code, ' ') FROM chart_data d WHERE d.chart_id = a.asset_id), ''
+        )), 'D')
+    FROM assets a
+    LEFT JOIN insights i ON i.id = a.asset_id
+    LEFT JOIN charts c ON c.id = a.asset_id
+    WHERE a.asset_id = $1
+$$ LANGUAGE SQL STABLE;
+
+UPDATE assets SET search_vector = asset_search_vector(asset_id);
+
+CREATE INDEX IF NOT EXISTS assets_search_vector_idx ON assets USING GIN (search_vector);
@@ -0,0 +1,5 @@
+ALTER TABLE favourites
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS pinned,
+    DROP COLUMN IF EXISTS position,
+    DROP COLUMN IF EXISTS created_at;
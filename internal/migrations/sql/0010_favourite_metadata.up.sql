@@ -0,0 +1,16 @@
+-- Favourites record when they were made, a position the user controls, a
+-- pinned flag and a private note. Listings show pinned favourites first and
+-- then order by position.
+ALTER TABLE favourites
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+-- Existing favourites keep the asset ID order they were listed in so far.
+UPDATE favourites f SET position = o.n
+FROM (
+    SELECT user_id, asset_id, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY asset_id) AS n
+    FROM favourites
+) o
+WHERE f.user_id = o.user_id AND f.asset_id = o.asset_id;
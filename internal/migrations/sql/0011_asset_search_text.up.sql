@@ -0,0 +1,13 @@
+-- The store now builds an asset's search document itself, from the text
+-- its type gives search, and keeps that text for ts_headline. This
+-- backfills it for existing assets the way asset_search_vector built it.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS search_text TEXT NOT NULL DEFAULT '';
+
+UPDATE assets a SET search_text = concat_ws(' ',
+    NULLIF(a.title, ''),
+    NULLIF(COALESCE((SELECT i.description FROM insights i WHERE i.id = a.asset_id), a.description), ''),
+    NULLIF((SELECT trim(concat_ws(' ', c.x_axis_title, c.y_axis_title)) FROM charts c WHERE c.id = a.asset_id), ''),
+    (SELECT string_agg(d.datapoint_code, ' ') FROM chart_data d WHERE d.chart_id = a.asset_id)
+);
+
+DROP FUNCTION IF EXISTS asset_search_vector(VARCHAR);
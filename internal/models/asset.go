@@ -1,9 +1,110 @@
 package models
 
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 // Asset interface
 type Asset interface {
 	GetID() string
-	SetDescription(desc string)
+	GetType() string  // type discriminator, e.g. "chart"
+	GetTitle() string // display title used for sorting
+	GetDescription() string
+	GetVersion() int64 // stored version, bumped on every change
+	SetVersion(v int64)
+}
+
+// Meta holds the bookkeeping fields shared by every asset type. It is
+// embedded in each asset struct and kept out of the JSON body; the version is
+// exposed through the ETag header instead.
+type Meta struct {
+	Version int64
+}
+
+func (m *Meta) GetVersion() int64  { return m.Version }
+func (m *Meta) SetVersion(v int64) { m.Version = v }
+
+// The built-in asset types. New types register themselves the same way.
+func init() {
+	Register(AssetType{Name: "chart", New: func() Asset { return &Chart{} }, Validate: validateChart, Search: chartSearchText})
+	Register(AssetType{Name: "insight", New: func() Asset { return &Insight{} }, Validate: validateID})
+	Register(AssetType{Name: "audience", New: func() Asset { return &Audience{} }, Validate: validateAudience})
+}
+
+// validateID reports a missing or blank id. Type-specific validators start
+// from its result.
+func validateID(a Asset) []FieldError {
+	if strings.TrimSpace(a.GetID()) == "" {
+		return []FieldError{{Field: "id", Message: "is required"}}
+	}
+	return nil
+}
+
+func validateChart(a Asset) []FieldError {
+	violations := validateID(a)
+	seen := make(map[string]bool)
+	for i, d := range a.(*Chart).Data {
+		if strings.TrimSpace(d.DatapointCode) == "" {
+			violations = append(violations, FieldError{
+				Field:   fmt.Sprintf("data[%d].datapoint_code", i),
+				Message: "is required",
+			})
+		} else if seen[d.DatapointCode] {
+			violations = append(violations, FieldError{
+				Field:   fmt.Sprintf("data[%d].datapoint_code", i),
+				Message: "is duplicated",
+			})
+		}
+		seen[d.DatapointCode] = true
+	}
+	return violations
+}
+
+// chartSearchText adds a chart's axis titles and datapoint codes to what
+// search indexes.
+func chartSearchText(a Asset) SearchText {
+	chart := a.(*Chart)
+	codes := make([]string, len(chart.Data))
+	for i, d := range chart.Data {
+		codes[i] = d.DatapointCode
+	}
+	return SearchText{
+		Title:   chart.Title,
+		Body:    chart.Description,
+		Details: strings.TrimSpace(chart.XAxisTitle + " " + chart.YAxisTitle),
+		Codes:   strings.Join(codes, " "),
+	}
+}
+
+var ageGroupPattern = regexp.MustCompile(`^(\d+)-(\d+)$`)
+
+func validateAudience(a Asset) []FieldError {
+	aud := a.(*Audience)
+	violations := validateID(a)
+	if aud.Gender != "" && aud.Gender != "Male" && aud.Gender != "Female" {
+		violations = append(violations, FieldError{Field: "gender", Message: `must be "Male" or "Female"`})
+	}
+	if aud.AgeGroup != "" {
+		if m := ageGroupPattern.FindStringSubmatch(aud.AgeGroup); m == nil {
+			violations = append(violations, FieldError{Field: "age_group", Message: `must look like "24-35"`})
+		} else {
+			lo, _ := strconv.Atoi(m[1])
+			hi, _ := strconv.Atoi(m[2])
+			if lo > hi {
+				violations = append(violations, FieldError{Field: "age_group", Message: "lower bound must not exceed upper bound"})
+			}
+		}
+	}
+	if aud.SocialHours < 0 {
+		violations = append(violations, FieldError{Field: "social_hours", Message: "must not be negative"})
+	}
+	if aud.Purchases < 0 {
+		violations = append(violations, FieldError{Field: "purchases", Message: "must not be negative"})
+	}
+	return violations
 }
 
 /// ChartData represents one data point in a chart
@@ -14,6 +115,7 @@ type ChartData struct {
 
 // Chart asset
 type Chart struct {
+	Meta        `json:"-"`
 	ID          string      `json:"id"`
 	Title       string      `json:"title"`
 	Description string      `json:"description"`
@@ -22,20 +124,26 @@ type Chart struct {
 	Data        []ChartData `json:"data"` // data points
 }
 
-func (c *Chart) GetID() string              { return c.ID }
-func (c *Chart) SetDescription(desc string) { c.Description = desc }
+func (c *Chart) GetID() string          { return c.ID }
+func (c *Chart) GetType() string        { return "chart" }
+func (c *Chart) GetTitle() string       { return c.Title }
+func (c *Chart) GetDescription() string { return c.Description }
 
 // Insight asset
 type Insight struct {
+	Meta        `json:"-"`
 	ID          string `json:"id"`
 	Description string `json:"description"` // short insight text
 }
 
-func (i *Insight) GetID() string              { return i.ID }
-func (i *Insight) SetDescription(desc string) { i.Description = desc }
+func (i *Insight) GetID() string          { return i.ID }
+func (i *Insight) GetType() string        { return "insight" }
+func (i *Insight) GetTitle() string       { return "Insight" }
+func (i *Insight) GetDescription() string { return i.Description }
 
 // Audience asset
 type Audience struct {
+	Meta        `json:"-"`
 	ID          string `json:"id"`
 	Gender      string `json:"gender"`       // Male / Female
 	Country     string `json:"country"`      // birth country
@@ -45,5 +153,7 @@ type Audience struct {
 	Description string `json:"description"`  // short description
 }
 
-func (a *Audience) GetID() string              { return a.ID }
-func (a *Audience) SetDescription(desc string) { a.Description = desc }
+func (a *Audience) GetID() string          { return a.ID }
+func (a *Audience) GetType() string        { return "audience" }
+func (a *Audience) GetTitle() string       { return "Audience" }
+func (a *Audience) GetDescription() string { return a.Description }
@@ -1,52 +1,37 @@
 package models
 
 import (
-	"encoding/json"
-	"errors"
+	"fmt"
+	"strings"
 )
 
-// AssetCreator defines the interface for creating assets.
-type AssetCreator interface {
-	Create(data map[string]interface{}) (Asset, error)
-}
-
-type ChartCreator struct{}
-
-func (c *ChartCreator) Create(data map[string]interface{}) (Asset, error) {
-	var chart Chart
-	bytes, _ := json.Marshal(data)
-	json.Unmarshal(bytes, &chart)
-	return &chart, nil
-}
-
-type InsightCreator struct{}
-
-func (c *InsightCreator) Create(data map[string]interface{}) (Asset, error) {
-	var insight Insight
-	bytes, _ := json.Marshal(data)
-	json.Unmarshal(bytes, &insight)
-	return &insight, nil
-}
-
-type AudienceCreator struct{}
-
-func (c *AudienceCreator) Create(data map[string]interface{}) (Asset, error) {
-	var audience Audience
-	bytes, _ := json.Marshal(data)
-	json.Unmarshal(bytes, &audience)
-	return &audience, nil
-}
-
-var AssetFactory = map[string]AssetCreator{
-	"chart":    &ChartCreator{},
-	"insight":  &InsightCreator{},
-	"audience": &AudienceCreator{},
-}
-
+// CreateAsset builds an asset of the registered type from a decoded JSON
+// request body. The "type" key selects the asset type and is otherwise
+// ignored. Every type error, unknown field and broken rule is collected into
+// a single *ValidationError.
 func CreateAsset(assetType string, data map[string]interface{}) (Asset, error) {
-	creator, ok := AssetFactory[assetType]
+	t, ok := LookupType(assetType)
 	if !ok {
-		return nil, errors.New("unknown asset type")
+		return nil, &ValidationError{Violations: []FieldError{{
+			Field:   "type",
+			Message: fmt.Sprintf("unknown asset type %q, expected one of %s", assetType, strings.Join(TypeNames(), ", ")),
+		}}}
+	}
+
+	fields := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		if k != "type" {
+			fields[k] = v
+		}
+	}
+
+	asset := t.New()
+	violations := decodeFields(asset, fields)
+	if t.Validate != nil {
+		violations = append(violations, t.Validate(asset)...)
+	}
+	if len(violations) > 0 {
+		return nil, &ValidationError{Violations: violations}
 	}
-	return creator.Create(data)
+	return asset, nil
 }
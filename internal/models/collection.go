@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Collection is a named, ordered group of a user's own assets. An asset can
+// be in any number of collections.
+type Collection struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	AssetIDs  []string  `json:"asset_ids"` // in collection order
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7386) to an asset and
+// returns the patched copy, validated like a new asset of the same type. The
+// patch may repeat the asset's id and type but not change them.
+func ApplyMergePatch(asset Asset, patch map[string]interface{}) (Asset, error) {
+	var violations []FieldError
+	if t, ok := patch["type"]; ok && t != asset.GetType() {
+		violations = append(violations, FieldError{Field: "type", Message: "cannot be changed"})
+	}
+	if id, ok := patch["id"]; ok && id != asset.GetID() {
+		violations = append(violations, FieldError{Field: "id", Message: "cannot be changed"})
+	}
+	if len(violations) > 0 {
+		return nil, &ValidationError{Violations: violations}
+	}
+
+	raw, err := json.Marshal(asset)
+	if err != nil {
+		return nil, fmt.Errorf("encode %s %s: %w", asset.GetType(), asset.GetID(), err)
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("encode %s %s: %w", asset.GetType(), asset.GetID(), err)
+	}
+
+	merged := mergePatch(doc, patch).(map[string]interface{})
+	delete(merged, "type")
+	return CreateAsset(asset.GetType(), merged)
+}
+
+// mergePatch implements the MergePatch algorithm of RFC 7386 section 2:
+// objects are merged key by key, null removes a key, and any other value
+// replaces the target outright (arrays included).
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = mergePatch(t[k], v)
+	}
+	return t
+}
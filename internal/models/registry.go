@@ -0,0 +1,120 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// AssetType describes one kind of asset. Each type registers itself once with
+// Register; the factory, the stores and the cache look it up by name instead
+// of switching over a hard-coded list.
+type AssetType struct {
+	// Name is the discriminator used in requests, storage and the cache.
+	Name string
+	// New returns an empty value of the type's Go struct.
+	New func() Asset
+	// Validate returns the rules a decoded asset breaks. Optional.
+	Validate func(Asset) []FieldError
+	// Codec serialises the asset for the cache. Defaults to JSONCodec.
+	Codec Codec
+	// Search returns the text full-text search indexes. Defaults to the
+	// title and description.
+	Search func(Asset) SearchText
+}
+
+// Codec converts an asset to and from its cached byte form.
+type Codec interface {
+	Encode(Asset) ([]byte, error)
+	Decode([]byte, Asset) error
+}
+
+// JSONCodec caches assets as their plain JSON representation.
+type JSONCodec struct{}
+
+func (JSONCodec) Encode(a Asset) ([]byte, error)       { return json.Marshal(a) }
+func (JSONCodec) Decode(data []byte, into Asset) error { return json.Unmarshal(data, into) }
+
+// TypedAsset is an encoded asset tagged with its type name, suitable for
+// storing heterogeneous assets in one payload.
+type TypedAsset struct {
+	Type    string          `json:"asset_type"`
+	Version int64           `json:"asset_version,omitempty"`
+	Data    json.RawMessage `json:"asset_data"`
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]AssetType
+}{
+	byName: make(map[string]AssetType),
+}
+
+// Register adds an asset type to the registry. It panics on an incomplete or
+// duplicate registration, since those are programming errors.
+func Register(t AssetType) {
+	if t.Name == "" || t.New == nil {
+		panic("models: asset type needs a Name and a New func")
+	}
+	if t.Codec == nil {
+		t.Codec = JSONCodec{}
+	}
+	if name := t.New().GetType(); name != t.Name {
+		panic(fmt.Sprintf("models: %T reports type %q but is registered as %q", t.New(), name, t.Name))
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, dup := registry.byName[t.Name]; dup {
+		panic("models: asset type " + t.Name + " registered twice")
+	}
+	registry.byName[t.Name] = t
+}
+
+// LookupType returns the registered asset type with the given name.
+func LookupType(name string) (AssetType, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	t, ok := registry.byName[name]
+	return t, ok
+}
+
+// TypeNames returns the names of all registered asset types, sorted.
+func TypeNames() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.byName))
+	for name := range registry.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// EncodeAsset serialises an asset with its registered codec.
+func EncodeAsset(a Asset) (TypedAsset, error) {
+	t, ok := LookupType(a.GetType())
+	if !ok {
+		return TypedAsset{}, fmt.Errorf("unknown asset type %q", a.GetType())
+	}
+	data, err := t.Codec.Encode(a)
+	if err != nil {
+		return TypedAsset{}, err
+	}
+	return TypedAsset{Type: t.Name, Version: a.GetVersion(), Data: data}, nil
+}
+
+// DecodeAsset rebuilds an asset encoded by EncodeAsset.
+func DecodeAsset(ta TypedAsset) (Asset, error) {
+	t, ok := LookupType(ta.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown asset type %q", ta.Type)
+	}
+	a := t.New()
+	if err := t.Codec.Decode(ta.Data, a); err != nil {
+		return nil, err
+	}
+	a.SetVersion(ta.Version)
+	return a, nil
+}
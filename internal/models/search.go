@@ -0,0 +1,42 @@
+package models
+
+import "strings"
+
+// SearchText is the text of an asset that full-text search indexes, by
+// weight: Title, then Body, then Details, then Codes. Codes are matched as
+// written rather than stemmed.
+type SearchText struct {
+	Title   string
+	Body    string
+	Details string
+	Codes   string
+}
+
+// String joins the fields, as shown in search snippets.
+func (t SearchText) String() string {
+	var parts []string
+	for _, part := range []string{t.Title, t.Body, t.Details, t.Codes} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// SearchTextOf returns what search indexes of an asset, from its type's
+// Search func.
+func SearchTextOf(a Asset) SearchText {
+	if t, ok := LookupType(a.GetType()); ok && t.Search != nil {
+		return t.Search(a)
+	}
+	return SearchText{Title: a.GetTitle(), Body: a.GetDescription()}
+}
+
+// SearchResult is one asset matching a full-text search.
+type SearchResult struct {
+	Asset Asset   `json:"asset"`
+	Rank  float64 `json:"rank"` // higher is a better match
+	// Snippet is an excerpt of the matching text, HTML-escaped, with the
+	// matched words wrapped in <mark> and </mark>.
+	Snippet string `json:"snippet"`
+}
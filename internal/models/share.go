@@ -0,0 +1,43 @@
+package models
+
+import "github.com/google/uuid"
+
+// Permission is the access a user has to an asset.
+type Permission string
+
+const (
+	PermissionViewer Permission = "viewer" // may read and favourite the asset
+	PermissionEditor Permission = "editor" // may also change it
+	PermissionOwner  Permission = "owner"  // may also delete and share it; never granted
+)
+
+var permissionRank = map[Permission]int{
+	PermissionViewer: 1,
+	PermissionEditor: 2,
+	PermissionOwner:  3,
+}
+
+// Allows reports whether p includes everything need grants.
+func (p Permission) Allows(need Permission) bool {
+	return permissionRank[p] >= permissionRank[need] && permissionRank[p] > 0
+}
+
+// Grantable reports whether p can be given to another user with a Share.
+func (p Permission) Grantable() bool {
+	return p == PermissionViewer || p == PermissionEditor
+}
+
+// Share grants a user access to an asset owned by someone else.
+type Share struct {
+	AssetID    string     `json:"asset_id"`
+	OwnerID    uuid.UUID  `json:"owner_id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Permission Permission `json:"permission"`
+}
+
+// SharedAsset is an asset as seen by a user it has been shared with.
+type SharedAsset struct {
+	OwnerID    uuid.UUID  `json:"owner_id"`
+	Permission Permission `json:"permission"`
+	Asset      Asset      `json:"asset"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// TagCount is one entry of a user's tag cloud: a tag and the number of the
+// user's assets carrying it.
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
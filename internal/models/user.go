@@ -1,9 +1,32 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
 
-// Favourite links a user to an Asset
+	"github.com/google/uuid"
+)
+
+// User is a row of the users table. Users are created implicitly the first
+// time they own, favourite or are granted an asset.
+type User struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+// Favourite links a user to an Asset. A user's favourites are listed pinned
+// ones first and then by Position, which the user can rearrange. Note is
+// private to the user.
 type Favourite struct {
-	UserID uuid.UUID `json:"user_id"`
-	Asset  Asset     `json:"asset"`
+	UserID    uuid.UUID `json:"user_id"`
+	Asset     Asset     `json:"asset"`
+	CreatedAt time.Time `json:"created_at"`
+	Position  int       `json:"position"`
+	Pinned    bool      `json:"pinned"`
+	Note      string    `json:"note,omitempty"`
+}
+
+// FavouriteUpdate changes the fields of a favourite that are set.
+type FavouriteUpdate struct {
+	Pinned *bool   `json:"pinned"`
+	Note   *string `json:"note"`
 }
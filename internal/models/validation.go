@@ -0,0 +1,129 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldError is one rule an asset payload breaks.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every violation found in an asset payload, so the
+// client can fix them all in one go.
+type ValidationError struct {
+	Violations []FieldError `json:"violations"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Field + ": " + v.Message
+	}
+	return "invalid asset: " + strings.Join(msgs, "; ")
+}
+
+// decodeFields decodes data into the struct behind asset one field at a time,
+// so that a type error in one field doesn't hide the others. Keys that don't
+// match a json tag of the struct are reported as unknown.
+func decodeFields(asset Asset, data map[string]interface{}) []FieldError {
+	v := reflect.ValueOf(asset)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		raw, err := json.Marshal(data)
+		if err == nil {
+			err = json.Unmarshal(raw, asset)
+		}
+		if err != nil {
+			return []FieldError{{Field: "", Message: err.Error()}}
+		}
+		return nil
+	}
+	fields := jsonFields(v.Elem())
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var violations []FieldError
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			violations = append(violations, FieldError{Field: key, Message: "unknown field"})
+			continue
+		}
+		raw, err := json.Marshal(data[key])
+		if err != nil {
+			violations = append(violations, FieldError{Field: key, Message: err.Error()})
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(field.Addr().Interface()); err != nil {
+			violations = append(violations, decodeError(key, err))
+		}
+	}
+	return violations
+}
+
+// jsonFields maps the json names of a struct's exported fields to the fields.
+func jsonFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields[name] = v.Field(i)
+	}
+	return fields
+}
+
+func decodeError(key string, err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := key
+		if typeErr.Field != "" {
+			field += "." + typeErr.Field
+		}
+		return FieldError{Field: field, Message: fmt.Sprintf("must be %s, got %s", jsonKind(typeErr.Type), typeErr.Value)}
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		return FieldError{Field: key + "." + strings.Trim(name, `"`), Message: "unknown field"}
+	}
+	return FieldError{Field: key, Message: err.Error()}
+}
+
+// jsonKind describes a Go type the way a JSON client would think of it.
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	default:
+		return "an object"
+	}
+}
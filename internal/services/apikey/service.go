@@ -0,0 +1,127 @@
+package apiKeyServices
+
+import (
+	"assetsApp/internal/auth"
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// keyPrefix starts every key, so leaked keys are easy to recognise. A key is
+// keyPrefix, the public prefix stored with the key, "_" and the secret.
+const keyPrefix = "ak_"
+
+// APIKeyService issues, lists and revokes API keys, and verifies them for
+// the auth middleware.
+type APIKeyService struct {
+	store storage.APIKeyStore
+}
+
+func NewAPIKeyService(store storage.APIKeyStore) *APIKeyService {
+	return &APIKeyService{store: store}
+}
+
+// CreateKey issues a key with the given scopes. The returned secret is the
+// full key; it is not stored and can't be retrieved again.
+func (s *APIKeyService) CreateKey(ctx context.Context, name string, scopes []string, expiresAt *time.Time) (models.APIKey, string, error) {
+	now := time.Now().UTC()
+	var violations []models.FieldError
+	if strings.TrimSpace(name) == "" {
+		violations = append(violations, models.FieldError{Field: "name", Message: "is required"})
+	}
+	if len(scopes) == 0 {
+		violations = append(violations, models.FieldError{Field: "scopes", Message: "must not be empty"})
+	}
+	for i, scope := range scopes {
+		if !auth.ValidScope(scope) {
+			violations = append(violations, models.FieldError{
+				Field:   fmt.Sprintf("scopes[%d]", i),
+				Message: "must be one of " + strings.Join(auth.Scopes, ", "),
+			})
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		violations = append(violations, models.FieldError{Field: "expires_at", Message: "must be in the future"})
+	}
+	if len(violations) > 0 {
+		return models.APIKey{}, "", &models.ValidationError{Violations: violations}
+	}
+
+	prefix, err := randomHex(6)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return models.APIKey{}, "", err
+	}
+	raw := keyPrefix + prefix + "_" + secret
+
+	key := models.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    prefix,
+		Scopes:    scopes,
+		CreatedAt: now.Truncate(time.Microsecond),
+		ExpiresAt: expiresAt,
+	}
+	if err := s.store.AddAPIKey(ctx, key, hashKey(raw)); err != nil {
+		return models.APIKey{}, "", err
+	}
+	return key, raw, nil
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]models.APIKey, error) {
+	return s.store.ListAPIKeys(ctx)
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, id uuid.UUID) error {
+	return s.store.RevokeAPIKey(ctx, id, time.Now().UTC())
+}
+
+// VerifyAPIKey implements auth.APIKeyVerifier.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, raw string) (auth.Principal, error) {
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(raw, keyPrefix), "_")
+	if !ok || !strings.HasPrefix(raw, keyPrefix) {
+		return auth.Principal{}, fmt.Errorf("%w: malformed api key", auth.ErrInvalidToken)
+	}
+	key, hash, err := s.store.GetAPIKey(ctx, prefix)
+	if errors.Is(err, storage.ErrNotFound) {
+		return auth.Principal{}, fmt.Errorf("%w: unknown api key %s", auth.ErrInvalidToken, prefix)
+	}
+	if err != nil {
+		return auth.Principal{}, err
+	}
+	if subtle.ConstantTimeCompare(hash, hashKey(raw)) != 1 {
+		return auth.Principal{}, fmt.Errorf("%w: wrong secret for api key %s", auth.ErrInvalidToken, prefix)
+	}
+	if !key.Active(time.Now()) {
+		return auth.Principal{}, fmt.Errorf("%w: api key %s is revoked or expired", auth.ErrInvalidToken, prefix)
+	}
+	return auth.Principal{APIKeyID: key.ID, Scopes: key.Scopes}, nil
+}
+
+// hashKey is what the store keeps instead of the key. Keys carry 256 random
+// bits, so a fast hash is enough.
+func hashKey(raw string) []byte {
+	sum := sha256.Sum256([]byte(raw))
+	return sum[:]
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate api key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
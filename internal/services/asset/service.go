@@ -3,6 +3,10 @@ package assetServices
 import (
 	"assetsApp/internal/models"
 	"assetsApp/internal/storage"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
@@ -15,18 +19,110 @@ func NewAssetService(store storage.AssetStore) *AssetService {
 	return &AssetService{store: store}
 }
 
-func (s *AssetService) GetAssets(userID uuid.UUID) []models.Asset {
-	return s.store.Get(userID)
+func (s *AssetService) GetAssets(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.AssetPage, error) {
+	return s.store.Get(ctx, userID, opts)
 }
 
-func (s *AssetService) AddAsset(userID uuid.UUID, asset models.Asset) {
-	s.store.Add(userID, asset)
+// GetAsset returns an asset the user owns or has had shared with them.
+func (s *AssetService) GetAsset(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetByID(ctx, ownerID, assetID)
 }
 
-func (s *AssetService) RemoveAsset(userID uuid.UUID, assetID string) bool {
-	return s.store.Remove(userID, assetID)
+func (s *AssetService) AddAsset(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
+	return s.store.Add(ctx, userID, asset)
 }
 
-func (s *AssetService) EditDescription(userID uuid.UUID, assetID, description string) bool {
-	return s.store.EditDescription(userID, assetID, description)
+// RemoveAsset deletes an asset at the expected version, or at any version
+// with storage.AnyVersion. Only the owner may delete an asset.
+func (s *AssetService) RemoveAsset(ctx context.Context, userID uuid.UUID, assetID string, version int64) error {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionOwner)
+	if err != nil {
+		return err
+	}
+	return s.store.Remove(ctx, ownerID, assetID, version)
+}
+
+// ReplaceAsset stores a full new version of an existing asset. The asset's
+// version is the one the caller expects to replace, or storage.AnyVersion.
+// Owners and editors may replace an asset.
+func (s *AssetService) ReplaceAsset(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, asset.GetID(), models.PermissionEditor)
+	if err != nil {
+		return err
+	}
+	return s.store.Update(ctx, ownerID, asset)
+}
+
+// PatchAsset applies a JSON Merge Patch to the stored asset and saves the
+// result, which it returns. The save is conditional on the version that was
+// patched, so a concurrent change makes it fail with
+// storage.ErrPreconditionFailed instead of being overwritten.
+func (s *AssetService) PatchAsset(ctx context.Context, userID uuid.UUID, assetID string, patch map[string]interface{}, version int64) (models.Asset, error) {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionEditor)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.store.GetByID(ctx, ownerID, assetID)
+	if err != nil {
+		return nil, err
+	}
+	if version != storage.AnyVersion && current.GetVersion() != version {
+		return nil, fmt.Errorf("asset %s is at version %d, not %d: %w", assetID, current.GetVersion(), version, storage.ErrPreconditionFailed)
+	}
+	patched, err := models.ApplyMergePatch(current, patch)
+	if err != nil {
+		return nil, err
+	}
+	patched.SetVersion(current.GetVersion())
+	if err := s.store.Update(ctx, ownerID, patched); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
+// maxQueryLength bounds a search query.
+const maxQueryLength = 256
+
+// SearchAssets runs a full-text search over the user's own assets and
+// returns up to limit results, best first. A limit of 0 means
+// storage.DefaultPageLimit.
+func (s *AssetService) SearchAssets(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	var message string
+	switch {
+	case query == "":
+		message = "is required"
+	case utf8.RuneCountInString(query) > maxQueryLength:
+		message = fmt.Sprintf("must be at most %d characters", maxQueryLength)
+	}
+	if message != "" {
+		return nil, &models.ValidationError{Violations: []models.FieldError{{Field: "q", Message: message}}}
+	}
+	if limit <= 0 {
+		limit = storage.DefaultPageLimit
+	}
+	return s.store.Search(ctx, userID, query, limit)
+}
+
+// The methods below act across users and skip the ownership checks above;
+// only admin routes call them.
+
+// ListUsers lists every user, ordered by ID.
+func (s *AssetService) ListUsers(ctx context.Context, opts storage.ListOptions) (storage.UserPage, error) {
+	return s.store.ListUsers(ctx, opts)
+}
+
+// ReassignAsset transfers an asset to newOwnerID and returns its previous
+// owner.
+func (s *AssetService) ReassignAsset(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error) {
+	return s.store.Reassign(ctx, assetID, newOwnerID)
+}
+
+// PurgeUser deletes a user and everything they own.
+func (s *AssetService) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	return s.store.PurgeUser(ctx, userID)
 }
@@ -0,0 +1,117 @@
+package collectionServices
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxNameLength matches the collections.name column.
+const maxNameLength = 255
+
+// CollectionService manages a user's collections and what is in them.
+type CollectionService struct {
+	store storage.AssetStore
+}
+
+func NewCollectionService(store storage.AssetStore) *CollectionService {
+	return &CollectionService{store: store}
+}
+
+func (s *CollectionService) GetCollections(ctx context.Context, userID uuid.UUID) ([]models.Collection, error) {
+	return s.store.GetCollections(ctx, userID)
+}
+
+func (s *CollectionService) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error) {
+	return s.store.GetCollection(ctx, userID, collectionID)
+}
+
+// CreateCollection adds an empty collection. Names are trimmed and must be
+// unique per user.
+func (s *CollectionService) CreateCollection(ctx context.Context, userID uuid.UUID, name string) (models.Collection, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	collection := models.Collection{ID: uuid.New(), Name: name}
+	if err := s.store.AddCollection(ctx, userID, &collection); err != nil {
+		return models.Collection{}, err
+	}
+	return collection, nil
+}
+
+func (s *CollectionService) RenameCollection(ctx context.Context, userID, collectionID uuid.UUID, name string) (models.Collection, error) {
+	name, err := validateName(name)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	if err := s.store.RenameCollection(ctx, userID, collectionID, name); err != nil {
+		return models.Collection{}, err
+	}
+	return s.store.GetCollection(ctx, userID, collectionID)
+}
+
+// RemoveCollection deletes a collection. Its assets are not affected.
+func (s *CollectionService) RemoveCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	return s.store.RemoveCollection(ctx, userID, collectionID)
+}
+
+func (s *CollectionService) GetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error) {
+	return s.store.GetCollectionAssets(ctx, userID, collectionID)
+}
+
+// AddAsset appends one of the user's assets to the collection.
+func (s *CollectionService) AddAsset(ctx context.Context, userID, collectionID uuid.UUID, assetID string) (models.Collection, error) {
+	if err := s.store.AddToCollection(ctx, userID, collectionID, assetID); err != nil {
+		return models.Collection{}, err
+	}
+	return s.store.GetCollection(ctx, userID, collectionID)
+}
+
+func (s *CollectionService) RemoveAsset(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	return s.store.RemoveFromCollection(ctx, userID, collectionID, assetID)
+}
+
+// SetAssets replaces the collection's members with assetIDs, in that order.
+// Sending the current members in a new order reorders the collection.
+func (s *CollectionService) SetAssets(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) (models.Collection, error) {
+	var violations []models.FieldError
+	seen := make(map[string]bool, len(assetIDs))
+	for i, id := range assetIDs {
+		field := fmt.Sprintf("asset_ids[%d]", i)
+		switch {
+		case strings.TrimSpace(id) == "":
+			violations = append(violations, models.FieldError{Field: field, Message: "is required"})
+		case seen[id]:
+			violations = append(violations, models.FieldError{Field: field, Message: "is listed more than once"})
+		}
+		seen[id] = true
+	}
+	if len(violations) > 0 {
+		return models.Collection{}, &models.ValidationError{Violations: violations}
+	}
+
+	if err := s.store.SetCollectionAssets(ctx, userID, collectionID, assetIDs); err != nil {
+		return models.Collection{}, err
+	}
+	return s.store.GetCollection(ctx, userID, collectionID)
+}
+
+func validateName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	var message string
+	switch {
+	case name == "":
+		message = "is required"
+	case utf8.RuneCountInString(name) > maxNameLength:
+		message = fmt.Sprintf("must be at most %d characters", maxNameLength)
+	default:
+		return name, nil
+	}
+	return "", &models.ValidationError{Violations: []models.FieldError{{Field: "name", Message: message}}}
+}
@@ -3,10 +3,18 @@ package favouriteServices
 import (
 	"assetsApp/internal/models"
 	"assetsApp/internal/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
+// maxNoteLength caps the private note on a favourite.
+const maxNoteLength = 1000
+
 type FavouriteService struct {
 	store storage.AssetStore
 }
@@ -15,14 +23,56 @@ func NewFavouriteService(store storage.AssetStore) *FavouriteService {
 	return &FavouriteService{store: store}
 }
 
-func (s *FavouriteService) GetFavourites(userID uuid.UUID) []models.Favourite {
-	return s.store.GetFavourites(userID)
+func (s *FavouriteService) GetFavourites(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.FavouritePage, error) {
+	return s.store.GetFavourites(ctx, userID, opts)
+}
+
+// AddFavourite favourites an asset the user owns or has had shared with them
+// and reports whether the favourite is new. Favouriting an asset again
+// leaves the favourite as it was.
+func (s *FavouriteService) AddFavourite(ctx context.Context, userID uuid.UUID, assetID, assetType string) (bool, error) {
+	if _, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionViewer); err != nil {
+		return false, err
+	}
+	err := s.store.AddFavourite(ctx, userID, assetID, assetType)
+	if errors.Is(err, storage.ErrConflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *FavouriteService) RemoveFavourite(ctx context.Context, userID uuid.UUID, assetID string) error {
+	return s.store.RemoveFavourite(ctx, userID, assetID)
 }
 
-func (s *FavouriteService) AddFavourite(userID uuid.UUID, assetID, assetType string) bool {
-	return s.store.AddFavourite(userID, assetID, assetType)
+// UpdateFavourite pins or unpins a favourite and sets its note, as far as the
+// update sets them. Notes are trimmed; an empty one clears the note.
+func (s *FavouriteService) UpdateFavourite(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error {
+	if update.Note != nil {
+		note := strings.TrimSpace(*update.Note)
+		if utf8.RuneCountInString(note) > maxNoteLength {
+			return &models.ValidationError{Violations: []models.FieldError{
+				{Field: "note", Message: fmt.Sprintf("must be at most %d characters", maxNoteLength)},
+			}}
+		}
+		update.Note = &note
+	}
+	return s.store.UpdateFavourite(ctx, userID, assetID, update)
 }
 
-func (s *FavouriteService) RemoveFavourite(userID uuid.UUID, assetID string) bool {
-	return s.store.RemoveFavourite(userID, assetID)
+// MoveFavourite moves a favourite to just before the favourite before or just
+// after the favourite after. Exactly one of the two must be given.
+func (s *FavouriteService) MoveFavourite(ctx context.Context, userID uuid.UUID, assetID, before, after string) error {
+	if (before == "") == (after == "") {
+		return &models.ValidationError{Violations: []models.FieldError{
+			{Field: "before", Message: "exactly one of before and after is required"},
+		}}
+	}
+	if before != "" {
+		return s.store.MoveFavourite(ctx, userID, assetID, before, false)
+	}
+	return s.store.MoveFavourite(ctx, userID, assetID, after, true)
 }
@@ -0,0 +1,63 @@
+package shareServices
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// ShareService manages the grants an asset's owner gives other users.
+type ShareService struct {
+	store storage.AssetStore
+}
+
+func NewShareService(store storage.AssetStore) *ShareService {
+	return &ShareService{store: store}
+}
+
+// GetShares lists the grants on an asset. Only its owner may see them.
+func (s *ShareService) GetShares(ctx context.Context, userID uuid.UUID, assetID string) ([]models.Share, error) {
+	if _, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionOwner); err != nil {
+		return nil, err
+	}
+	return s.store.GetShares(ctx, userID, assetID)
+}
+
+// ShareAsset grants granteeID viewer or editor access to an asset userID
+// owns, replacing any earlier grant.
+func (s *ShareService) ShareAsset(ctx context.Context, userID uuid.UUID, assetID string, granteeID uuid.UUID, perm models.Permission) (models.Share, error) {
+	var violations []models.FieldError
+	if !perm.Grantable() {
+		violations = append(violations, models.FieldError{Field: "permission", Message: `must be "viewer" or "editor"`})
+	}
+	if granteeID == userID {
+		violations = append(violations, models.FieldError{Field: "user_id", Message: "cannot share an asset with its owner"})
+	}
+	if len(violations) > 0 {
+		return models.Share{}, &models.ValidationError{Violations: violations}
+	}
+
+	if _, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionOwner); err != nil {
+		return models.Share{}, err
+	}
+	share := models.Share{AssetID: assetID, OwnerID: userID, UserID: granteeID, Permission: perm}
+	if err := s.store.Share(ctx, share); err != nil {
+		return models.Share{}, err
+	}
+	return share, nil
+}
+
+// UnshareAsset revokes granteeID's access to an asset userID owns.
+func (s *ShareService) UnshareAsset(ctx context.Context, userID uuid.UUID, assetID string, granteeID uuid.UUID) error {
+	if _, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionOwner); err != nil {
+		return err
+	}
+	return s.store.Unshare(ctx, userID, assetID, granteeID)
+}
+
+// GetSharedWithMe lists the assets other users have shared with userID.
+func (s *ShareService) GetSharedWithMe(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.SharedPage, error) {
+	return s.store.GetSharedWithMe(ctx, userID, opts)
+}
@@ -0,0 +1,78 @@
+package tagServices
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// maxTagLength matches the asset_tags.tag column.
+const maxTagLength = 64
+
+// TagService manages the free-form tags on assets. Tags belong to the asset:
+// anyone who can read an asset sees its tags, and owners and editors can
+// change them.
+type TagService struct {
+	store storage.AssetStore
+}
+
+func NewTagService(store storage.AssetStore) *TagService {
+	return &TagService{store: store}
+}
+
+func (s *TagService) GetTags(ctx context.Context, userID uuid.UUID, assetID string) ([]string, error) {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.GetTags(ctx, ownerID, assetID)
+}
+
+// AddTag tags an asset and returns all its tags. Tags are trimmed and
+// compared exactly, so "emea" and "EMEA" are different tags.
+func (s *TagService) AddTag(ctx context.Context, userID uuid.UUID, assetID, tag string) ([]string, error) {
+	tag, err := validateTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionEditor)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.AddTag(ctx, ownerID, assetID, tag); err != nil {
+		return nil, err
+	}
+	return s.store.GetTags(ctx, ownerID, assetID)
+}
+
+func (s *TagService) RemoveTag(ctx context.Context, userID uuid.UUID, assetID, tag string) error {
+	ownerID, err := storage.Authorize(ctx, s.store, userID, assetID, models.PermissionEditor)
+	if err != nil {
+		return err
+	}
+	return s.store.RemoveTag(ctx, ownerID, assetID, strings.TrimSpace(tag))
+}
+
+// GetTagCloud counts the user's own assets per tag.
+func (s *TagService) GetTagCloud(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error) {
+	return s.store.TagCounts(ctx, userID)
+}
+
+func validateTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	var message string
+	switch {
+	case tag == "":
+		message = "is required"
+	case utf8.RuneCountInString(tag) > maxTagLength:
+		message = fmt.Sprintf("must be at most %d characters", maxTagLength)
+	default:
+		return tag, nil
+	}
+	return "", &models.ValidationError{Violations: []models.FieldError{{Field: "tag", Message: message}}}
+}
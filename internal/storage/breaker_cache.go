@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCacheUnavailable is returned by BreakerCache while its breaker is open.
+var ErrCacheUnavailable = errors.New("cache unavailable")
+
+// BreakerOptions tune when a BreakerCache gives up on its cache.
+type BreakerOptions struct {
+	// Failures is how many calls in a row must fail to open the breaker.
+	Failures int
+	// Cooldown is how long an open breaker rejects calls before it lets one
+	// through to find out whether the cache is back.
+	Cooldown time.Duration
+	// Timeout, if set, bounds every call, so that a slow cache counts as a
+	// failing one.
+	Timeout time.Duration
+}
+
+// BreakerCache is a Cache that stops calling another cache, typically
+// Redis, once it keeps failing. While the breaker is open every call fails at
+// once with ErrCacheUnavailable, which CachedStore treats as a miss, so
+// reads go straight to the database instead of waiting on timeouts. After
+// Cooldown a single call is let through; if it succeeds the breaker closes.
+type BreakerCache struct {
+	cache Cache
+	opts  BreakerOptions
+
+	mu        sync.Mutex
+	failures  int // consecutive
+	openUntil time.Time
+	probing   bool
+}
+
+func NewBreakerCache(cache Cache, opts BreakerOptions) *BreakerCache {
+	if opts.Failures < 1 {
+		opts.Failures = 1
+	}
+	return &BreakerCache{cache: cache, opts: opts}
+}
+
+// Open reports whether calls are currently being rejected.
+func (b *BreakerCache) Open() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures >= b.opts.Failures && (time.Now().Before(b.openUntil) || b.probing)
+}
+
+// allow reports whether a call may go ahead and whether it is the probe of
+// an open breaker.
+func (b *BreakerCache) allow() (ok, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.opts.Failures {
+		return true, false
+	}
+	if b.probing || time.Now().Before(b.openUntil) {
+		return false, false
+	}
+	b.probing = true
+	return true, true
+}
+
+func (b *BreakerCache) record(err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if probe {
+		b.probing = false
+	}
+	if err == nil {
+		if b.failures >= b.opts.Failures {
+			log.Printf("breaker_cache: cache is back, closing the breaker")
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.opts.Failures && (probe || b.failures == b.opts.Failures) {
+		log.Printf("breaker_cache: opening the breaker for %v after %d failures: %v", b.opts.Cooldown, b.failures, err)
+		b.openUntil = time.Now().Add(b.opts.Cooldown)
+	}
+}
+
+// do runs call unless the breaker is open, and records how it went. Calls
+// the caller gave up on don't count.
+func (b *BreakerCache) do(ctx context.Context, call func(ctx context.Context) error) error {
+	ok, probe := b.allow()
+	if !ok {
+		return ErrCacheUnavailable
+	}
+	callCtx := ctx
+	if b.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, b.opts.Timeout)
+		defer cancel()
+	}
+	err := call(callCtx)
+	if ctx.Err() != nil {
+		if probe {
+			b.mu.Lock()
+			b.probing = false
+			b.mu.Unlock()
+		}
+		return err
+	}
+	b.record(err, probe)
+	return err
+}
+
+func (b *BreakerCache) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := b.do(ctx, func(ctx context.Context) (err error) {
+		value, err = b.cache.Get(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+func (b *BreakerCache) Set(ctx context.Context, key string, value string) error {
+	return b.do(ctx, func(ctx context.Context) error {
+		return b.cache.Set(ctx, key, value)
+	})
+}
+
+func (b *BreakerCache) Del(ctx context.Context, keys ...string) error {
+	return b.do(ctx, func(ctx context.Context) error {
+		return b.cache.Del(ctx, keys...)
+	})
+}
+
+func (b *BreakerCache) SAdd(ctx context.Context, key string, members ...string) error {
+	return b.do(ctx, func(ctx context.Context) error {
+		return b.cache.SAdd(ctx, key, members...)
+	})
+}
+
+func (b *BreakerCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	var members []string
+	err := b.do(ctx, func(ctx context.Context) (err error) {
+		members, err = b.cache.SMembers(ctx, key)
+		return err
+	})
+	return members, err
+}
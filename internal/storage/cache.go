@@ -0,0 +1,16 @@
+package storage
+
+import "context"
+
+// Cache is the key-value store CachedStore keeps its entries in. Entries
+// expire after a TTL chosen by the cache. A missing key is reported by Get
+// as an error or an empty value. RedisClient is the production Cache.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value string) error
+	Del(ctx context.Context, keys ...string) error
+	// SAdd adds members to the set at key and restarts its TTL.
+	SAdd(ctx context.Context, key string, members ...string) error
+	// SMembers lists the set at key, or nothing if it doesn't exist.
+	SMembers(ctx context.Context, key string) ([]string, error)
+}
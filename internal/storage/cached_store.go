@@ -2,151 +2,526 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"log"
+	"time"
 
 	"assetsApp/internal/models"
 
 	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
 )
 
+// CachedStore reads assets, asset listings, the first page of favourites and
+// tag clouds through a Cache in front of another store, and keeps them fresh
+// on writes. A nil cache turns it into a pass-through.
 type CachedStore struct {
-	db    AssetStore
-	cache *RedisClient
+	db     AssetStore
+	cache  Cache
+	opts   CacheOptions
+	flight singleflight.Group // loads in progress, by cache key
+	loads  inFlightLoads      // the same loads, for invalidations to mark
+	// invalidations that failed, to be made once the cache is back
+	repairs repairQueue
 }
 
+// cachedFavourite stores the asset with its registered codec so it can be
+// decoded back into the right Go type.
 type cachedFavourite struct {
-	UserID    uuid.UUID       `json:"user_id"`
-	AssetType string          `json:"asset_type"`
-	AssetData json.RawMessage `json:"asset_data"`
+	UserID    uuid.UUID `json:"user_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Position  int       `json:"position"`
+	Pinned    bool      `json:"pinned"`
+	Note      string    `json:"note,omitempty"`
+	models.TypedAsset
 }
 
-func NewCachedStore(db AssetStore, cache *RedisClient) *CachedStore {
+// cachedFavouritePage is the Redis payload for the first page of favourites.
+type cachedFavouritePage struct {
+	Items      []cachedFavourite `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// cachedAssetPage is the Redis payload for a page of a user's assets.
+type cachedAssetPage struct {
+	Items      []models.TypedAsset `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+func NewCachedStore(db AssetStore, cache Cache, opts CacheOptions) *CachedStore {
 	return &CachedStore{
 		db:    db,
 		cache: cache,
+		opts:  opts,
 	}
 }
 
-// ----- Asset methods without caching -----
+// ----- Asset methods -----
 func favsCacheKey(userID uuid.UUID) string {
 	return fmt.Sprintf("favourites:%s", userID.String())
 }
 
-func (c *CachedStore) Get(userID uuid.UUID) []models.Asset {
-	return c.db.Get(userID)
+func assetCacheKey(userID uuid.UUID, assetID string) string {
+	return fmt.Sprintf("asset:%s:%s", userID.String(), assetID)
+}
+
+func tagsCacheKey(userID uuid.UUID) string {
+	return fmt.Sprintf("tags:%s", userID.String())
+}
+
+// listingCacheKey names one cached page of a user's assets. Options that
+// list the same page share a key.
+func listingCacheKey(userID uuid.UUID, opts ListOptions) string {
+	opts.Limit, opts.Sort = opts.limit(), opts.sortField()
+	b, _ := json.Marshal(opts)
+	return fmt.Sprintf("assets:%s:%x", userID.String(), sha256.Sum256(b))
+}
+
+// listingsCacheKey names the set of a user's cached listing pages.
+func listingsCacheKey(userID uuid.UUID) string {
+	return fmt.Sprintf("asset-listings:%s", userID.String())
+}
+
+// favouritedByCacheKey names the set of users whose cached favourites page
+// holds the asset, so that changing the asset can drop all of them.
+func favouritedByCacheKey(assetID string) string {
+	return fmt.Sprintf("favourited-by:%s", assetID)
+}
+
+// Get reads listings through the cache. Pages filtered by collection or tag
+// depend on more than the assets themselves and always go to the database.
+func (c *CachedStore) Get(ctx context.Context, userID uuid.UUID, opts ListOptions) (AssetPage, error) {
+	if c.cache == nil || opts.Collection != uuid.Nil || len(opts.Tags) > 0 {
+		return c.db.Get(ctx, userID, opts)
+	}
+
+	key := listingCacheKey(userID, opts)
+	return readThrough(ctx, c, key, cacheable[AssetPage]{
+		load: func(ctx context.Context) (AssetPage, error) {
+			return c.db.Get(ctx, userID, opts)
+		},
+		encode: encodeAssetPage,
+		decode: decodeAssetPage,
+		index: func(ctx context.Context, _ AssetPage) error {
+			return c.cache.SAdd(ctx, listingsCacheKey(userID), key)
+		},
+	})
+}
+
+func encodeAssetPage(page AssetPage) (string, error) {
+	cachedPage := cachedAssetPage{Items: []models.TypedAsset{}, NextCursor: page.NextCursor}
+	for _, asset := range page.Items {
+		typed, err := models.EncodeAsset(asset)
+		if err != nil {
+			return "", err
+		}
+		cachedPage.Items = append(cachedPage.Items, typed)
+	}
+	b, err := json.Marshal(cachedPage)
+	return string(b), err
+}
+
+func decodeAssetPage(cached string) (AssetPage, error) {
+	var cachedPage cachedAssetPage
+	if err := json.Unmarshal([]byte(cached), &cachedPage); err != nil {
+		return AssetPage{}, err
+	}
+	page := AssetPage{Items: make([]models.Asset, 0, len(cachedPage.Items)), NextCursor: cachedPage.NextCursor}
+	for _, typed := range cachedPage.Items {
+		asset, err := models.DecodeAsset(typed)
+		if err != nil {
+			return AssetPage{}, err
+		}
+		page.Items = append(page.Items, asset)
+	}
+	return page, nil
+}
+
+// GetByID reads through Redis: a hit is decoded with the asset type's codec,
+// a miss is loaded from the database and written back.
+func (c *CachedStore) GetByID(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	if c.cache == nil {
+		return c.db.GetByID(ctx, userID, assetID)
+	}
+	return readThrough(ctx, c, assetCacheKey(userID, assetID), cacheable[models.Asset]{
+		load: func(ctx context.Context) (models.Asset, error) {
+			return c.db.GetByID(ctx, userID, assetID)
+		},
+		encode: encodeAsset,
+		decode: decodeAsset,
+	})
+}
+
+func encodeAsset(asset models.Asset) (string, error) {
+	typed, err := models.EncodeAsset(asset)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(typed)
+	return string(b), err
+}
+
+func decodeAsset(cached string) (models.Asset, error) {
+	var typed models.TypedAsset
+	if err := json.Unmarshal([]byte(cached), &typed); err != nil {
+		return nil, err
+	}
+	return models.DecodeAsset(typed)
+}
+
+// writeAsset stores an asset under its owner's key, fresh for SoftTTL. If
+// that fails the key is dropped instead, since it may hold an older version.
+func (c *CachedStore) writeAsset(ctx context.Context, userID uuid.UUID, asset models.Asset) {
+	key := assetCacheKey(userID, asset.GetID())
+	c.loads.invalidate(pendingInvalidation{keys: []string{key}})
+	err := write(ctx, c, key, cacheable[models.Asset]{encode: encodeAsset}, asset, 0)
+	if err != nil {
+		c.del(ctx, key)
+	}
+}
+
+// assetChanged drops everything cached that shows an asset: its owner's
+// listings and every favourites page that holds it. The asset's own entry is
+// left to the caller, which either rewrites or deletes it.
+func (c *CachedStore) assetChanged(ctx context.Context, ownerID uuid.UUID, assetIDs ...string) {
+	c.invalidate(ctx, pendingInvalidation{owners: []uuid.UUID{ownerID}, assets: assetIDs})
 }
 
-func (c *CachedStore) Add(userID uuid.UUID, asset models.Asset) {
-	c.db.Add(userID, asset)
+// del drops keys, queueing them for repair if that fails.
+func (c *CachedStore) del(ctx context.Context, keys ...string) {
+	c.invalidate(ctx, pendingInvalidation{keys: keys})
+}
+
+// listingKeys returns the keys of a user's cached listing pages along with
+// the set recording them.
+func (c *CachedStore) listingKeys(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	keys, err := c.cache.SMembers(ctx, listingsCacheKey(userID))
+	if err != nil {
+		return nil, err
+	}
+	return append(keys, listingsCacheKey(userID)), nil
+}
+
+// favouritedByKeys returns the keys of the favourites pages that hold an
+// asset along with the set recording them.
+func (c *CachedStore) favouritedByKeys(ctx context.Context, assetID string) ([]string, error) {
+	users, err := c.cache.SMembers(ctx, favouritedByCacheKey(assetID))
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(users)+1)
+	for _, u := range users {
+		if userID, err := uuid.Parse(u); err == nil {
+			keys = append(keys, favsCacheKey(userID))
+		}
+	}
+	return append(keys, favouritedByCacheKey(assetID)), nil
 }
 
-func (c *CachedStore) Remove(userID uuid.UUID, assetID string) bool {
-	res := c.db.Remove(userID, assetID)
-	if res && c.cache != nil {
-		_ = c.cache.Del(context.Background(), favsCacheKey(userID))
+// Add writes the new asset through to the cache.
+func (c *CachedStore) Add(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
+	err := c.db.Add(ctx, userID, asset)
+	if err == nil && c.cache != nil {
+		c.assetChanged(ctx, userID)
+		c.writeAsset(ctx, userID, asset)
 	}
-	return res
+	return err
 }
 
-func (c *CachedStore) EditDescription(userID uuid.UUID, assetID, newDesc string) bool {
-	res := c.db.EditDescription(userID, assetID, newDesc)
-	if res && c.cache != nil {
-		_ = c.cache.Del(context.Background(), favsCacheKey(userID))
+func (c *CachedStore) Remove(ctx context.Context, userID uuid.UUID, assetID string, version int64) error {
+	err := c.db.Remove(ctx, userID, assetID, version)
+	if err == nil && c.cache != nil {
+		c.assetChanged(ctx, userID, assetID)
+		c.del(ctx, assetCacheKey(userID, assetID), tagsCacheKey(userID))
 	}
-	return res
+	return err
+}
+
+// Update writes the asset, which now carries its new version, through to
+// the cache.
+func (c *CachedStore) Update(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
+	err := c.db.Update(ctx, userID, asset)
+	if err == nil && c.cache != nil {
+		c.assetChanged(ctx, userID, asset.GetID())
+		c.writeAsset(ctx, userID, asset)
+	}
+	return err
 }
 
 // ----- Favourites with caching -----
 
-func (c *CachedStore) GetFavourites(userID uuid.UUID) []models.Favourite {
-	ctx := context.Background()
-
-	if c.cache != nil {
-		if cached, err := c.cache.Get(ctx, favsCacheKey(userID)); err == nil && cached != "" {
-			fmt.Printf("cached_store: cache hit for favourites of user %v\n", userID)
-
-			var cachedFavs []cachedFavourite
-			if err := json.Unmarshal([]byte(cached), &cachedFavs); err == nil {
-				var favs []models.Favourite
-				for _, cf := range cachedFavs {
-					var asset models.Asset
-					switch cf.AssetType {
-					case "chart":
-						var a models.Chart
-						if err := json.Unmarshal(cf.AssetData, &a); err == nil {
-							asset = &a
-						}
-					case "insight":
-						var a models.Insight
-						if err := json.Unmarshal(cf.AssetData, &a); err == nil {
-							asset = &a
-						}
-					case "audience":
-						var a models.Audience
-						if err := json.Unmarshal(cf.AssetData, &a); err == nil {
-							asset = &a
-						}
-					}
-					if asset != nil {
-						favs = append(favs, models.Favourite{
-							UserID: cf.UserID,
-							Asset:  asset,
-						})
-					}
+// GetFavourites serves the default first page from Redis; other pages and
+// orderings always go to the database. The cached page is indexed under each
+// of its assets, so that a change to any of them finds and drops it.
+func (c *CachedStore) GetFavourites(ctx context.Context, userID uuid.UUID, opts ListOptions) (FavouritePage, error) {
+	if c.cache == nil || !opts.isDefaultFavourites() {
+		return c.db.GetFavourites(ctx, userID, opts)
+	}
+
+	return readThrough(ctx, c, favsCacheKey(userID), cacheable[FavouritePage]{
+		load: func(ctx context.Context) (FavouritePage, error) {
+			return c.db.GetFavourites(ctx, userID, opts)
+		},
+		encode: encodeFavouritePage,
+		decode: decodeFavouritePage,
+		index: func(ctx context.Context, page FavouritePage) error {
+			for _, f := range page.Items {
+				if err := c.cache.SAdd(ctx, favouritedByCacheKey(f.Asset.GetID()), userID.String()); err != nil {
+					return err
 				}
-				return favs
 			}
-			log.Printf("cached_store: failed to unmarshal favourites cache for user %v: %v", userID, err)
+			return nil
+		},
+	})
+}
+
+func encodeFavouritePage(page FavouritePage) (string, error) {
+	cachedPage := cachedFavouritePage{NextCursor: page.NextCursor}
+	for _, f := range page.Items {
+		typed, err := models.EncodeAsset(f.Asset)
+		if err != nil {
+			return "", err
 		}
+		cachedPage.Items = append(cachedPage.Items, cachedFavourite{
+			UserID:     f.UserID,
+			CreatedAt:  f.CreatedAt,
+			Position:   f.Position,
+			Pinned:     f.Pinned,
+			Note:       f.Note,
+			TypedAsset: typed,
+		})
 	}
+	b, err := json.Marshal(cachedPage)
+	return string(b), err
+}
 
-	// Fallback to DB
-	favs := c.db.GetFavourites(userID)
-
-	// Write back to cache
-	if c.cache != nil {
-		var cachedFavs []cachedFavourite
-		for _, f := range favs {
-			assetJSON, _ := json.Marshal(f.Asset)
-			var assetType string
-			switch f.Asset.(type) {
-			case *models.Chart:
-				assetType = "chart"
-			case *models.Insight:
-				assetType = "insight"
-			case *models.Audience:
-				assetType = "audience"
-			}
-			cachedFavs = append(cachedFavs, cachedFavourite{
-				UserID:    f.UserID,
-				AssetType: assetType,
-				AssetData: assetJSON,
-			})
+func decodeFavouritePage(cached string) (FavouritePage, error) {
+	var cachedPage cachedFavouritePage
+	if err := json.Unmarshal([]byte(cached), &cachedPage); err != nil {
+		return FavouritePage{}, err
+	}
+	var favs []models.Favourite
+	for _, cf := range cachedPage.Items {
+		asset, err := models.DecodeAsset(cf.TypedAsset)
+		if err != nil {
+			log.Printf("cached_store: skipping cached favourite of user %v: %v", cf.UserID, err)
+			continue
 		}
-		if b, err := json.Marshal(cachedFavs); err == nil {
-			_ = c.cache.Set(ctx, favsCacheKey(userID), string(b))
-		} else {
-			log.Printf("cached_store: failed to marshal favourites for caching: %v", err)
+		favs = append(favs, models.Favourite{
+			UserID:    cf.UserID,
+			Asset:     asset,
+			CreatedAt: cf.CreatedAt,
+			Position:  cf.Position,
+			Pinned:    cf.Pinned,
+			Note:      cf.Note,
+		})
+	}
+	return FavouritePage{Items: favs, NextCursor: cachedPage.NextCursor}, nil
+}
+
+func (c *CachedStore) AddFavourite(ctx context.Context, userID uuid.UUID, assetID, assetType string) error {
+	err := c.db.AddFavourite(ctx, userID, assetID, assetType)
+	if err == nil && c.cache != nil {
+		c.del(ctx, favsCacheKey(userID))
+	}
+	return err
+}
+
+func (c *CachedStore) RemoveFavourite(ctx context.Context, userID uuid.UUID, assetID string) error {
+	err := c.db.RemoveFavourite(ctx, userID, assetID)
+	if err == nil && c.cache != nil {
+		c.del(ctx, favsCacheKey(userID))
+	}
+	return err
+}
+
+func (c *CachedStore) UpdateFavourite(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error {
+	err := c.db.UpdateFavourite(ctx, userID, assetID, update)
+	if err == nil && c.cache != nil {
+		c.del(ctx, favsCacheKey(userID))
+	}
+	return err
+}
+
+func (c *CachedStore) MoveFavourite(ctx context.Context, userID uuid.UUID, assetID, targetID string, after bool) error {
+	err := c.db.MoveFavourite(ctx, userID, assetID, targetID, after)
+	if err == nil && c.cache != nil {
+		c.del(ctx, favsCacheKey(userID))
+	}
+	return err
+}
+
+// ----- Sharing, not cached -----
+
+func (c *CachedStore) Access(ctx context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error) {
+	return c.db.Access(ctx, userID, assetID)
+}
+
+func (c *CachedStore) GetShares(ctx context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error) {
+	return c.db.GetShares(ctx, ownerID, assetID)
+}
+
+func (c *CachedStore) Share(ctx context.Context, share models.Share) error {
+	return c.db.Share(ctx, share)
+}
+
+// Unshare also drops the grantee's favourite, so their cached page goes too.
+func (c *CachedStore) Unshare(ctx context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error {
+	err := c.db.Unshare(ctx, ownerID, assetID, userID)
+	if err == nil && c.cache != nil {
+		c.del(ctx, favsCacheKey(userID))
+	}
+	return err
+}
+
+func (c *CachedStore) GetSharedWithMe(ctx context.Context, userID uuid.UUID, opts ListOptions) (SharedPage, error) {
+	return c.db.GetSharedWithMe(ctx, userID, opts)
+}
+
+// ----- Admin operations -----
+
+func (c *CachedStore) ListUsers(ctx context.Context, opts ListOptions) (UserPage, error) {
+	return c.db.ListUsers(ctx, opts)
+}
+
+// Reassign moves the asset's cache entry from one owner's key to the other's,
+// so both are dropped, along with both owners' listings. Its tags move with
+// it, changing both tag clouds.
+func (c *CachedStore) Reassign(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error) {
+	ownerID, err := c.db.Reassign(ctx, assetID, newOwnerID)
+	if err == nil && c.cache != nil {
+		c.assetChanged(ctx, ownerID, assetID)
+		c.assetChanged(ctx, newOwnerID)
+		c.del(ctx,
+			assetCacheKey(ownerID, assetID), assetCacheKey(newOwnerID, assetID),
+			favsCacheKey(ownerID), favsCacheKey(newOwnerID),
+			tagsCacheKey(ownerID), tagsCacheKey(newOwnerID),
+		)
+	}
+	return ownerID, err
+}
+
+// PurgeUser drops the user's listings, favourites page and tag cloud, and
+// every other user's favourites page holding one of their assets, which it
+// lists before they are gone. Their cached assets are left to expire: every
+// read checks Access against the database first, which no longer finds them.
+func (c *CachedStore) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	if c.cache == nil {
+		return c.db.PurgeUser(ctx, userID)
+	}
+
+	var assetIDs []string
+	opts := ListOptions{Limit: MaxPageLimit}
+	for {
+		page, err := c.db.Get(ctx, userID, opts)
+		if err != nil {
+			log.Printf("cached_store: listing assets of user %v to purge: %v", userID, err)
+			break
+		}
+		for _, asset := range page.Items {
+			assetIDs = append(assetIDs, asset.GetID())
 		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	err := c.db.PurgeUser(ctx, userID)
+	if err == nil {
+		c.assetChanged(ctx, userID, assetIDs...)
+		c.del(ctx, favsCacheKey(userID), tagsCacheKey(userID))
 	}
+	return err
+}
+
+// ----- Collections, not cached -----
+
+func (c *CachedStore) GetCollections(ctx context.Context, userID uuid.UUID) ([]models.Collection, error) {
+	return c.db.GetCollections(ctx, userID)
+}
+
+func (c *CachedStore) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error) {
+	return c.db.GetCollection(ctx, userID, collectionID)
+}
+
+func (c *CachedStore) AddCollection(ctx context.Context, userID uuid.UUID, collection *models.Collection) error {
+	return c.db.AddCollection(ctx, userID, collection)
+}
+
+func (c *CachedStore) RenameCollection(ctx context.Context, userID, collectionID uuid.UUID, name string) error {
+	return c.db.RenameCollection(ctx, userID, collectionID, name)
+}
+
+func (c *CachedStore) RemoveCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	return c.db.RemoveCollection(ctx, userID, collectionID)
+}
 
-	return favs
+func (c *CachedStore) GetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error) {
+	return c.db.GetCollectionAssets(ctx, userID, collectionID)
 }
 
-func (c *CachedStore) AddFavourite(userID uuid.UUID, assetID, assetType string) bool {
-	res := c.db.AddFavourite(userID, assetID, assetType)
-	if res && c.cache != nil {
-		_ = c.cache.Del(context.Background(), favsCacheKey(userID))
+func (c *CachedStore) AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	return c.db.AddToCollection(ctx, userID, collectionID, assetID)
+}
+
+func (c *CachedStore) RemoveFromCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	return c.db.RemoveFromCollection(ctx, userID, collectionID, assetID)
+}
+
+func (c *CachedStore) SetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) error {
+	return c.db.SetCollectionAssets(ctx, userID, collectionID, assetIDs)
+}
+
+// ----- Tags; the tag cloud is cached -----
+
+func (c *CachedStore) GetTags(ctx context.Context, ownerID uuid.UUID, assetID string) ([]string, error) {
+	return c.db.GetTags(ctx, ownerID, assetID)
+}
+
+func (c *CachedStore) AddTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	err := c.db.AddTag(ctx, ownerID, assetID, tag)
+	if err == nil && c.cache != nil {
+		c.del(ctx, tagsCacheKey(ownerID))
+	}
+	return err
+}
+
+func (c *CachedStore) RemoveTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	err := c.db.RemoveTag(ctx, ownerID, assetID, tag)
+	if err == nil && c.cache != nil {
+		c.del(ctx, tagsCacheKey(ownerID))
 	}
-	return res
+	return err
 }
 
-func (c *CachedStore) RemoveFavourite(userID uuid.UUID, assetID string) bool {
-	res := c.db.RemoveFavourite(userID, assetID)
-	if res && c.cache != nil {
-		_ = c.cache.Del(context.Background(), favsCacheKey(userID))
+// TagCounts reads the user's tag cloud through Redis. Every write that can
+// change it (tagging, untagging, removing, reassigning or purging) drops the
+// entry.
+func (c *CachedStore) TagCounts(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error) {
+	if c.cache == nil {
+		return c.db.TagCounts(ctx, userID)
 	}
-	return res
+	return readThrough(ctx, c, tagsCacheKey(userID), cacheable[[]models.TagCount]{
+		load: func(ctx context.Context) ([]models.TagCount, error) {
+			return c.db.TagCounts(ctx, userID)
+		},
+		encode: func(cloud []models.TagCount) (string, error) {
+			b, err := json.Marshal(cloud)
+			return string(b), err
+		},
+		decode: func(cached string) ([]models.TagCount, error) {
+			var cloud []models.TagCount
+			err := json.Unmarshal([]byte(cached), &cloud)
+			return cloud, err
+		},
+	})
+}
+
+// ----- Search, not cached -----
+
+func (c *CachedStore) Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+	return c.db.Search(ctx, userID, query, limit)
 }
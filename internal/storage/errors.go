@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Errors returned by AssetStore implementations. Stores wrap them with
+// extra context, so callers should match them with errors.Is.
+var (
+	ErrNotFound    = errors.New("not found")
+	ErrConflict    = errors.New("conflict")
+	ErrValidation  = errors.New("validation failed")
+	ErrUnavailable = errors.New("store unavailable")
+	// ErrPreconditionFailed means the asset changed since the version the
+	// caller expected.
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrForbidden means the caller can see the asset but lacks the
+	// permission the operation needs.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// pgError translates a pgx error into one of the store errors above,
+// keeping the original error in the chain for logging.
+func pgError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, ErrNotFound)
+	}
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch {
+		case pgErr.Code == "23505", pgErr.Code == "23503":
+			// unique_violation, foreign_key_violation
+			return fmt.Errorf("%s: %w: %s", op, ErrConflict, pgErr.Message)
+		case pgErr.Code == "23502", pgErr.Code == "23514", pgErr.Code[:2] == "22":
+			// not_null_violation, check_violation, data exceptions
+			return fmt.Errorf("%s: %w: %s", op, ErrValidation, pgErr.Message)
+		}
+	}
+
+	return fmt.Errorf("%s: %w: %w", op, ErrUnavailable, err)
+}
+
+// pgInsertError is pgError for inserts of rows that reference an asset or
+// another row. A foreign key violation there means the row referenced is
+// gone, say deleted concurrently, rather than a conflict.
+func pgInsertError(op string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+		return fmt.Errorf("%s: %w: %s", op, ErrNotFound, pgErr.Message)
+	}
+	return pgError(op, err)
+}
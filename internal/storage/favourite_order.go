@@ -0,0 +1,51 @@
+package storage
+
+import "fmt"
+
+// positionKeySQL computes positionKey in SQL for a favourites row aliased f.
+const positionKeySQL = "(CASE WHEN f.pinned THEN '0' ELSE '1' END || lpad(f.position::text, 19, '0'))"
+
+// positionKey is the value favourites are ordered by for SortByPosition:
+// pinned ones first, then by position. Positions are never negative, so
+// padding them keeps the keys lexically sortable.
+func positionKey(pinned bool, position int) string {
+	flag := "1"
+	if pinned {
+		flag = "0"
+	}
+	return fmt.Sprintf("%s%019d", flag, position)
+}
+
+// moveID returns order with assetID moved just before targetID, or just
+// after it when after is set. It fails with ErrNotFound if either ID isn't in
+// order and with ErrValidation if they are the same.
+func moveID(order []string, assetID, targetID string, after bool) ([]string, error) {
+	if assetID == targetID {
+		return nil, fmt.Errorf("favourite %s can't be moved relative to itself: %w", assetID, ErrValidation)
+	}
+	rest := make([]string, 0, len(order))
+	found := false
+	for _, id := range order {
+		if id == assetID {
+			found = true
+			continue
+		}
+		rest = append(rest, id)
+	}
+	if !found {
+		return nil, fmt.Errorf("favourite %s: %w", assetID, ErrNotFound)
+	}
+	for i, id := range rest {
+		if id != targetID {
+			continue
+		}
+		if after {
+			i++
+		}
+		moved := make([]string, 0, len(order))
+		moved = append(moved, rest[:i]...)
+		moved = append(moved, assetID)
+		return append(moved, rest[i:]...), nil
+	}
+	return nil, fmt.Errorf("favourite %s: %w", targetID, ErrNotFound)
+}
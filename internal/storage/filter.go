@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"assetsApp/internal/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Op is the comparison of one filter condition. The name is the suffix of
+// the query term: social_hours_gte=3 is social_hours with OpGte.
+type Op string
+
+const (
+	OpEq  Op = "eq"
+	OpLt  Op = "lt"
+	OpLte Op = "lte"
+	OpGt  Op = "gt"
+	OpGte Op = "gte"
+)
+
+// FieldKind is the type of a filterable attribute.
+type FieldKind int
+
+const (
+	// StringField attributes are compared for equality with one or more
+	// values, e.g. country=GR,DK.
+	StringField FieldKind = iota
+	// IntField attributes take every Op and a single integer.
+	IntField
+)
+
+// FilterField is an attribute of an asset type that listings can filter on.
+type FilterField struct {
+	Kind FieldKind
+	// Column is the attribute's column in the type's table.
+	Column string
+	// Value reads the attribute from an asset of the type: a string for a
+	// StringField, an int for an IntField.
+	Value func(models.Asset) interface{}
+}
+
+// Condition is one parsed filter term. Value holds the []string an
+// equality on a StringField matches any of, or the int an IntField is
+// compared with.
+type Condition struct {
+	Field string
+	Op    Op
+	Value interface{}
+}
+
+type filterableType struct {
+	table  string
+	fields map[string]FilterField
+}
+
+var filterableTypes = make(map[string]filterableType)
+
+// RegisterFilterFields lets asset listings of the named type filter on the
+// given attributes, stored in table. It is meant to be called from init and
+// panics on duplicates.
+func RegisterFilterFields(assetType, table string, fields map[string]FilterField) {
+	if _, dup := filterableTypes[assetType]; dup {
+		panic("storage: filter fields for " + assetType + " registered twice")
+	}
+	filterableTypes[assetType] = filterableType{table: table, fields: fields}
+}
+
+func init() {
+	RegisterFilterFields("audience", "audiences", map[string]FilterField{
+		"gender":       {Kind: StringField, Column: "gender", Value: func(a models.Asset) interface{} { return a.(*models.Audience).Gender }},
+		"country":      {Kind: StringField, Column: "country", Value: func(a models.Asset) interface{} { return a.(*models.Audience).Country }},
+		"age_group":    {Kind: StringField, Column: "age_group", Value: func(a models.Asset) interface{} { return a.(*models.Audience).AgeGroup }},
+		"social_hours": {Kind: IntField, Column: "social_hours", Value: func(a models.Asset) interface{} { return a.(*models.Audience).SocialHours }},
+		"purchases":    {Kind: IntField, Column: "purchases", Value: func(a models.Asset) interface{} { return a.(*models.Audience).Purchases }},
+	})
+}
+
+// HasFilterFields reports whether listings of the asset type can filter on
+// its attributes.
+func HasFilterFields(assetType string) bool {
+	_, ok := filterableTypes[assetType]
+	return ok
+}
+
+// IsFilterKey reports whether a query term names an attribute of any
+// filterable type, with or without an Op suffix.
+func IsFilterKey(key string) bool {
+	field, _ := splitFilterKey(key)
+	for _, t := range filterableTypes {
+		if _, ok := t.fields[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseCondition parses one filter term of an asset listing of the given
+// type, such as ("social_hours_gte", ["3"]) or ("country", ["GR,DK"]).
+// Values of a string field may be repeated or comma-separated.
+func ParseCondition(assetType, key string, values []string) (Condition, error) {
+	fieldName, op := splitFilterKey(key)
+	t, ok := filterableTypes[assetType]
+	if !ok {
+		return Condition{}, fmt.Errorf("%s: filtering on attributes needs type=%s: %w", key, strings.Join(filterableTypeNames(), " or type="), ErrValidation)
+	}
+	field, ok := t.fields[fieldName]
+	if !ok {
+		return Condition{}, fmt.Errorf("%s: %s assets can't be filtered on %s (try %s): %w",
+			key, assetType, fieldName, strings.Join(t.fieldNames(), ", "), ErrValidation)
+	}
+
+	switch field.Kind {
+	case StringField:
+		if op != OpEq {
+			return Condition{}, fmt.Errorf("%s: %s can only be compared for equality: %w", key, fieldName, ErrValidation)
+		}
+		var matches []string
+		for _, v := range values {
+			for _, s := range strings.Split(v, ",") {
+				if s = strings.TrimSpace(s); s != "" {
+					matches = append(matches, s)
+				}
+			}
+		}
+		if len(matches) == 0 {
+			return Condition{}, fmt.Errorf("%s: a value is required: %w", key, ErrValidation)
+		}
+		return Condition{Field: fieldName, Op: op, Value: matches}, nil
+	default:
+		if len(values) != 1 {
+			return Condition{}, fmt.Errorf("%s: takes a single value: %w", key, ErrValidation)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(values[0]))
+		if err != nil {
+			return Condition{}, fmt.Errorf("%s: must be an integer: %w", key, ErrValidation)
+		}
+		return Condition{Field: fieldName, Op: op, Value: n}, nil
+	}
+}
+
+// splitFilterKey splits a term such as "purchases_lt" into its field and
+// Op; a term without an Op suffix is an equality.
+func splitFilterKey(key string) (string, Op) {
+	if i := strings.LastIndex(key, "_"); i > 0 {
+		switch op := Op(key[i+1:]); op {
+		case OpEq, OpLt, OpLte, OpGt, OpGte:
+			return key[:i], op
+		}
+	}
+	return key, OpEq
+}
+
+func filterableTypeNames() []string {
+	names := make([]string, 0, len(filterableTypes))
+	for name := range filterableTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t filterableType) fieldNames() []string {
+	names := make([]string, 0, len(t.fields))
+	for name := range t.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchesFilter reports whether an asset passes the type and attribute
+// filters of opts. It is MemoryStore's counterpart of filterSQL.
+func matchesFilter(asset models.Asset, opts ListOptions) bool {
+	if opts.Type == "" {
+		return true
+	}
+	if asset.GetType() != opts.Type {
+		return false
+	}
+	fields := filterableTypes[opts.Type].fields
+	for _, c := range opts.Where {
+		field, ok := fields[c.Field]
+		if !ok || !c.matches(field.Value(asset)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c Condition) matches(value interface{}) bool {
+	switch want := c.Value.(type) {
+	case []string:
+		for _, s := range want {
+			if value == s {
+				return true
+			}
+		}
+		return false
+	case int:
+		got, ok := value.(int)
+		if !ok {
+			return false
+		}
+		switch c.Op {
+		case OpLt:
+			return got < want
+		case OpLte:
+			return got <= want
+		case OpGt:
+			return got > want
+		case OpGte:
+			return got >= want
+		default:
+			return got == want
+		}
+	}
+	return false
+}
+
+// sqlOps maps each Op onto its SQL operator.
+var sqlOps = map[Op]string{OpEq: "=", OpLt: "<", OpLte: "<=", OpGt: ">", OpGte: ">="}
+
+// filterSQL compiles the type and attribute filters of opts into conditions
+// on the listing's assets (alias "a"), appending their values to args. Only
+// registered column names are put into the SQL text.
+func filterSQL(opts ListOptions, args []interface{}) (string, []interface{}, error) {
+	if opts.Type == "" {
+		if len(opts.Where) > 0 {
+			return "", nil, fmt.Errorf("attribute filters need an asset type: %w", ErrValidation)
+		}
+		return "", args, nil
+	}
+	args = append(args, opts.Type)
+	sql := fmt.Sprintf(" AND a.asset_type=$%d", len(args))
+	if len(opts.Where) == 0 {
+		return sql, args, nil
+	}
+
+	t := filterableTypes[opts.Type]
+	conds := make([]string, 0, len(opts.Where))
+	for _, c := range opts.Where {
+		field, ok := t.fields[c.Field]
+		if !ok {
+			return "", nil, fmt.Errorf("%s assets can't be filtered on %s: %w", opts.Type, c.Field, ErrValidation)
+		}
+		args = append(args, c.Value)
+		if _, many := c.Value.([]string); many {
+			conds = append(conds, fmt.Sprintf("f.%s = ANY($%d)", field.Column, len(args)))
+		} else {
+			conds = append(conds, fmt.Sprintf("f.%s %s $%d", field.Column, sqlOps[c.Op], len(args)))
+		}
+	}
+	sql += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM %s f WHERE f.id = a.asset_id AND %s)", t.table, strings.Join(conds, " AND "))
+	return sql, args, nil
+}
@@ -2,8 +2,12 @@ package storage
 
 import (
 	"assetsApp/internal/models"
+	"context"
+	"fmt"
 	"log"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,119 +15,886 @@ import (
 type MemoryStore struct {
 	mu         sync.RWMutex
 	store      map[uuid.UUID][]models.Asset
-	favourites map[uuid.UUID][]string
+	favourites map[uuid.UUID][]favouriteRecord
+	createdAt  map[uuid.UUID]map[string]time.Time
+	shares     map[string]map[uuid.UUID]models.Permission // asset ID -> grantee -> permission
+	apiKeys    map[string]apiKeyRecord                    // prefix -> key
+	users      map[uuid.UUID]string                       // user ID -> name
+	// user ID -> collection ID -> collection
+	collections map[uuid.UUID]map[uuid.UUID]*models.Collection
+	tags        map[string]map[string]bool // asset ID -> set of tags
+	search      map[string]searchDoc       // asset ID -> tokenised text
+}
+
+// favouriteRecord is one of a user's favourites.
+type favouriteRecord struct {
+	assetID   string
+	createdAt time.Time
+	position  int
+	pinned    bool
+	note      string
+}
+
+type apiKeyRecord struct {
+	key  models.APIKey
+	hash []byte
 }
 
 func NewMemoryStore() *MemoryStore {
 	return &MemoryStore{
 		store:      make(map[uuid.UUID][]models.Asset),
-		favourites: make(map[uuid.UUID][]string),
+		favourites: make(map[uuid.UUID][]favouriteRecord),
+		createdAt:  make(map[uuid.UUID]map[string]time.Time),
+		shares:     make(map[string]map[uuid.UUID]models.Permission),
+		apiKeys:    make(map[string]apiKeyRecord),
+		users:      make(map[uuid.UUID]string),
+
+		collections: make(map[uuid.UUID]map[uuid.UUID]*models.Collection),
+		tags:        make(map[string]map[string]bool),
+		search:      make(map[string]searchDoc),
 	}
 }
 
-// Add, Get, Remove, EditDescription for Assets
-func (m *MemoryStore) Get(userID uuid.UUID) []models.Asset {
+// Add, Get, Remove, Update for Assets
+func (m *MemoryStore) Get(_ context.Context, userID uuid.UUID, opts ListOptions) (AssetPage, error) {
 	log.Printf("Storage: Get called for user %v", userID)
+	if err := opts.validateAssetSort(); err != nil {
+		return AssetPage{}, err
+	}
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	assets := make([]models.Asset, len(m.store[userID]))
-	copy(assets, m.store[userID])
-	return assets
+	var members map[string]bool
+	if opts.Collection != uuid.Nil {
+		c := m.collections[userID][opts.Collection]
+		if c == nil {
+			return AssetPage{}, fmt.Errorf("collection %v: %w", opts.Collection, ErrNotFound)
+		}
+		members = make(map[string]bool, len(c.AssetIDs))
+		for _, id := range c.AssetIDs {
+			members[id] = true
+		}
+	}
+
+	items := make([]pageItem[models.Asset], 0, len(m.store[userID]))
+	for _, asset := range m.store[userID] {
+		if members != nil && !members[asset.GetID()] {
+			continue
+		}
+		if !m.matchesTags(asset.GetID(), opts) || !matchesFilter(asset, opts) {
+			continue
+		}
+		items = append(items, pageItem[models.Asset]{
+			key:  sortKey(opts.sortField(), asset, m.createdAt[userID][asset.GetID()]),
+			id:   asset.GetID(),
+			item: asset,
+		})
+	}
+	assets, next, err := paginate(items, opts)
+	if err != nil {
+		return AssetPage{}, err
+	}
+	return AssetPage{Items: assets, NextCursor: next}, nil
+}
+
+func (m *MemoryStore) GetByID(_ context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	log.Printf("Storage: GetByID called for user %v, asset %s", userID, assetID)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	asset := m.findAsset(userID, assetID)
+	if asset == nil {
+		return nil, fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	return asset, nil
 }
 
-func (m *MemoryStore) Add(userID uuid.UUID, asset models.Asset) {
+func (m *MemoryStore) Add(_ context.Context, userID uuid.UUID, asset models.Asset) error {
 	log.Printf("Storage: Add called for user %v", userID)
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	for _, a := range m.store[userID] {
-		if a.GetID() == asset.GetID() {
-			log.Printf("Storage: Add called for user %v, asset %s already exists", userID, asset.GetID())
-			// already exists, ignore or return an error
-			return
+	// Asset IDs are unique across users, as they are in Postgres.
+	for _, assets := range m.store {
+		for _, a := range assets {
+			if a.GetID() == asset.GetID() {
+				log.Printf("Storage: Add called for user %v, asset %s already exists", userID, asset.GetID())
+				return fmt.Errorf("asset %s: %w", asset.GetID(), ErrConflict)
+			}
 		}
 	}
+	asset.SetVersion(1)
+	m.ensureUser(userID, "User "+userID.String())
 	m.store[userID] = append(m.store[userID], asset)
+	if m.createdAt[userID] == nil {
+		m.createdAt[userID] = make(map[string]time.Time)
+	}
+	m.createdAt[userID][asset.GetID()] = time.Now()
+	m.search[asset.GetID()] = newSearchDoc(asset)
+	return nil
 }
 
-func (m *MemoryStore) Remove(userID uuid.UUID, assetID string) bool {
+func (m *MemoryStore) Remove(_ context.Context, userID uuid.UUID, assetID string, version int64) error {
 	log.Printf("Storage: Remove called for user %v, asset %s", userID, assetID)
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	assets, ok := m.store[userID]
-	if !ok {
-		return false
-	}
+	assets := m.store[userID]
 	for i := range assets {
 		if assets[i].GetID() == assetID {
+			if err := checkVersion(assets[i], version); err != nil {
+				return err
+			}
 			m.store[userID] = append(assets[:i], assets[i+1:]...)
-			return true
+			delete(m.createdAt[userID], assetID)
+			delete(m.shares, assetID)
+			delete(m.tags, assetID)
+			delete(m.search, assetID)
+			for favUserID := range m.favourites {
+				m.dropFavourite(favUserID, assetID)
+			}
+			m.dropFromCollections(userID, assetID)
+			return nil
 		}
 	}
-	return false
+	return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
 }
 
-func (m *MemoryStore) EditDescription(userID uuid.UUID, assetID, desc string) bool {
-	log.Printf("Storage: EditDescription called for user %v, asset %s", userID, assetID)
+func (m *MemoryStore) Update(_ context.Context, userID uuid.UUID, asset models.Asset) error {
+	log.Printf("Storage: Update called for user %v, asset %s", userID, asset.GetID())
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	assets, ok := m.store[userID]
-	if !ok {
-		return false
-	}
+	assets := m.store[userID]
 	for i := range assets {
-		if assets[i].GetID() == assetID {
-			assets[i].SetDescription(desc)
-			m.store[userID][i] = assets[i]
-			return true
+		if assets[i].GetID() == asset.GetID() {
+			if assets[i].GetType() != asset.GetType() {
+				return fmt.Errorf("asset %s is a %s, not a %s: %w", asset.GetID(), assets[i].GetType(), asset.GetType(), ErrValidation)
+			}
+			if err := checkVersion(assets[i], asset.GetVersion()); err != nil {
+				return err
+			}
+			asset.SetVersion(assets[i].GetVersion() + 1)
+			assets[i] = asset
+			m.search[asset.GetID()] = newSearchDoc(asset)
+			return nil
 		}
 	}
-	return false
+	return fmt.Errorf("asset %s: %w", asset.GetID(), ErrNotFound)
 }
 
 // Add, Get, Remove for Favourites
-func (m *MemoryStore) AddFavourite(userID uuid.UUID, assetID, assetType string) bool {
+func (m *MemoryStore) AddFavourite(_ context.Context, userID uuid.UUID, assetID, assetType string) error {
 	log.Printf("Storage: AddFavourite called for user %v, asset %s", userID, assetID)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	if _, asset := m.findOwned(assetID); asset == nil {
+		return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	m.ensureUser(userID, "Unknown")
+	last := 0
 	for _, fav := range m.favourites[userID] {
-		if fav == assetID {
-			return false // already exists
+		if fav.assetID == assetID {
+			return fmt.Errorf("favourite %s: %w", assetID, ErrConflict)
+		}
+		if fav.position > last {
+			last = fav.position
 		}
 	}
-	m.favourites[userID] = append(m.favourites[userID], assetID)
-	return true
+	m.favourites[userID] = append(m.favourites[userID], favouriteRecord{
+		assetID:   assetID,
+		createdAt: time.Now(),
+		position:  last + 1,
+	})
+	return nil
 }
 
-func (m *MemoryStore) RemoveFavourite(userID uuid.UUID, assetID string) bool {
+func (m *MemoryStore) RemoveFavourite(_ context.Context, userID uuid.UUID, assetID string) error {
 	log.Printf("Storage: RemoveFavourite called for user %v, asset %s", userID, assetID)
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	favs := m.favourites[userID]
 	for i, fav := range favs {
-		if fav == assetID {
+		if fav.assetID == assetID {
 			m.favourites[userID] = append(favs[:i], favs[i+1:]...)
-			return true
+			return nil
+		}
+	}
+	return fmt.Errorf("favourite %s: %w", assetID, ErrNotFound)
+}
+
+func (m *MemoryStore) UpdateFavourite(_ context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error {
+	log.Printf("Storage: UpdateFavourite called for user %v, asset %s", userID, assetID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	favs := m.favourites[userID]
+	for i := range favs {
+		if favs[i].assetID == assetID {
+			if update.Pinned != nil {
+				favs[i].pinned = *update.Pinned
+			}
+			if update.Note != nil {
+				favs[i].note = *update.Note
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("favourite %s: %w", assetID, ErrNotFound)
+}
+
+func (m *MemoryStore) MoveFavourite(_ context.Context, userID uuid.UUID, assetID, targetID string, after bool) error {
+	log.Printf("Storage: MoveFavourite called for user %v, asset %s", userID, assetID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	favs := m.favourites[userID]
+	sort.SliceStable(favs, func(i, j int) bool {
+		if favs[i].position != favs[j].position {
+			return favs[i].position < favs[j].position
 		}
+		return favs[i].assetID < favs[j].assetID
+	})
+	order := make([]string, len(favs))
+	for i, fav := range favs {
+		order[i] = fav.assetID
+	}
+	moved, err := moveID(order, assetID, targetID, after)
+	if err != nil {
+		return err
+	}
+	position := make(map[string]int, len(moved))
+	for i, id := range moved {
+		position[id] = i + 1
 	}
-	return false
+	for i := range favs {
+		favs[i].position = position[favs[i].assetID]
+	}
+	return nil
 }
 
-func (m *MemoryStore) GetFavourites(userID uuid.UUID) []models.Favourite {
+func (m *MemoryStore) GetFavourites(_ context.Context, userID uuid.UUID, opts ListOptions) (FavouritePage, error) {
 	log.Printf("Storage: GetFavourites called for user %v", userID)
+	opts = opts.favouriteOrder()
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	var result []models.Favourite
-	allAssets := m.store[userID]
-	for _, favID := range m.favourites[userID] {
-		for _, asset := range allAssets {
-			if asset.GetID() == favID {
-				result = append(result, models.Favourite{UserID: userID, Asset: asset})
+	var items []pageItem[models.Favourite]
+	for _, fav := range m.favourites[userID] {
+		owner, asset := m.findOwned(fav.assetID)
+		if asset == nil {
+			continue
+		}
+		key := positionKey(fav.pinned, fav.position)
+		if opts.sortField() != SortByPosition {
+			key = sortKey(opts.sortField(), asset, m.createdAt[owner][fav.assetID])
+		}
+		items = append(items, pageItem[models.Favourite]{
+			key: key,
+			id:  fav.assetID,
+			item: models.Favourite{
+				UserID:    userID,
+				Asset:     asset,
+				CreatedAt: fav.createdAt,
+				Position:  fav.position,
+				Pinned:    fav.pinned,
+				Note:      fav.note,
+			},
+		})
+	}
+	favs, next, err := paginate(items, opts)
+	if err != nil {
+		return FavouritePage{}, err
+	}
+	return FavouritePage{Items: favs, NextCursor: next}, nil
+}
+
+// findAsset returns the user's asset with the given ID, or nil. Callers must
+// hold m.mu.
+func (m *MemoryStore) findAsset(userID uuid.UUID, assetID string) models.Asset {
+	for _, asset := range m.store[userID] {
+		if asset.GetID() == assetID {
+			return asset
+		}
+	}
+	return nil
+}
+
+// checkVersion reports whether the caller's expected version allows changing
+// the stored asset.
+func checkVersion(stored models.Asset, version int64) error {
+	if version != AnyVersion && stored.GetVersion() != version {
+		return fmt.Errorf("asset %s is at version %d, not %d: %w", stored.GetID(), stored.GetVersion(), version, ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// findOwned returns the asset with the given ID and its owner, whoever that
+// is, or a nil asset. Callers must hold m.mu.
+func (m *MemoryStore) findOwned(assetID string) (uuid.UUID, models.Asset) {
+	for owner, assets := range m.store {
+		for _, asset := range assets {
+			if asset.GetID() == assetID {
+				return owner, asset
 			}
 		}
 	}
-	return result
+	return uuid.Nil, nil
+}
+
+// ensureUser records a user the first time they appear, like the users rows
+// PostgresStore inserts.
+func (m *MemoryStore) ensureUser(userID uuid.UUID, name string) {
+	if _, ok := m.users[userID]; !ok {
+		m.users[userID] = name
+	}
+}
+
+// Access, Share, Unshare and listings for shared assets
+func (m *MemoryStore) Access(_ context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	owner, asset := m.findOwned(assetID)
+	switch {
+	case asset == nil:
+		return uuid.Nil, "", fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	case owner == userID:
+		return owner, models.PermissionOwner, nil
+	}
+	if perm, ok := m.shares[assetID][userID]; ok {
+		return owner, perm, nil
+	}
+	return uuid.Nil, "", fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+}
+
+func (m *MemoryStore) GetShares(_ context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findAsset(ownerID, assetID) == nil {
+		return nil, fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	shares := make([]models.Share, 0, len(m.shares[assetID]))
+	for userID, perm := range m.shares[assetID] {
+		shares = append(shares, models.Share{AssetID: assetID, OwnerID: ownerID, UserID: userID, Permission: perm})
+	}
+	sort.Slice(shares, func(i, j int) bool { return shares[i].UserID.String() < shares[j].UserID.String() })
+	return shares, nil
+}
+
+func (m *MemoryStore) Share(_ context.Context, share models.Share) error {
+	log.Printf("Storage: Share called for asset %s, user %v", share.AssetID, share.UserID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findAsset(share.OwnerID, share.AssetID) == nil {
+		return fmt.Errorf("asset %s: %w", share.AssetID, ErrNotFound)
+	}
+	m.ensureUser(share.UserID, "Unknown")
+	if m.shares[share.AssetID] == nil {
+		m.shares[share.AssetID] = make(map[uuid.UUID]models.Permission)
+	}
+	m.shares[share.AssetID][share.UserID] = share.Permission
+	return nil
+}
+
+func (m *MemoryStore) Unshare(_ context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error {
+	log.Printf("Storage: Unshare called for asset %s, user %v", assetID, userID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findAsset(ownerID, assetID) == nil {
+		return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	if _, ok := m.shares[assetID][userID]; !ok {
+		return fmt.Errorf("share of %s with %v: %w", assetID, userID, ErrNotFound)
+	}
+	delete(m.shares[assetID], userID)
+	m.dropFavourite(userID, assetID)
+	return nil
+}
+
+// dropFavourite removes the user's favourite of the asset, if they have one.
+func (m *MemoryStore) dropFavourite(userID uuid.UUID, assetID string) {
+	favs := m.favourites[userID]
+	for i, fav := range favs {
+		if fav.assetID == assetID {
+			m.favourites[userID] = append(favs[:i], favs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *MemoryStore) GetSharedWithMe(_ context.Context, userID uuid.UUID, opts ListOptions) (SharedPage, error) {
+	if err := opts.validateAssetSort(); err != nil {
+		return SharedPage{}, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var items []pageItem[models.SharedAsset]
+	for assetID, grants := range m.shares {
+		perm, ok := grants[userID]
+		if !ok {
+			continue
+		}
+		owner, asset := m.findOwned(assetID)
+		if asset == nil {
+			continue
+		}
+		items = append(items, pageItem[models.SharedAsset]{
+			key:  sortKey(opts.sortField(), asset, m.createdAt[owner][assetID]),
+			id:   assetID,
+			item: models.SharedAsset{OwnerID: owner, Permission: perm, Asset: asset},
+		})
+	}
+	shared, next, err := paginate(items, opts)
+	if err != nil {
+		return SharedPage{}, err
+	}
+	return SharedPage{Items: shared, NextCursor: next}, nil
+}
+
+// API keys
+func (m *MemoryStore) AddAPIKey(_ context.Context, key models.APIKey, hash []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for prefix, rec := range m.apiKeys {
+		if prefix == key.Prefix || rec.key.ID == key.ID {
+			return fmt.Errorf("api key %s: %w", key.Prefix, ErrConflict)
+		}
+	}
+	m.apiKeys[key.Prefix] = apiKeyRecord{key: key, hash: hash}
+	return nil
+}
+
+func (m *MemoryStore) GetAPIKey(_ context.Context, prefix string) (models.APIKey, []byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rec, ok := m.apiKeys[prefix]
+	if !ok {
+		return models.APIKey{}, nil, fmt.Errorf("api key %s: %w", prefix, ErrNotFound)
+	}
+	return rec.key, rec.hash, nil
+}
+
+func (m *MemoryStore) ListAPIKeys(_ context.Context) ([]models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(m.apiKeys))
+	for _, rec := range m.apiKeys {
+		keys = append(keys, rec.key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.String() < keys[j].ID.String()
+	})
+	return keys, nil
+}
+
+func (m *MemoryStore) RevokeAPIKey(_ context.Context, id uuid.UUID, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for prefix, rec := range m.apiKeys {
+		if rec.key.ID != id {
+			continue
+		}
+		if rec.key.RevokedAt == nil {
+			rec.key.RevokedAt = &at
+			m.apiKeys[prefix] = rec
+		}
+		return nil
+	}
+	return fmt.Errorf("api key %v: %w", id, ErrNotFound)
+}
+
+// Users, across all of them
+func (m *MemoryStore) ListUsers(_ context.Context, opts ListOptions) (UserPage, error) {
+	if err := opts.validateUserSort(); err != nil {
+		return UserPage{}, err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := make([]pageItem[models.User], 0, len(m.users))
+	for id, name := range m.users {
+		items = append(items, pageItem[models.User]{key: id.String(), id: id.String(), item: models.User{ID: id, Name: name}})
+	}
+	users, next, err := paginate(items, opts)
+	if err != nil {
+		return UserPage{}, err
+	}
+	return UserPage{Items: users, NextCursor: next}, nil
+}
+
+func (m *MemoryStore) Reassign(_ context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error) {
+	log.Printf("Storage: Reassign called for asset %s, new owner %v", assetID, newOwnerID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	owner, asset := m.findOwned(assetID)
+	if asset == nil {
+		return uuid.Nil, fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	if owner == newOwnerID {
+		return owner, nil
+	}
+
+	assets := m.store[owner]
+	for i, a := range assets {
+		if a.GetID() == assetID {
+			m.store[owner] = append(assets[:i], assets[i+1:]...)
+			break
+		}
+	}
+	created := m.createdAt[owner][assetID]
+	delete(m.createdAt[owner], assetID)
+
+	m.ensureUser(newOwnerID, "Unknown")
+	asset.SetVersion(asset.GetVersion() + 1)
+	m.store[newOwnerID] = append(m.store[newOwnerID], asset)
+	if m.createdAt[newOwnerID] == nil {
+		m.createdAt[newOwnerID] = make(map[string]time.Time)
+	}
+	m.createdAt[newOwnerID][assetID] = created
+	delete(m.shares[assetID], newOwnerID)
+	m.dropFavourite(owner, assetID)
+	m.dropFromCollections(owner, assetID)
+	return owner, nil
+}
+
+func (m *MemoryStore) PurgeUser(_ context.Context, userID uuid.UUID) error {
+	log.Printf("Storage: PurgeUser called for user %v", userID)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[userID]; !ok {
+		return fmt.Errorf("user %v: %w", userID, ErrNotFound)
+	}
+
+	owned := make(map[string]bool, len(m.store[userID]))
+	for _, asset := range m.store[userID] {
+		owned[asset.GetID()] = true
+		delete(m.shares, asset.GetID())
+		delete(m.tags, asset.GetID())
+		delete(m.search, asset.GetID())
+	}
+	for id, favs := range m.favourites {
+		kept := favs[:0]
+		for _, fav := range favs {
+			if !owned[fav.assetID] {
+				kept = append(kept, fav)
+			}
+		}
+		m.favourites[id] = kept
+	}
+	for _, grants := range m.shares {
+		delete(grants, userID)
+	}
+
+	delete(m.store, userID)
+	delete(m.collections, userID)
+	delete(m.createdAt, userID)
+	delete(m.favourites, userID)
+	delete(m.users, userID)
+	return nil
+}
+
+// Collections
+func (m *MemoryStore) GetCollections(_ context.Context, userID uuid.UUID) ([]models.Collection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	collections := make([]models.Collection, 0, len(m.collections[userID]))
+	for _, c := range m.collections[userID] {
+		collections = append(collections, copyCollection(c))
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		if collections[i].Name != collections[j].Name {
+			return collections[i].Name < collections[j].Name
+		}
+		return collections[i].ID.String() < collections[j].ID.String()
+	})
+	return collections, nil
+}
+
+func (m *MemoryStore) GetCollection(_ context.Context, userID, collectionID uuid.UUID) (models.Collection, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return models.Collection{}, err
+	}
+	return copyCollection(c), nil
+}
+
+func (m *MemoryStore) AddCollection(_ context.Context, userID uuid.UUID, collection *models.Collection) error {
+	log.Printf("Storage: AddCollection called for user %v, collection %q", userID, collection.Name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.collections[userID] {
+		if c.ID == collection.ID || c.Name == collection.Name {
+			return fmt.Errorf("collection %q: %w", collection.Name, ErrConflict)
+		}
+	}
+	m.ensureUser(userID, "Unknown")
+	collection.CreatedAt = time.Now()
+	collection.AssetIDs = []string{}
+	if m.collections[userID] == nil {
+		m.collections[userID] = make(map[uuid.UUID]*models.Collection)
+	}
+	stored := copyCollection(collection)
+	m.collections[userID][collection.ID] = &stored
+	return nil
+}
+
+func (m *MemoryStore) RenameCollection(_ context.Context, userID, collectionID uuid.UUID, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	for _, other := range m.collections[userID] {
+		if other != c && other.Name == name {
+			return fmt.Errorf("collection %q: %w", name, ErrConflict)
+		}
+	}
+	c.Name = name
+	return nil
+}
+
+func (m *MemoryStore) RemoveCollection(_ context.Context, userID, collectionID uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, err := m.findCollection(userID, collectionID); err != nil {
+		return err
+	}
+	delete(m.collections[userID], collectionID)
+	return nil
+}
+
+func (m *MemoryStore) GetCollectionAssets(_ context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	assets := make([]models.Asset, 0, len(c.AssetIDs))
+	for _, id := range c.AssetIDs {
+		if asset := m.findAsset(userID, id); asset != nil {
+			assets = append(assets, asset)
+		}
+	}
+	return assets, nil
+}
+
+func (m *MemoryStore) AddToCollection(_ context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	if m.findAsset(userID, assetID) == nil {
+		return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	for _, id := range c.AssetIDs {
+		if id == assetID {
+			return nil
+		}
+	}
+	c.AssetIDs = append(c.AssetIDs, assetID)
+	return nil
+}
+
+func (m *MemoryStore) RemoveFromCollection(_ context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	for i, id := range c.AssetIDs {
+		if id == assetID {
+			c.AssetIDs = append(c.AssetIDs[:i], c.AssetIDs[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("asset %s in collection %v: %w", assetID, collectionID, ErrNotFound)
+}
+
+func (m *MemoryStore) SetCollectionAssets(_ context.Context, userID, collectionID uuid.UUID, assetIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, err := m.findCollection(userID, collectionID)
+	if err != nil {
+		return err
+	}
+	seen := make(map[string]bool, len(assetIDs))
+	for _, id := range assetIDs {
+		if m.findAsset(userID, id) == nil {
+			return fmt.Errorf("asset %s: %w", id, ErrNotFound)
+		}
+		if seen[id] {
+			return fmt.Errorf("asset %s listed twice: %w", id, ErrValidation)
+		}
+		seen[id] = true
+	}
+	c.AssetIDs = append([]string{}, assetIDs...)
+	return nil
+}
+
+func (m *MemoryStore) findCollection(userID, collectionID uuid.UUID) (*models.Collection, error) {
+	c := m.collections[userID][collectionID]
+	if c == nil {
+		return nil, fmt.Errorf("collection %v: %w", collectionID, ErrNotFound)
+	}
+	return c, nil
+}
+
+// dropFromCollections removes an asset from its owner's collections, which
+// are the only ones it can be in.
+func (m *MemoryStore) dropFromCollections(ownerID uuid.UUID, assetID string) {
+	for _, c := range m.collections[ownerID] {
+		for i, id := range c.AssetIDs {
+			if id == assetID {
+				c.AssetIDs = append(c.AssetIDs[:i], c.AssetIDs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func copyCollection(c *models.Collection) models.Collection {
+	copied := *c
+	copied.AssetIDs = append([]string{}, c.AssetIDs...)
+	return copied
+}
+
+// Tags
+func (m *MemoryStore) GetTags(_ context.Context, ownerID uuid.UUID, assetID string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.findAsset(ownerID, assetID) == nil {
+		return nil, fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	tags := make([]string, 0, len(m.tags[assetID]))
+	for tag := range m.tags[assetID] {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+func (m *MemoryStore) AddTag(_ context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findAsset(ownerID, assetID) == nil {
+		return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	if m.tags[assetID] == nil {
+		m.tags[assetID] = make(map[string]bool)
+	}
+	m.tags[assetID][tag] = true
+	return nil
+}
+
+func (m *MemoryStore) RemoveTag(_ context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findAsset(ownerID, assetID) == nil {
+		return fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+	}
+	if !m.tags[assetID][tag] {
+		return fmt.Errorf("tag %q on asset %s: %w", tag, assetID, ErrNotFound)
+	}
+	delete(m.tags[assetID], tag)
+	return nil
+}
+
+func (m *MemoryStore) TagCounts(_ context.Context, userID uuid.UUID) ([]models.TagCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, asset := range m.store[userID] {
+		for tag := range m.tags[asset.GetID()] {
+			counts[tag]++
+		}
+	}
+	cloud := make([]models.TagCount, 0, len(counts))
+	for tag, n := range counts {
+		cloud = append(cloud, models.TagCount{Tag: tag, Count: n})
+	}
+	sort.Slice(cloud, func(i, j int) bool {
+		if cloud[i].Count != cloud[j].Count {
+			return cloud[i].Count > cloud[j].Count
+		}
+		return cloud[i].Tag < cloud[j].Tag
+	})
+	return cloud, nil
+}
+
+// matchesTags reports whether an asset passes the tag filter of opts.
+func (m *MemoryStore) matchesTags(assetID string, opts ListOptions) bool {
+	if len(opts.Tags) == 0 {
+		return true
+	}
+	for _, tag := range opts.Tags {
+		has := m.tags[assetID][tag]
+		if opts.AnyTag && has {
+			return true
+		}
+		if !opts.AnyTag && !has {
+			return false
+		}
+	}
+	return !opts.AnyTag
+}
+
+// Search ranks the user's assets against the tokenised query using the
+// search index, a simpler stand-in for Postgres' full-text search.
+func (m *MemoryStore) Search(_ context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	terms := tokenize(query)
+	results := []models.SearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+	for _, asset := range m.store[userID] {
+		doc := m.search[asset.GetID()]
+		if rank, ok := doc.rank(terms); ok {
+			results = append(results, models.SearchResult{Asset: asset, Rank: rank, Snippet: doc.snippet(terms)})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Asset.GetID() < results[j].Asset.GetID()
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
 }
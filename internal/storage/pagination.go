@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"assetsApp/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SortField names a column listings can be ordered by. Ties are always
+// broken by asset ID so that cursors are stable.
+type SortField string
+
+const (
+	SortByID      SortField = "id"
+	SortByTitle   SortField = "title"
+	SortByType    SortField = "type"
+	SortByCreated SortField = "created_at"
+	// SortByPosition orders favourites pinned first and then by position. It
+	// is the default for favourites and doesn't apply to other listings.
+	SortByPosition SortField = "position"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 500
+)
+
+// cursorTimeFormat keeps timestamps lexically sortable inside cursors.
+const cursorTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// ListOptions controls paging and ordering of asset and favourite listings.
+// The zero value returns the first DefaultPageLimit items ordered by ID, or
+// for favourites by position.
+type ListOptions struct {
+	Limit  int
+	Cursor string
+	Sort   SortField
+	Desc   bool
+
+	// Collection limits an asset listing to the members of one of the
+	// user's collections. Other listings ignore it.
+	Collection uuid.UUID
+	// Tags limits an asset listing to assets carrying every one of the tags,
+	// or any of them when AnyTag is set. Other listings ignore both.
+	Tags   []string
+	AnyTag bool
+	// Type limits an asset listing to one asset type, and Where further to
+	// assets of that type whose attributes meet every condition; see
+	// ParseCondition. Other listings ignore both.
+	Type  string
+	Where []Condition
+}
+
+// AssetPage is one page of a user's assets.
+type AssetPage struct {
+	Items      []models.Asset `json:"items"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// FavouritePage is one page of a user's favourites.
+type FavouritePage struct {
+	Items      []models.Favourite `json:"items"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+// SharedPage is one page of the assets other users have shared with a user.
+type SharedPage struct {
+	Items      []models.SharedAsset `json:"items"`
+	NextCursor string               `json:"next_cursor,omitempty"`
+}
+
+// UserPage is one page of users, always ordered by ID.
+type UserPage struct {
+	Items      []models.User `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+// cursor is the decoded form of the opaque string handed to clients. It
+// records the sort it was issued for so it can't be replayed against another.
+type cursor struct {
+	Sort SortField `json:"s"`
+	Desc bool      `json:"d,omitempty"`
+	Key  string    `json:"k"`
+	ID   string    `json:"id"`
+}
+
+// ParseSort parses a sort query value such as "title" or "-created_at".
+// An empty value selects the default ordering.
+func ParseSort(value string) (SortField, bool, error) {
+	desc := strings.HasPrefix(value, "-")
+	field := SortField(strings.TrimPrefix(value, "-"))
+	switch field {
+	case "":
+		return SortByID, false, nil
+	case SortByID, SortByTitle, SortByType, SortByCreated:
+		return field, desc, nil
+	}
+	return "", false, fmt.Errorf("unknown sort field %q: %w", field, ErrValidation)
+}
+
+// ParseFavouriteSort is ParseSort for favourites, which can also be sorted
+// by position and are by default.
+func ParseFavouriteSort(value string) (SortField, bool, error) {
+	switch value {
+	case "", string(SortByPosition):
+		return SortByPosition, false, nil
+	case "-" + string(SortByPosition):
+		return SortByPosition, true, nil
+	}
+	return ParseSort(value)
+}
+
+// ParseUserSort is ParseSort for users, which can only be sorted by id.
+func ParseUserSort(value string) (SortField, bool, error) {
+	switch value {
+	case "", string(SortByID):
+		return SortByID, false, nil
+	case "-" + string(SortByID):
+		return SortByID, true, nil
+	}
+	return "", false, fmt.Errorf("unknown sort field %q: %w", strings.TrimPrefix(value, "-"), ErrValidation)
+}
+
+// validateUserSort rejects orderings users can't be listed in.
+func (o ListOptions) validateUserSort() error {
+	if o.sortField() != SortByID {
+		return fmt.Errorf("users can only be sorted by id: %w", ErrValidation)
+	}
+	return nil
+}
+
+// Validate checks the limit, sort field and cursor without touching a store.
+func (o ListOptions) Validate() error {
+	if o.Limit < 0 || o.Limit > MaxPageLimit {
+		return fmt.Errorf("limit must be between 1 and %d: %w", MaxPageLimit, ErrValidation)
+	}
+	if _, _, err := ParseFavouriteSort(string(o.sortField())); err != nil {
+		return err
+	}
+	_, err := o.decodeCursor()
+	return err
+}
+
+// validateAssetSort rejects the orderings only favourites can be listed in.
+func (o ListOptions) validateAssetSort() error {
+	if o.sortField() == SortByPosition {
+		return fmt.Errorf("only favourites can be sorted by position: %w", ErrValidation)
+	}
+	return nil
+}
+
+// IsDefault reports whether the options request the default first page.
+func (o ListOptions) IsDefault() bool {
+	return o.sortField() == SortByID && o.isFirstPage()
+}
+
+// isDefaultFavourites reports whether the options request the default first
+// page of favourites.
+func (o ListOptions) isDefaultFavourites() bool {
+	return o.favouriteOrder().Sort == SortByPosition && o.isFirstPage()
+}
+
+// isFirstPage reports whether the options request an unfiltered first page
+// of the default size and direction.
+func (o ListOptions) isFirstPage() bool {
+	return o.Cursor == "" && !o.Desc && o.limit() == DefaultPageLimit &&
+		o.Collection == uuid.Nil && len(o.Tags) == 0 && o.Type == "" && len(o.Where) == 0
+}
+
+// favouriteOrder fills in the default ordering of favourites.
+func (o ListOptions) favouriteOrder() ListOptions {
+	if o.Sort == "" {
+		o.Sort = SortByPosition
+	}
+	return o
+}
+
+func (o ListOptions) limit() int {
+	if o.Limit <= 0 {
+		return DefaultPageLimit
+	}
+	if o.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return o.Limit
+}
+
+func (o ListOptions) sortField() SortField {
+	if o.Sort == "" {
+		return SortByID
+	}
+	return o.Sort
+}
+
+func (o ListOptions) decodeCursor() (*cursor, error) {
+	if o.Cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(o.Cursor)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", ErrValidation)
+	}
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("malformed cursor: %w", ErrValidation)
+	}
+	if c.Sort != o.sortField() || c.Desc != o.Desc {
+		return nil, fmt.Errorf("cursor was issued for a different sort: %w", ErrValidation)
+	}
+	return &c, nil
+}
+
+func (o ListOptions) encodeCursor(key, id string) string {
+	raw, _ := json.Marshal(cursor{Sort: o.sortField(), Desc: o.Desc, Key: key, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// sortKey returns the value an asset is ordered by for the given field.
+func sortKey(field SortField, asset models.Asset, createdAt time.Time) string {
+	switch field {
+	case SortByTitle:
+		return asset.GetTitle()
+	case SortByType:
+		return asset.GetType()
+	case SortByCreated:
+		return createdAt.UTC().Format(cursorTimeFormat)
+	}
+	return asset.GetID()
+}
+
+// pageItem is an item with its precomputed sort key, used by stores that
+// page in memory.
+type pageItem[T any] struct {
+	key  string
+	id   string
+	item T
+}
+
+// paginate orders items by (key, id), skips past the cursor and cuts one
+// page, returning the cursor for the next page if there is one.
+func paginate[T any](items []pageItem[T], opts ListOptions) ([]T, string, error) {
+	after, err := opts.decodeCursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	less := func(a, b pageItem[T]) bool {
+		if a.key != b.key {
+			return a.key < b.key
+		}
+		return a.id < b.id
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if opts.Desc {
+			return less(items[j], items[i])
+		}
+		return less(items[i], items[j])
+	})
+
+	start := 0
+	if after != nil {
+		pivot := pageItem[T]{key: after.Key, id: after.ID}
+		start = sort.Search(len(items), func(i int) bool {
+			if opts.Desc {
+				return less(items[i], pivot)
+			}
+			return less(pivot, items[i])
+		})
+	}
+
+	end := start + opts.limit()
+	next := ""
+	if end < len(items) {
+		last := items[end-1]
+		next = opts.encodeCursor(last.key, last.id)
+	} else {
+		end = len(items)
+	}
+
+	page := make([]T, 0, end-start)
+	for _, it := range items[start:end] {
+		page = append(page, it.item)
+	}
+	return page, next, nil
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"assetsApp/internal/models"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PostgresMapper persists one asset type in its type-specific table(s). The
+// generic assets row is written by PostgresStore itself, and type rows are
+// removed through ON DELETE CASCADE.
+type PostgresMapper interface {
+	// Insert writes the type-specific rows of a new asset.
+	Insert(ctx context.Context, tx pgx.Tx, asset models.Asset) error
+	// QueueLoad queues the queries that load the assets with the given IDs;
+	// their callbacks add each loaded asset to loaded.
+	QueueLoad(batch *pgx.Batch, ids []string, loaded map[string]models.Asset)
+	// Update overwrites the type-specific rows of an existing asset.
+	Update(ctx context.Context, tx pgx.Tx, asset models.Asset) error
+}
+
+var postgresMappers = make(map[string]PostgresMapper)
+
+// RegisterPostgresMapper makes PostgresStore able to persist the named asset
+// type. It is meant to be called from init and panics on duplicates.
+func RegisterPostgresMapper(assetType string, m PostgresMapper) {
+	if _, dup := postgresMappers[assetType]; dup {
+		panic("storage: postgres mapper for " + assetType + " registered twice")
+	}
+	postgresMappers[assetType] = m
+}
+
+func postgresMapperFor(assetType string) (PostgresMapper, error) {
+	m, ok := postgresMappers[assetType]
+	if !ok {
+		return nil, fmt.Errorf("no postgres mapper for asset type %q: %w", assetType, ErrValidation)
+	}
+	return m, nil
+}
+
+func init() {
+	RegisterPostgresMapper("chart", chartMapper{})
+	RegisterPostgresMapper("insight", insightMapper{})
+	RegisterPostgresMapper("audience", audienceMapper{})
+}
+
+// ----------------- chart -----------------
+
+type chartMapper struct{}
+
+func (chartMapper) Insert(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	c := asset.(*models.Chart)
+	_, err := tx.Exec(ctx,
+		"INSERT INTO charts (id, title, description, x_axis_title, y_axis_title) VALUES ($1,$2,$3,$4,$5)",
+		c.ID, c.Title, c.Description, c.XAxisTitle, c.YAxisTitle,
+	)
+	if err != nil {
+		return pgError("insert chart", err)
+	}
+
+	for _, d := range c.Data {
+		_, err = tx.Exec(ctx,
+			"INSERT INTO chart_data (chart_id, datapoint_code, value) VALUES ($1,$2,$3)",
+			c.ID, d.DatapointCode, d.Value,
+		)
+		if err != nil {
+			return pgError("insert chart data", err)
+		}
+	}
+	return nil
+}
+
+func (chartMapper) QueueLoad(batch *pgx.Batch, ids []string, loaded map[string]models.Asset) {
+	charts := make(map[string]*models.Chart, len(ids))
+	batch.Queue(`
+		SELECT id, title, description, x_axis_title, y_axis_title
+		FROM charts WHERE id = ANY($1)`, ids).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var c models.Chart
+			if err := rows.Scan(&c.ID, &c.Title, &c.Description, &c.XAxisTitle, &c.YAxisTitle); err != nil {
+				return err
+			}
+			charts[c.ID] = &c
+			loaded[c.ID] = &c
+		}
+		return rows.Err()
+	})
+	batch.Queue(`
+		SELECT chart_id, datapoint_code, value
+		FROM chart_data WHERE chart_id = ANY($1)
+		ORDER BY chart_id, datapoint_code`, ids).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var chartID string
+			var dp models.ChartData
+			if err := rows.Scan(&chartID, &dp.DatapointCode, &dp.Value); err != nil {
+				return err
+			}
+			if c, ok := charts[chartID]; ok {
+				c.Data = append(c.Data, dp)
+			}
+		}
+		return rows.Err()
+	})
+}
+
+func (chartMapper) Update(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	c := asset.(*models.Chart)
+	_, err := tx.Exec(ctx,
+		"UPDATE charts SET title=$1, description=$2, x_axis_title=$3, y_axis_title=$4 WHERE id=$5",
+		c.Title, c.Description, c.XAxisTitle, c.YAxisTitle, c.ID,
+	)
+	if err != nil {
+		return pgError("update chart", err)
+	}
+
+	// Data points are replaced wholesale.
+	if _, err := tx.Exec(ctx, "DELETE FROM chart_data WHERE chart_id=$1", c.ID); err != nil {
+		return pgError("delete chart data", err)
+	}
+	for _, d := range c.Data {
+		_, err = tx.Exec(ctx,
+			"INSERT INTO chart_data (chart_id, datapoint_code, value) VALUES ($1,$2,$3)",
+			c.ID, d.DatapointCode, d.Value,
+		)
+		if err != nil {
+			return pgError("insert chart data", err)
+		}
+	}
+	return nil
+}
+
+// ----------------- insight -----------------
+
+type insightMapper struct{}
+
+func (insightMapper) Insert(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	i := asset.(*models.Insight)
+	_, err := tx.Exec(ctx,
+		"INSERT INTO insights (id, description) VALUES ($1,$2)",
+		i.ID, i.Description,
+	)
+	return pgError("insert insight", err)
+}
+
+func (insightMapper) QueueLoad(batch *pgx.Batch, ids []string, loaded map[string]models.Asset) {
+	batch.Queue(`SELECT id, description FROM insights WHERE id = ANY($1)`, ids).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var i models.Insight
+			if err := rows.Scan(&i.ID, &i.Description); err != nil {
+				return err
+			}
+			loaded[i.ID] = &i
+		}
+		return rows.Err()
+	})
+}
+
+func (insightMapper) Update(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	i := asset.(*models.Insight)
+	_, err := tx.Exec(ctx, "UPDATE insights SET description=$1 WHERE id=$2", i.Description, i.ID)
+	return pgError("update insight", err)
+}
+
+// ----------------- audience -----------------
+
+type audienceMapper struct{}
+
+func (audienceMapper) Insert(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	a := asset.(*models.Audience)
+	_, err := tx.Exec(ctx,
+		"INSERT INTO audiences (id, gender, country, age_group, social_hours, purchases, description) VALUES ($1,$2,$3,$4,$5,$6,$7)",
+		a.ID, a.Gender, a.Country, a.AgeGroup, a.SocialHours, a.Purchases, a.Description,
+	)
+	return pgError("insert audience", err)
+}
+
+func (audienceMapper) QueueLoad(batch *pgx.Batch, ids []string, loaded map[string]models.Asset) {
+	batch.Queue(`
+		SELECT id, gender, country, age_group, social_hours, purchases, description
+		FROM audiences WHERE id = ANY($1)`, ids).Query(func(rows pgx.Rows) error {
+		for rows.Next() {
+			var a models.Audience
+			if err := rows.Scan(&a.ID, &a.Gender, &a.Country, &a.AgeGroup, &a.SocialHours, &a.Purchases, &a.Description); err != nil {
+				return err
+			}
+			loaded[a.ID] = &a
+		}
+		return rows.Err()
+	})
+}
+
+func (audienceMapper) Update(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	a := asset.(*models.Audience)
+	_, err := tx.Exec(ctx,
+		"UPDATE audiences SET gender=$1, country=$2, age_group=$3, social_hours=$4, purchases=$5, description=$6 WHERE id=$7",
+		a.Gender, a.Country, a.AgeGroup, a.SocialHours, a.Purchases, a.Description, a.ID,
+	)
+	return pgError("update audience", err)
+}
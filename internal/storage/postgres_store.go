@@ -3,9 +3,14 @@ package storage
 import (
 	"assetsApp/internal/models"
 	"context"
+	"fmt"
+	"html"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,9 +24,7 @@ func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
 
 // ----------------- Asset Methods -----------------
 
-func (p *PostgresStore) Add(userID uuid.UUID, asset models.Asset) {
-	ctx := context.Background()
-
+func (p *PostgresStore) Add(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
 	// Ensure user exists
 	_, err := p.pool.Exec(ctx,
 		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT DO NOTHING",
@@ -29,276 +32,354 @@ func (p *PostgresStore) Add(userID uuid.UUID, asset models.Asset) {
 	)
 	if err != nil {
 		log.Println("Failed to ensure user exists:", err)
-		return
+		return pgError("ensure user", err)
 	}
 
-	switch a := asset.(type) {
-	case *models.Chart:
-		tx, err := p.pool.Begin(ctx)
-		if err != nil {
-			log.Println("Failed to start transaction:", err)
-			return
-		}
-		defer tx.Rollback(ctx)
+	mapper, err := postgresMapperFor(asset.GetType())
+	if err != nil {
+		return err
+	}
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO assets (asset_id, title, description, asset_type, user_id) VALUES ($1, $2, $3, $4, $5)",
-			a.ID, a.Title, a.Description, "chart", userID,
-		)
-		if err != nil {
-			log.Println("Failed to insert into assets (chart):", err)
-			return
-		}
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start transaction:", err)
+		return pgError("begin add", err)
+	}
+	defer tx.Rollback(ctx)
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO charts (id, title, description, x_axis_title, y_axis_title) VALUES ($1,$2,$3,$4,$5)",
-			a.ID, a.Title, a.Description, a.XAxisTitle, a.YAxisTitle,
-		)
-		if err != nil {
-			log.Println("Failed to insert chart:", err)
-			return
-		}
+	_, err = tx.Exec(ctx,
+		"INSERT INTO assets (asset_id, title, description, asset_type, user_id) VALUES ($1, $2, $3, $4, $5)",
+		asset.GetID(), asset.GetTitle(), asset.GetDescription(), asset.GetType(), userID,
+	)
+	if err != nil {
+		log.Println("Failed to insert into assets:", err)
+		return pgError("insert asset", err)
+	}
 
-		for _, d := range a.Data {
-			_, err = tx.Exec(ctx,
-				"INSERT INTO chart_data (chart_id, datapoint_code, value) VALUES ($1,$2,$3)",
-				a.ID, d.DatapointCode, d.Value,
-			)
-			if err != nil {
-				log.Println("Failed to insert chart data:", err)
-				return
-			}
-		}
+	if err := mapper.Insert(ctx, tx, asset); err != nil {
+		log.Printf("Failed to insert %s: %v", asset.GetType(), err)
+		return err
+	}
+	if err := refreshSearchVector(ctx, tx, asset); err != nil {
+		return err
+	}
 
-		if err = tx.Commit(ctx); err != nil {
-			log.Println("Failed to commit chart transaction:", err)
-		}
+	if err = tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit add transaction:", err)
+		return pgError("commit add", err)
+	}
+	asset.SetVersion(1)
+	return nil
+}
 
-	case *models.Insight:
-		tx, err := p.pool.Begin(ctx)
-		if err != nil {
-			log.Println("Failed to start transaction:", err)
-			return
+func (p *PostgresStore) Get(ctx context.Context, userID uuid.UUID, opts ListOptions) (AssetPage, error) {
+	if err := opts.validateAssetSort(); err != nil {
+		return AssetPage{}, err
+	}
+	where, args := "a.user_id=$1", []interface{}{userID}
+	if opts.Collection != uuid.Nil {
+		if err := ownsCollection(ctx, p.pool, userID, opts.Collection); err != nil {
+			return AssetPage{}, err
 		}
-		defer tx.Rollback(ctx)
-
-		_, err = tx.Exec(ctx,
-			"INSERT INTO assets (asset_id, title, description, asset_type, user_id) VALUES ($1, $2, $3, $4, $5)",
-			a.ID, "Insight", a.Description, "insight", userID,
-		)
-		if err != nil {
-			log.Println("Failed to insert into assets:", err)
-			return
+		args = append(args, opts.Collection)
+		where += fmt.Sprintf(" AND a.asset_id IN (SELECT asset_id FROM collection_assets WHERE collection_id=$%d)", len(args))
+	}
+	if len(opts.Tags) > 0 {
+		args = append(args, opts.Tags)
+		if opts.AnyTag {
+			where += fmt.Sprintf(" AND a.asset_id IN (SELECT asset_id FROM asset_tags WHERE tag = ANY($%d))", len(args))
+		} else {
+			// Every distinct tag asked for must be on the asset
+			where += fmt.Sprintf(` AND a.asset_id IN (
+				SELECT asset_id FROM asset_tags WHERE tag = ANY($%[1]d)
+				GROUP BY asset_id
+				HAVING count(*) = (SELECT count(DISTINCT t) FROM unnest($%[1]d::text[]) AS t))`, len(args))
 		}
+	}
+	filter, args, err := filterSQL(opts, args)
+	if err != nil {
+		return AssetPage{}, err
+	}
+	where += filter
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO insights (id, description) VALUES ($1,$2)",
-			a.ID, a.Description,
-		)
-		if err != nil {
-			log.Println("Failed to insert insight:", err)
-			return
-		}
+	refs, next, err := p.listPage(ctx, "assets a", where, args, opts)
+	if err != nil {
+		log.Println("Failed to get assets:", err)
+		return AssetPage{}, err
+	}
 
-		if err = tx.Commit(ctx); err != nil {
-			log.Println("Failed to commit insight transaction:", err)
-		}
+	assets, err := p.loadAssets(ctx, refs)
+	if err != nil {
+		return AssetPage{}, err
+	}
+	return AssetPage{Items: assets, NextCursor: next}, nil
+}
 
-	case *models.Audience:
-		tx, err := p.pool.Begin(ctx)
-		if err != nil {
-			log.Println("Failed to start transaction:", err)
-			return
-		}
-		defer tx.Rollback(ctx)
+// sortColumns maps sort fields onto the assets columns they order by.
+// SortByPosition only applies to listings joining favourites as f.
+var sortColumns = map[SortField]string{
+	SortByID:       "a.asset_id",
+	SortByTitle:    "COALESCE(a.title, '')",
+	SortByType:     "a.asset_type",
+	SortByCreated:  "a.created_at",
+	SortByPosition: positionKeySQL,
+}
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO assets (asset_id, title, description, asset_type, user_id) VALUES ($1, $2, $3, $4, $5)",
-			a.ID, "Audience", a.Description, "audience", userID,
-		)
-		if err != nil {
-			log.Println("Failed to insert into assets:", err)
-			return
-		}
+// assetRef identifies one row of a listing page before its asset is loaded.
+type assetRef struct {
+	id        string
+	assetType string
+	title     string
+	createdAt time.Time
+	version   int64
+	position  string // positionKey, when sorting by position
+}
 
-		_, err = tx.Exec(ctx,
-			"INSERT INTO audiences (id, gender, country, age_group, social_hours, purchases, description) VALUES ($1,$2,$3,$4,$5,$6,$7)",
-			a.ID, a.Gender, a.Country, a.AgeGroup, a.SocialHours, a.Purchases, a.Description,
-		)
-		if err != nil {
-			log.Println("Failed to insert audience:", err)
-			return
-		}
+// listPage runs a keyset-paginated listing over the assets table (aliased
+// "a") joined as described by from, filtered by where with its placeholders
+// bound to args. It fetches one extra row to know whether another page exists.
+func (p *PostgresStore) listPage(ctx context.Context, from, where string, args []interface{}, opts ListOptions) ([]assetRef, string, error) {
+	after, err := opts.decodeCursor()
+	if err != nil {
+		return nil, "", err
+	}
+
+	col := sortColumns[opts.sortField()]
+	cmp, dir := ">", "ASC"
+	if opts.Desc {
+		cmp, dir = "<", "DESC"
+	}
 
-		if err = tx.Commit(ctx); err != nil {
-			log.Println("Failed to commit audience transaction:", err)
+	if after != nil {
+		var key interface{} = after.Key
+		if opts.sortField() == SortByCreated {
+			t, err := time.Parse(cursorTimeFormat, after.Key)
+			if err != nil {
+				return nil, "", fmt.Errorf("malformed cursor: %w", ErrValidation)
+			}
+			key = t
 		}
+		args = append(args, key, after.ID)
+		where += fmt.Sprintf(" AND (%s, a.asset_id) %s ($%d, $%d)", col, cmp, len(args)-1, len(args))
 	}
-}
+	args = append(args, opts.limit()+1)
+
+	positionCol := "''"
+	if opts.sortField() == SortByPosition {
+		positionCol = col
+	}
+	query := fmt.Sprintf(`
+		SELECT a.asset_id, a.asset_type, COALESCE(a.title, ''), a.created_at, a.version, %s
+		FROM %s WHERE %s
+		ORDER BY %s %s, a.asset_id %s
+		LIMIT $%d`, positionCol, from, where, col, dir, dir, len(args))
 
-func (p *PostgresStore) Get(userID uuid.UUID) []models.Asset {
-	ctx := context.Background()
-	rows, err := p.pool.Query(ctx, "SELECT asset_id, asset_type FROM assets WHERE user_id=$1", userID)
+	rows, err := p.pool.Query(ctx, query, args...)
 	if err != nil {
-		log.Println("Failed to get assets:", err)
-		return nil
+		return nil, "", pgError("list assets", err)
 	}
 	defer rows.Close()
 
-	var assets []models.Asset
+	var refs []assetRef
 	for rows.Next() {
-		var assetID, assetType string
-		if err := rows.Scan(&assetID, &assetType); err != nil {
+		var ref assetRef
+		if err := rows.Scan(&ref.id, &ref.assetType, &ref.title, &ref.createdAt, &ref.version, &ref.position); err != nil {
 			log.Println("Failed to scan asset row:", err)
-			continue
+			return nil, "", pgError("scan asset", err)
 		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, "", pgError("list assets", err)
+	}
 
-		var asset models.Asset
-		switch assetType {
-		case "chart":
-			var c models.Chart
-			err := p.pool.QueryRow(ctx, `
-				SELECT id, title, description, x_axis_title, y_axis_title 
-				FROM charts WHERE id=$1`, assetID).Scan(&c.ID, &c.Title, &c.Description, &c.XAxisTitle, &c.YAxisTitle)
-			if err != nil {
-				log.Println("Failed to fetch chart:", err)
-				continue
-			}
+	next := ""
+	if len(refs) > opts.limit() {
+		refs = refs[:opts.limit()]
+		last := refs[len(refs)-1]
+		next = opts.encodeCursor(refKey(opts.sortField(), last), last.id)
+	}
+	return refs, next, nil
+}
 
-			// Fetch chart data
-			dataRows, err := p.pool.Query(ctx, `SELECT datapoint_code, value FROM chart_data WHERE chart_id=$1`, assetID)
-			if err != nil {
-				log.Println("Failed to fetch chart data:", err)
-			} else {
-				defer dataRows.Close()
-				for dataRows.Next() {
-					var dp models.ChartData
-					if err := dataRows.Scan(&dp.DatapointCode, &dp.Value); err != nil {
-						log.Println("Failed to scan chart data row:", err)
-						continue
-					}
-					c.Data = append(c.Data, dp)
-				}
-			}
-			asset = &c
+// refKey returns the cursor key of a listing row for the given sort field.
+func refKey(field SortField, ref assetRef) string {
+	switch field {
+	case SortByTitle:
+		return ref.title
+	case SortByType:
+		return ref.assetType
+	case SortByCreated:
+		return ref.createdAt.UTC().Format(cursorTimeFormat)
+	case SortByPosition:
+		return ref.position
+	}
+	return ref.id
+}
 
-		case "insight":
-			var i models.Insight
-			err := p.pool.QueryRow(ctx, `SELECT id, description FROM insights WHERE id=$1`, assetID).
-				Scan(&i.ID, &i.Description)
-			if err != nil {
-				log.Println("Failed to fetch insight:", err)
-				continue
-			}
-			asset = &i
+// loadAssets fetches the full assets behind a page of refs. Each type's mapper
+// queues its = ANY($1) queries into a single batch, so the cost is one
+// round-trip regardless of how many assets the page holds. Refs whose type-specific row
+// is missing are left out of the result.
+func (p *PostgresStore) loadAssets(ctx context.Context, refs []assetRef) ([]models.Asset, error) {
+	idsByType := make(map[string][]string)
+	for _, ref := range refs {
+		idsByType[ref.assetType] = append(idsByType[ref.assetType], ref.id)
+	}
 
-		case "audience":
-			var a models.Audience
-			err := p.pool.QueryRow(ctx, `
-				SELECT id, gender, country, age_group, social_hours, purchases, description 
-				FROM audiences WHERE id=$1`, assetID).Scan(&a.ID, &a.Gender, &a.Country, &a.AgeGroup, &a.SocialHours, &a.Purchases, &a.Description)
-			if err != nil {
-				log.Println("Failed to fetch audience:", err)
-				continue
-			}
-			asset = &a
+	loaded := make(map[string]models.Asset, len(refs))
+	batch := &pgx.Batch{}
+	for assetType, ids := range idsByType {
+		mapper, err := postgresMapperFor(assetType)
+		if err != nil {
+			log.Printf("Skipping %d assets: %v", len(ids), err)
+			continue
+		}
+		mapper.QueueLoad(batch, ids, loaded)
+	}
+
+	if batch.Len() > 0 {
+		if err := p.pool.SendBatch(ctx, batch).Close(); err != nil {
+			log.Println("Failed to load assets:", err)
+			return nil, pgError("load assets", err)
+		}
+	}
+
+	assets := make([]models.Asset, 0, len(refs))
+	for _, ref := range refs {
+		asset, ok := loaded[ref.id]
+		if !ok {
+			log.Printf("Skipping asset %s with missing %s row", ref.id, ref.assetType)
+			continue
 		}
+		asset.SetVersion(ref.version)
 		assets = append(assets, asset)
 	}
-	return assets
+	return assets, nil
 }
 
-func (p *PostgresStore) Remove(userID uuid.UUID, assetID string) bool {
-	ctx := context.Background()
+func (p *PostgresStore) GetByID(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	var ref assetRef
+	err := p.pool.QueryRow(ctx,
+		"SELECT asset_id, asset_type, version FROM assets WHERE asset_id=$1 AND user_id=$2",
+		assetID, userID,
+	).Scan(&ref.id, &ref.assetType, &ref.version)
+	if err != nil {
+		return nil, pgError("find asset "+assetID, err)
+	}
+
+	assets, err := p.loadAssets(ctx, []assetRef{ref})
+	if err != nil {
+		return nil, err
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("asset %s has no %s row: %w", assetID, ref.assetType, ErrNotFound)
+	}
+	return assets[0], nil
+}
+
+func (p *PostgresStore) Remove(ctx context.Context, userID uuid.UUID, assetID string, version int64) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
 		log.Println("Failed to start remove transaction:", err)
-		return false
+		return pgError("begin remove", err)
 	}
 	defer tx.Rollback(ctx)
 
-	statements := []struct {
-		query string
-		args  []interface{}
-	}{
-		{"DELETE FROM chart_data WHERE chart_id=$1", []interface{}{assetID}},
-		{"DELETE FROM charts WHERE id=$1", []interface{}{assetID}},
-		{"DELETE FROM insights WHERE id=$1", []interface{}{assetID}},
-		{"DELETE FROM audiences WHERE id=$1", []interface{}{assetID}},
-		{"DELETE FROM assets WHERE asset_id=$1", []interface{}{assetID}},
-		{"DELETE FROM favourites WHERE asset_id=$1 AND user_id=$2", []interface{}{assetID, userID}},
+	// Only the owner may remove an asset
+	var current int64
+	err = tx.QueryRow(ctx,
+		"SELECT version FROM assets WHERE asset_id=$1 AND user_id=$2 FOR UPDATE",
+		assetID, userID,
+	).Scan(&current)
+	if err != nil {
+		log.Println("Failed to find asset for user:", err)
+		return pgError("find asset "+assetID, err)
+	}
+	if version != AnyVersion && current != version {
+		return fmt.Errorf("asset %s is at version %d, not %d: %w", assetID, current, version, ErrPreconditionFailed)
+	}
+
+	// Type-specific rows and chart data go with the assets row (ON DELETE CASCADE)
+	statements := []string{
+		"DELETE FROM favourites WHERE asset_id=$1",
+		"DELETE FROM assets WHERE asset_id=$1",
 	}
 
 	for _, stmt := range statements {
-		if _, err := tx.Exec(ctx, stmt.query, stmt.args...); err != nil {
+		if _, err := tx.Exec(ctx, stmt, assetID); err != nil {
 			log.Println("Failed to execute remove statement:", err)
-			return false
+			return pgError("remove asset", err)
 		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
 		log.Println("Failed to commit remove transaction:", err)
-		return false
+		return pgError("commit remove", err)
 	}
 
-	return true
+	return nil
 }
 
-func (p *PostgresStore) EditDescription(userID uuid.UUID, assetID, newDesc string) bool {
-	ctx := context.Background()
+// Update replaces a stored asset with a new version of the same type. The
+// assets row and the type-specific rows are written in one transaction.
+func (p *PostgresStore) Update(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
 	tx, err := p.pool.Begin(ctx)
 	if err != nil {
-		log.Println("Failed to start edit transaction:", err)
-		return false
+		log.Println("Failed to start update transaction:", err)
+		return pgError("begin update", err)
 	}
 	defer tx.Rollback(ctx)
 
-	// First, get the asset type
 	var assetType string
-	err = tx.QueryRow(ctx, "SELECT asset_type FROM assets WHERE asset_id=$1 AND user_id=$2", assetID, userID).Scan(&assetType)
+	var current int64
+	err = tx.QueryRow(ctx,
+		"SELECT asset_type, version FROM assets WHERE asset_id=$1 AND user_id=$2 FOR UPDATE",
+		asset.GetID(), userID,
+	).Scan(&assetType, &current)
 	if err != nil {
 		log.Println("Failed to find asset for user:", err)
-		return false
+		return pgError("find asset "+asset.GetID(), err)
 	}
-
-	// Update the specific asset table
-	var stmt string
-	switch assetType {
-	case "chart":
-		stmt = "UPDATE charts SET description=$1 WHERE id=$2"
-	case "insight":
-		stmt = "UPDATE insights SET description=$1 WHERE id=$2"
-	case "audience":
-		stmt = "UPDATE audiences SET description=$1 WHERE id=$2"
-	default:
-		log.Println("Unknown asset type:", assetType)
-		return false
+	if assetType != asset.GetType() {
+		return fmt.Errorf("asset %s is a %s, not a %s: %w", asset.GetID(), assetType, asset.GetType(), ErrValidation)
+	}
+	if v := asset.GetVersion(); v != AnyVersion && current != v {
+		return fmt.Errorf("asset %s is at version %d, not %d: %w", asset.GetID(), current, v, ErrPreconditionFailed)
 	}
 
-	if _, err := tx.Exec(ctx, stmt, newDesc, assetID); err != nil {
-		log.Println("Failed to update description in specific table:", err)
-		return false
+	mapper, err := postgresMapperFor(assetType)
+	if err != nil {
+		return err
 	}
 
-	// Also update the main assets table
-	if _, err := tx.Exec(ctx, "UPDATE assets SET description=$1 WHERE asset_id=$2", newDesc, assetID); err != nil {
-		log.Println("Failed to update description in assets table:", err)
-		return false
+	_, err = tx.Exec(ctx,
+		"UPDATE assets SET title=$1, description=$2, version=$3 WHERE asset_id=$4",
+		asset.GetTitle(), asset.GetDescription(), current+1, asset.GetID(),
+	)
+	if err != nil {
+		log.Println("Failed to update assets row:", err)
+		return pgError("update asset", err)
+	}
+	if err := mapper.Update(ctx, tx, asset); err != nil {
+		log.Printf("Failed to update %s: %v", assetType, err)
+		return err
+	}
+	if err := refreshSearchVector(ctx, tx, asset); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
-		log.Println("Failed to commit edit transaction:", err)
-		return false
+		log.Println("Failed to commit update transaction:", err)
+		return pgError("commit update", err)
 	}
-
-	return true
+	asset.SetVersion(current + 1)
+	return nil
 }
 
 // ----------------- Favourite Methods -----------------
 
-func (p *PostgresStore) AddFavourite(userID uuid.UUID, assetID, _ string) bool {
-	ctx := context.Background()
-
+func (p *PostgresStore) AddFavourite(ctx context.Context, userID uuid.UUID, assetID, _ string) error {
 	// Ensure user exists
 	_, err := p.pool.Exec(ctx,
 		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
@@ -306,136 +387,953 @@ func (p *PostgresStore) AddFavourite(userID uuid.UUID, assetID, _ string) bool {
 	)
 	if err != nil {
 		log.Println("Failed to ensure user exists:", err)
-		return false
+		return pgError("ensure user", err)
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start add favourite transaction:", err)
+		return pgError("begin add favourite", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the user so concurrent adds get distinct positions
+	var one int
+	if err := tx.QueryRow(ctx, "SELECT 1 FROM users WHERE id=$1 FOR UPDATE", userID).Scan(&one); err != nil {
+		return pgError(fmt.Sprintf("find user %v", userID), err)
 	}
 
 	// Fetch the asset type from assets table
 	var assetType string
-	err = p.pool.QueryRow(ctx, "SELECT asset_type FROM assets WHERE asset_id=$1", assetID).Scan(&assetType)
+	err = tx.QueryRow(ctx, "SELECT asset_type FROM assets WHERE asset_id=$1", assetID).Scan(&assetType)
 	if err != nil {
 		log.Println("Failed to fetch asset type:", err)
-		return false
+		return pgError("find asset "+assetID, err)
 	}
 
-	_, err = p.pool.Exec(ctx,
-		"INSERT INTO favourites (user_id, asset_id, asset_type) VALUES ($1, $2, $3) ON CONFLICT (user_id, asset_id) DO NOTHING",
+	// New favourites go to the end of the user's order.
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO favourites (user_id, asset_id, asset_type, position)
+		SELECT $1, $2, $3, COALESCE(MAX(position), 0) + 1 FROM favourites WHERE user_id=$1
+		ON CONFLICT (user_id, asset_id) DO NOTHING`,
 		userID, assetID, assetType,
 	)
 	if err != nil {
 		log.Println("Failed to add favourite:", err)
-		return false
+		return pgInsertError("add favourite", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("favourite %s: %w", assetID, ErrConflict)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit add favourite transaction:", err)
+		return pgError("commit add favourite", err)
 	}
 
 	log.Printf("Favourite added: user=%v, asset=%s, type=%s", userID, assetID, assetType)
-	return true
+	return nil
 }
 
-func (p *PostgresStore) RemoveFavourite(userID uuid.UUID, assetID string) bool {
-	_, err := p.pool.Exec(context.Background(),
+func (p *PostgresStore) RemoveFavourite(ctx context.Context, userID uuid.UUID, assetID string) error {
+	tag, err := p.pool.Exec(ctx,
 		"DELETE FROM favourites WHERE user_id=$1 AND asset_id=$2",
 		userID, assetID,
 	)
 	if err != nil {
 		log.Println("Failed to remove favourite:", err)
-		return false
+		return pgError("remove favourite", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("favourite %s: %w", assetID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) UpdateFavourite(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE favourites SET pinned = COALESCE($3, pinned), note = COALESCE($4, note)
+		WHERE user_id=$1 AND asset_id=$2`,
+		userID, assetID, update.Pinned, update.Note,
+	)
+	if err != nil {
+		log.Println("Failed to update favourite:", err)
+		return pgError("update favourite", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("favourite %s: %w", assetID, ErrNotFound)
 	}
-	return true
+	return nil
 }
 
-func (p *PostgresStore) GetFavourites(userID uuid.UUID) []models.Favourite {
-	ctx := context.Background()
+func (p *PostgresStore) MoveFavourite(ctx context.Context, userID uuid.UUID, assetID, targetID string, after bool) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start move transaction:", err)
+		return pgError("begin move favourite", err)
+	}
+	defer tx.Rollback(ctx)
 
-	rows, err := p.pool.Query(ctx,
-		"SELECT asset_id, asset_type FROM favourites WHERE user_id=$1", userID)
+	rows, err := tx.Query(ctx,
+		"SELECT asset_id FROM favourites WHERE user_id=$1 ORDER BY position, asset_id FOR UPDATE",
+		userID,
+	)
+	if err != nil {
+		return pgError("list favourites", err)
+	}
+	var order []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return pgError("scan favourite", err)
+		}
+		order = append(order, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return pgError("list favourites", err)
+	}
+
+	moved, err := moveID(order, assetID, targetID, after)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE favourites f SET position = o.n
+		FROM unnest($2::text[]) WITH ORDINALITY AS o(asset_id, n)
+		WHERE f.user_id=$1 AND f.asset_id = o.asset_id`,
+		userID, moved,
+	)
+	if err != nil {
+		log.Println("Failed to reorder favourites:", err)
+		return pgError("reorder favourites", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit move transaction:", err)
+		return pgError("commit move favourite", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetFavourites(ctx context.Context, userID uuid.UUID, opts ListOptions) (FavouritePage, error) {
+	opts = opts.favouriteOrder()
+	refs, next, err := p.listPage(ctx,
+		"favourites f JOIN assets a ON a.asset_id = f.asset_id", "f.user_id=$1", []interface{}{userID}, opts)
 	if err != nil {
 		log.Println("Failed to get favourites:", err)
-		return nil
+		return FavouritePage{}, err
 	}
-	defer rows.Close()
 
 	log.Printf("Query executed for user %v", userID)
 
-	var favs []models.Favourite
+	assets, err := p.loadAssets(ctx, refs)
+	if err != nil {
+		return FavouritePage{}, err
+	}
+
+	ids := make([]string, len(assets))
+	for i, asset := range assets {
+		ids[i] = asset.GetID()
+	}
+	rows, err := p.pool.Query(ctx, `
+		SELECT asset_id, created_at, position, pinned, note
+		FROM favourites WHERE user_id=$1 AND asset_id = ANY($2)`,
+		userID, ids,
+	)
+	if err != nil {
+		return FavouritePage{}, pgError("get favourites", err)
+	}
+	defer rows.Close()
+	byID := make(map[string]models.Favourite, len(ids))
 	for rows.Next() {
-		var assetID, assetType string
-		if err := rows.Scan(&assetID, &assetType); err != nil {
-			log.Println("Failed to scan favourite row:", err)
-			continue
+		var id string
+		fav := models.Favourite{UserID: userID}
+		if err := rows.Scan(&id, &fav.CreatedAt, &fav.Position, &fav.Pinned, &fav.Note); err != nil {
+			return FavouritePage{}, pgError("scan favourite", err)
 		}
-		log.Printf("Processing favourite: assetID=%s, assetType=%s", assetID, assetType)
+		byID[id] = fav
+	}
+	if err := rows.Err(); err != nil {
+		return FavouritePage{}, pgError("get favourites", err)
+	}
 
-		var asset models.Asset
+	favs := make([]models.Favourite, 0, len(assets))
+	for _, asset := range assets {
+		fav := byID[asset.GetID()]
+		fav.UserID, fav.Asset = userID, asset
+		favs = append(favs, fav)
+	}
 
-		switch assetType {
-		case "chart":
-			var c models.Chart
-			err := p.pool.QueryRow(ctx, `
-				SELECT id, title, description, x_axis_title, y_axis_title
-				FROM charts WHERE id=$1`, assetID).Scan(&c.ID, &c.Title, &c.Description, &c.XAxisTitle, &c.YAxisTitle)
-			if err != nil {
-				log.Println("Failed to fetch chart:", err)
-				continue
-			}
+	log.Printf("GetFavourites finished for user %v, total favourites: %d", userID, len(favs))
+	return FavouritePage{Items: favs, NextCursor: next}, nil
+}
 
-			// Fetch chart data
-			dataRows, err := p.pool.Query(ctx, `SELECT datapoint_code, value FROM chart_data WHERE chart_id=$1`, assetID)
-			if err != nil {
-				log.Println("Failed to fetch chart data:", err)
-			} else {
-				defer dataRows.Close()
-				for dataRows.Next() {
-					var dp models.ChartData
-					if err := dataRows.Scan(&dp.DatapointCode, &dp.Value); err != nil {
-						log.Println("Failed to scan chart data row:", err)
-						continue
-					}
-					c.Data = append(c.Data, dp)
-				}
-				log.Printf("Fetched %d data points for chart %s", len(c.Data), c.ID)
-			}
+// ----------------- Share Methods -----------------
 
-			asset = &c
-			log.Printf("Chart fetched successfully: %s", c.Title)
+func (p *PostgresStore) Access(ctx context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error) {
+	var ownerID uuid.UUID
+	var granted string
+	err := p.pool.QueryRow(ctx, `
+		SELECT a.user_id, COALESCE(s.permission, '')
+		FROM assets a
+		LEFT JOIN asset_shares s ON s.asset_id = a.asset_id AND s.user_id = $2
+		WHERE a.asset_id = $1`, assetID, userID,
+	).Scan(&ownerID, &granted)
+	if err != nil {
+		return uuid.Nil, "", pgError("find asset "+assetID, err)
+	}
 
-		case "insight":
-			var i models.Insight
-			err := p.pool.QueryRow(ctx, `SELECT id, description FROM insights WHERE id=$1`, assetID).
-				Scan(&i.ID, &i.Description)
-			if err != nil {
-				log.Println("Failed to fetch insight:", err)
-				continue
-			}
-			asset = &i
-			log.Printf("Insight fetched successfully: %s", i.ID)
-
-		case "audience":
-			var a models.Audience
-			err := p.pool.QueryRow(ctx, `
-				SELECT id, gender, country, age_group, social_hours, purchases, description
-				FROM audiences WHERE id=$1`, assetID).Scan(&a.ID, &a.Gender, &a.Country, &a.AgeGroup, &a.SocialHours, &a.Purchases, &a.Description)
-			if err != nil {
-				log.Println("Failed to fetch audience:", err)
-				continue
-			}
-			asset = &a
-			log.Printf("Audience fetched successfully: %s", a.ID)
+	switch {
+	case ownerID == userID:
+		return ownerID, models.PermissionOwner, nil
+	case granted != "":
+		return ownerID, models.Permission(granted), nil
+	}
+	return uuid.Nil, "", fmt.Errorf("asset %s: %w", assetID, ErrNotFound)
+}
 
-		default:
-			log.Printf("Unknown asset type: %s", assetType)
-			continue
+// rowQuerier is satisfied by both the pool and a transaction.
+type rowQuerier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ownsAsset fails with ErrNotFound unless ownerID owns assetID.
+func ownsAsset(ctx context.Context, q rowQuerier, ownerID uuid.UUID, assetID string) error {
+	var one int
+	err := q.QueryRow(ctx, "SELECT 1 FROM assets WHERE asset_id=$1 AND user_id=$2", assetID, ownerID).Scan(&one)
+	return pgError("find asset "+assetID, err)
+}
+
+func (p *PostgresStore) GetShares(ctx context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error) {
+	if err := ownsAsset(ctx, p.pool, ownerID, assetID); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.pool.Query(ctx,
+		"SELECT user_id, permission FROM asset_shares WHERE asset_id=$1 ORDER BY user_id",
+		assetID,
+	)
+	if err != nil {
+		return nil, pgError("list shares", err)
+	}
+	defer rows.Close()
+
+	shares := []models.Share{}
+	for rows.Next() {
+		share := models.Share{AssetID: assetID, OwnerID: ownerID}
+		if err := rows.Scan(&share.UserID, &share.Permission); err != nil {
+			return nil, pgError("scan share", err)
 		}
+		shares = append(shares, share)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("list shares", err)
+	}
+	return shares, nil
+}
 
-		favs = append(favs, models.Favourite{
-			UserID: userID,
-			Asset:  asset,
-		})
-		log.Printf("Favourite appended for user %v: assetID=%s", userID, assetID)
+func (p *PostgresStore) Share(ctx context.Context, share models.Share) error {
+	// Ensure the grantee exists
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+		share.UserID, "Unknown",
+	)
+	if err != nil {
+		log.Println("Failed to ensure user exists:", err)
+		return pgError("ensure user", err)
 	}
 
+	tag, err := p.pool.Exec(ctx, `
+		INSERT INTO asset_shares (asset_id, user_id, permission)
+		SELECT asset_id, $2, $3 FROM assets WHERE asset_id=$1 AND user_id=$4
+		ON CONFLICT (asset_id, user_id) DO UPDATE SET permission = EXCLUDED.permission`,
+		share.AssetID, share.UserID, string(share.Permission), share.OwnerID,
+	)
+	if err != nil {
+		log.Println("Failed to share asset:", err)
+		return pgInsertError("share asset", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("asset %s: %w", share.AssetID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) Unshare(ctx context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start unshare transaction:", err)
+		return pgError("begin unshare", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := ownsAsset(ctx, tx, ownerID, assetID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, "DELETE FROM asset_shares WHERE asset_id=$1 AND user_id=$2", assetID, userID)
+	if err != nil {
+		return pgError("unshare asset", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("share of %s with %v: %w", assetID, userID, ErrNotFound)
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM favourites WHERE asset_id=$1 AND user_id=$2", assetID, userID); err != nil {
+		return pgError("remove favourite", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit unshare transaction:", err)
+		return pgError("commit unshare", err)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetSharedWithMe(ctx context.Context, userID uuid.UUID, opts ListOptions) (SharedPage, error) {
+	if err := opts.validateAssetSort(); err != nil {
+		return SharedPage{}, err
+	}
+	refs, next, err := p.listPage(ctx,
+		"asset_shares s JOIN assets a ON a.asset_id = s.asset_id", "s.user_id=$1", []interface{}{userID}, opts)
+	if err != nil {
+		log.Println("Failed to list shared assets:", err)
+		return SharedPage{}, err
+	}
+	assets, err := p.loadAssets(ctx, refs)
+	if err != nil {
+		return SharedPage{}, err
+	}
+
+	ids := make([]string, len(assets))
+	for i, asset := range assets {
+		ids[i] = asset.GetID()
+	}
+	rows, err := p.pool.Query(ctx, `
+		SELECT s.asset_id, a.user_id, s.permission
+		FROM asset_shares s JOIN assets a ON a.asset_id = s.asset_id
+		WHERE s.user_id=$1 AND s.asset_id = ANY($2)`, userID, ids)
+	if err != nil {
+		return SharedPage{}, pgError("load shares", err)
+	}
+	defer rows.Close()
+
+	grants := make(map[string]models.SharedAsset, len(ids))
+	for rows.Next() {
+		var assetID string
+		var shared models.SharedAsset
+		if err := rows.Scan(&assetID, &shared.OwnerID, &shared.Permission); err != nil {
+			return SharedPage{}, pgError("scan share", err)
+		}
+		grants[assetID] = shared
+	}
 	if err := rows.Err(); err != nil {
-		log.Println("Row iteration error:", err)
+		return SharedPage{}, pgError("load shares", err)
 	}
 
-	log.Printf("GetFavourites finished for user %v, total favourites: %d", userID, len(favs))
-	return favs
+	items := make([]models.SharedAsset, 0, len(assets))
+	for _, asset := range assets {
+		shared, ok := grants[asset.GetID()]
+		if !ok {
+			continue // revoked since the page was listed
+		}
+		shared.Asset = asset
+		items = append(items, shared)
+	}
+	return SharedPage{Items: items, NextCursor: next}, nil
+}
+
+// ----------------- API Key Methods -----------------
+
+func (p *PostgresStore) AddAPIKey(ctx context.Context, key models.APIKey, hash []byte) error {
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO api_keys (id, name, prefix, key_hash, scopes, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		key.ID, key.Name, key.Prefix, hash, key.Scopes, key.CreatedAt, key.ExpiresAt,
+	)
+	if err != nil {
+		log.Println("Failed to insert api key:", err)
+		return pgError("insert api key", err)
+	}
+	return nil
+}
+
+const apiKeyColumns = "id, name, prefix, scopes, created_at, expires_at, revoked_at"
+
+func (p *PostgresStore) GetAPIKey(ctx context.Context, prefix string) (models.APIKey, []byte, error) {
+	var key models.APIKey
+	var hash []byte
+	err := p.pool.QueryRow(ctx,
+		"SELECT "+apiKeyColumns+", key_hash FROM api_keys WHERE prefix=$1", prefix,
+	).Scan(&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt, &hash)
+	if err != nil {
+		return models.APIKey{}, nil, pgError("find api key "+prefix, err)
+	}
+	return key, hash, nil
+}
+
+func (p *PostgresStore) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := p.pool.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at, id")
+	if err != nil {
+		return nil, pgError("list api keys", err)
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Scopes, &key.CreatedAt, &key.ExpiresAt, &key.RevokedAt); err != nil {
+			return nil, pgError("scan api key", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("list api keys", err)
+	}
+	return keys, nil
+}
+
+func (p *PostgresStore) RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	tag, err := p.pool.Exec(ctx,
+		"UPDATE api_keys SET revoked_at=COALESCE(revoked_at, $2) WHERE id=$1",
+		id, at,
+	)
+	if err != nil {
+		log.Println("Failed to revoke api key:", err)
+		return pgError("revoke api key", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("api key %v: %w", id, ErrNotFound)
+	}
+	return nil
+}
+
+// ----------------- User Methods -----------------
+
+func (p *PostgresStore) ListUsers(ctx context.Context, opts ListOptions) (UserPage, error) {
+	if err := opts.validateUserSort(); err != nil {
+		return UserPage{}, err
+	}
+	after, err := opts.decodeCursor()
+	if err != nil {
+		return UserPage{}, err
+	}
+
+	cmp, dir := ">", "ASC"
+	if opts.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	where, args := "TRUE", []interface{}{}
+	if after != nil {
+		afterID, err := uuid.Parse(after.ID)
+		if err != nil {
+			return UserPage{}, fmt.Errorf("malformed cursor: %w", ErrValidation)
+		}
+		where, args = "id "+cmp+" $1", append(args, afterID)
+	}
+	args = append(args, opts.limit()+1)
+
+	rows, err := p.pool.Query(ctx, fmt.Sprintf(
+		"SELECT id, COALESCE(name, '') FROM users WHERE %s ORDER BY id %s LIMIT $%d", where, dir, len(args)),
+		args...,
+	)
+	if err != nil {
+		return UserPage{}, pgError("list users", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.ID, &user.Name); err != nil {
+			return UserPage{}, pgError("scan user", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return UserPage{}, pgError("list users", err)
+	}
+
+	next := ""
+	if len(users) > opts.limit() {
+		users = users[:opts.limit()]
+		last := users[len(users)-1].ID.String()
+		next = opts.encodeCursor(last, last)
+	}
+	return UserPage{Items: users, NextCursor: next}, nil
+}
+
+func (p *PostgresStore) Reassign(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error) {
+	// Ensure the new owner exists
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+		newOwnerID, "Unknown",
+	)
+	if err != nil {
+		log.Println("Failed to ensure user exists:", err)
+		return uuid.Nil, pgError("ensure user", err)
+	}
+
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start reassign transaction:", err)
+		return uuid.Nil, pgError("begin reassign", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID uuid.UUID
+	err = tx.QueryRow(ctx, "SELECT user_id FROM assets WHERE asset_id=$1 FOR UPDATE", assetID).Scan(&ownerID)
+	if err != nil {
+		return uuid.Nil, pgError("find asset "+assetID, err)
+	}
+	if ownerID == newOwnerID {
+		return ownerID, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE assets SET user_id=$2, version=version+1 WHERE asset_id=$1", assetID, newOwnerID,
+	); err != nil {
+		log.Println("Failed to reassign asset:", err)
+		return uuid.Nil, pgError("reassign asset", err)
+	}
+	if _, err := tx.Exec(ctx,
+		"DELETE FROM asset_shares WHERE asset_id=$1 AND user_id=$2", assetID, newOwnerID,
+	); err != nil {
+		return uuid.Nil, pgError("drop share", err)
+	}
+	// Collections only hold their owner's assets
+	if _, err := tx.Exec(ctx, "DELETE FROM collection_assets WHERE asset_id=$1", assetID); err != nil {
+		return uuid.Nil, pgError("drop collection memberships", err)
+	}
+	// The previous owner can no longer see the asset
+	if _, err := tx.Exec(ctx, "DELETE FROM favourites WHERE asset_id=$1 AND user_id=$2", assetID, ownerID); err != nil {
+		return uuid.Nil, pgError("drop favourite", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit reassign transaction:", err)
+		return uuid.Nil, pgError("commit reassign", err)
+	}
+	return ownerID, nil
+}
+
+func (p *PostgresStore) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		log.Println("Failed to start purge transaction:", err)
+		return pgError("begin purge", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Shares on the user's assets and type-specific rows go with the assets
+	// rows (ON DELETE CASCADE)
+	statements := []string{
+		"DELETE FROM favourites WHERE user_id=$1 OR asset_id IN (SELECT asset_id FROM assets WHERE user_id=$1)",
+		"DELETE FROM asset_shares WHERE user_id=$1",
+		"DELETE FROM collections WHERE user_id=$1",
+		"DELETE FROM assets WHERE user_id=$1",
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(ctx, stmt, userID); err != nil {
+			log.Println("Failed to execute purge statement:", err)
+			return pgError("purge user", err)
+		}
+	}
+
+	tag, err := tx.Exec(ctx, "DELETE FROM users WHERE id=$1", userID)
+	if err != nil {
+		return pgError("delete user", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %v: %w", userID, ErrNotFound)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		log.Println("Failed to commit purge transaction:", err)
+		return pgError("commit purge", err)
+	}
+	return nil
+}
+
+// ----------------- Collection Methods -----------------
+
+// ownsCollection fails with ErrNotFound unless userID owns collectionID.
+func ownsCollection(ctx context.Context, q rowQuerier, userID, collectionID uuid.UUID) error {
+	var one int
+	err := q.QueryRow(ctx, "SELECT 1 FROM collections WHERE id=$1 AND user_id=$2", collectionID, userID).Scan(&one)
+	return pgError(fmt.Sprintf("find collection %v", collectionID), err)
+}
+
+// collectionQuery selects a user's collections with their members in order;
+// %s adds conditions on c.
+const collectionQuery = `
+	SELECT c.id, c.name, c.created_at,
+		COALESCE(array_agg(ca.asset_id ORDER BY ca.position) FILTER (WHERE ca.asset_id IS NOT NULL), '{}')
+	FROM collections c LEFT JOIN collection_assets ca ON ca.collection_id = c.id
+	WHERE c.user_id=$1 %s
+	GROUP BY c.id
+	ORDER BY c.name, c.id`
+
+func (p *PostgresStore) GetCollections(ctx context.Context, userID uuid.UUID) ([]models.Collection, error) {
+	rows, err := p.pool.Query(ctx, fmt.Sprintf(collectionQuery, ""), userID)
+	if err != nil {
+		return nil, pgError("list collections", err)
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		var c models.Collection
+		if err := rows.Scan(&c.ID, &c.Name, &c.CreatedAt, &c.AssetIDs); err != nil {
+			return nil, pgError("scan collection", err)
+		}
+		collections = append(collections, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("list collections", err)
+	}
+	return collections, nil
+}
+
+func (p *PostgresStore) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error) {
+	var c models.Collection
+	err := p.pool.QueryRow(ctx, fmt.Sprintf(collectionQuery, "AND c.id=$2"), userID, collectionID).
+		Scan(&c.ID, &c.Name, &c.CreatedAt, &c.AssetIDs)
+	if err != nil {
+		return models.Collection{}, pgError(fmt.Sprintf("find collection %v", collectionID), err)
+	}
+	return c, nil
+}
+
+func (p *PostgresStore) AddCollection(ctx context.Context, userID uuid.UUID, collection *models.Collection) error {
+	// Ensure user exists
+	_, err := p.pool.Exec(ctx,
+		"INSERT INTO users (id, name) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING",
+		userID, "Unknown",
+	)
+	if err != nil {
+		log.Println("Failed to ensure user exists:", err)
+		return pgError("ensure user", err)
+	}
+
+	err = p.pool.QueryRow(ctx,
+		"INSERT INTO collections (id, user_id, name) VALUES ($1, $2, $3) RETURNING created_at",
+		collection.ID, userID, collection.Name,
+	).Scan(&collection.CreatedAt)
+	if err != nil {
+		log.Println("Failed to insert collection:", err)
+		return pgError("insert collection", err)
+	}
+	collection.AssetIDs = []string{}
+	return nil
+}
+
+func (p *PostgresStore) RenameCollection(ctx context.Context, userID, collectionID uuid.UUID, name string) error {
+	tag, err := p.pool.Exec(ctx,
+		"UPDATE collections SET name=$3 WHERE id=$1 AND user_id=$2",
+		collectionID, userID, name,
+	)
+	if err != nil {
+		return pgError("rename collection", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("collection %v: %w", collectionID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) RemoveCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	// Memberships go with the collection (ON DELETE CASCADE)
+	tag, err := p.pool.Exec(ctx, "DELETE FROM collections WHERE id=$1 AND user_id=$2", collectionID, userID)
+	if err != nil {
+		return pgError("remove collection", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("collection %v: %w", collectionID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) GetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error) {
+	if err := ownsCollection(ctx, p.pool, userID, collectionID); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.pool.Query(ctx, `
+		SELECT a.asset_id, a.asset_type, COALESCE(a.title, ''), a.created_at, a.version
+		FROM collection_assets ca JOIN assets a ON a.asset_id = ca.asset_id
+		WHERE ca.collection_id=$1
+		ORDER BY ca.position`, collectionID)
+	if err != nil {
+		return nil, pgError("list collection assets", err)
+	}
+	defer rows.Close()
+
+	var refs []assetRef
+	for rows.Next() {
+		var ref assetRef
+		if err := rows.Scan(&ref.id, &ref.assetType, &ref.title, &ref.createdAt, &ref.version); err != nil {
+			return nil, pgError("scan asset", err)
+		}
+		refs = append(refs, ref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("list collection assets", err)
+	}
+	return p.loadAssets(ctx, refs)
+}
+
+func (p *PostgresStore) AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return pgError("begin add to collection", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the collection so concurrent appends get distinct positions
+	var one int
+	err = tx.QueryRow(ctx,
+		"SELECT 1 FROM collections WHERE id=$1 AND user_id=$2 FOR UPDATE", collectionID, userID,
+	).Scan(&one)
+	if err != nil {
+		return pgError(fmt.Sprintf("find collection %v", collectionID), err)
+	}
+	if err := ownsAsset(ctx, tx, userID, assetID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO collection_assets (collection_id, asset_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM collection_assets WHERE collection_id=$1
+		ON CONFLICT (collection_id, asset_id) DO NOTHING`,
+		collectionID, assetID,
+	)
+	if err != nil {
+		log.Println("Failed to add asset to collection:", err)
+		return pgInsertError("add to collection", err)
+	}
+	return pgError("commit add to collection", tx.Commit(ctx))
+}
+
+func (p *PostgresStore) RemoveFromCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	if err := ownsCollection(ctx, p.pool, userID, collectionID); err != nil {
+		return err
+	}
+	tag, err := p.pool.Exec(ctx,
+		"DELETE FROM collection_assets WHERE collection_id=$1 AND asset_id=$2", collectionID, assetID,
+	)
+	if err != nil {
+		return pgError("remove from collection", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("asset %s in collection %v: %w", assetID, collectionID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) SetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return pgError("begin set collection assets", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var one int
+	err = tx.QueryRow(ctx,
+		"SELECT 1 FROM collections WHERE id=$1 AND user_id=$2 FOR UPDATE", collectionID, userID,
+	).Scan(&one)
+	if err != nil {
+		return pgError(fmt.Sprintf("find collection %v", collectionID), err)
+	}
+
+	var owned int
+	err = tx.QueryRow(ctx,
+		"SELECT count(*) FROM assets WHERE user_id=$1 AND asset_id = ANY($2)", userID, assetIDs,
+	).Scan(&owned)
+	if err != nil {
+		return pgError("find assets", err)
+	}
+	if owned != len(assetIDs) {
+		return fmt.Errorf("collection %v: some assets are missing: %w", collectionID, ErrNotFound)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM collection_assets WHERE collection_id=$1", collectionID); err != nil {
+		return pgError("clear collection", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO collection_assets (collection_id, asset_id, position)
+		SELECT $1, t.asset_id, t.ord FROM unnest($2::text[]) WITH ORDINALITY AS t(asset_id, ord)`,
+		collectionID, assetIDs,
+	)
+	if err != nil {
+		log.Println("Failed to set collection assets:", err)
+		return pgInsertError("set collection assets", err)
+	}
+	return pgError("commit set collection assets", tx.Commit(ctx))
+}
+
+// ----------------- Tag Methods -----------------
+
+func (p *PostgresStore) GetTags(ctx context.Context, ownerID uuid.UUID, assetID string) ([]string, error) {
+	if err := ownsAsset(ctx, p.pool, ownerID, assetID); err != nil {
+		return nil, err
+	}
+	rows, err := p.pool.Query(ctx, "SELECT tag FROM asset_tags WHERE asset_id=$1 ORDER BY tag", assetID)
+	if err != nil {
+		return nil, pgError("list tags", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, pgError("scan tag", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("list tags", err)
+	}
+	return tags, nil
+}
+
+func (p *PostgresStore) AddTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	// The SELECT yields no row unless ownerID owns the asset
+	cmd, err := p.pool.Exec(ctx, `
+		INSERT INTO asset_tags (asset_id, tag)
+		SELECT asset_id, $3 FROM assets WHERE asset_id=$1 AND user_id=$2
+		ON CONFLICT (asset_id, tag) DO NOTHING`,
+		assetID, ownerID, tag,
+	)
+	if err != nil {
+		log.Println("Failed to add tag:", err)
+		return pgInsertError("add tag", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		// Either the tag was already there or the asset isn't ownerID's
+		return ownsAsset(ctx, p.pool, ownerID, assetID)
+	}
+	return nil
+}
+
+func (p *PostgresStore) RemoveTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	if err := ownsAsset(ctx, p.pool, ownerID, assetID); err != nil {
+		return err
+	}
+	cmd, err := p.pool.Exec(ctx, "DELETE FROM asset_tags WHERE asset_id=$1 AND tag=$2", assetID, tag)
+	if err != nil {
+		return pgError("remove tag", err)
+	}
+	if cmd.RowsAffected() == 0 {
+		return fmt.Errorf("tag %q on asset %s: %w", tag, assetID, ErrNotFound)
+	}
+	return nil
+}
+
+func (p *PostgresStore) TagCounts(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT t.tag, count(*)
+		FROM asset_tags t JOIN assets a ON a.asset_id = t.asset_id
+		WHERE a.user_id=$1
+		GROUP BY t.tag
+		ORDER BY count(*) DESC, t.tag`, userID)
+	if err != nil {
+		return nil, pgError("count tags", err)
+	}
+	defer rows.Close()
+
+	cloud := []models.TagCount{}
+	for rows.Next() {
+		var tc models.TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, pgError("scan tag count", err)
+		}
+		cloud = append(cloud, tc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("count tags", err)
+	}
+	return cloud, nil
+}
+
+// ----------------- Search -----------------
+
+// refreshSearchVector rebuilds an asset's search document from the text its
+// type gives search. It must run in the transaction that wrote the asset.
+func refreshSearchVector(ctx context.Context, tx pgx.Tx, asset models.Asset) error {
+	text := models.SearchTextOf(asset)
+	_, err := tx.Exec(ctx, `
+		UPDATE assets SET search_text=$2, search_vector=
+			setweight(to_tsvector('english', $3), 'A') ||
+			setweight(to_tsvector('english', $4), 'B') ||
+			setweight(to_tsvector('english', $5), 'C') ||
+			setweight(to_tsvector('simple', $6), 'D')
+		WHERE asset_id=$1`,
+		asset.GetID(), text.String(), text.Title, text.Body, text.Details, text.Codes,
+	)
+	if err != nil {
+		log.Println("Failed to refresh search vector:", err)
+		return pgError("index asset", err)
+	}
+	return nil
+}
+
+// searchHeadlineOptions configures the snippets ts_headline cuts. It marks
+// matches with control characters, stripped from the text beforehand, so
+// that the snippet can be HTML-escaped before they become <mark> tags.
+const searchHeadlineOptions = "StartSel=\x02, StopSel=\x03, MaxWords=20, MinWords=5, MaxFragments=2"
+
+// searchSnippetMarks turns ts_headline's marks into <mark> tags.
+var searchSnippetMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+func (p *PostgresStore) Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT r.asset_id, r.asset_type, r.title, r.created_at, r.version, r.rank,
+			ts_headline('english', translate(r.search_text, E'\x02\x03', ''), r.query, $4)
+		FROM (
+			SELECT a.asset_id, a.asset_type, COALESCE(a.title, '') AS title, a.created_at, a.version,
+				ts_rank(a.search_vector, q.query) AS rank, a.search_text, q.query
+			FROM assets a
+			CROSS JOIN websearch_to_tsquery('english', $2) AS q(query)
+			WHERE a.user_id=$1 AND a.search_vector @@ q.query
+			ORDER BY rank DESC, a.asset_id
+			LIMIT $3
+		) r
+		ORDER BY r.rank DESC, r.asset_id`,
+		userID, query, limit, searchHeadlineOptions,
+	)
+	if err != nil {
+		log.Println("Failed to search assets:", err)
+		return nil, pgError("search assets", err)
+	}
+	defer rows.Close()
+
+	var refs []assetRef
+	ranked := make(map[string]models.SearchResult)
+	for rows.Next() {
+		var ref assetRef
+		var result models.SearchResult
+		var rank float32
+		if err := rows.Scan(&ref.id, &ref.assetType, &ref.title, &ref.createdAt, &ref.version, &rank, &result.Snippet); err != nil {
+			return nil, pgError("scan search result", err)
+		}
+		result.Rank = float64(rank)
+		result.Snippet = searchSnippetMarks.Replace(html.EscapeString(result.Snippet))
+		refs = append(refs, ref)
+		ranked[ref.id] = result
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pgError("search assets", err)
+	}
+
+	assets, err := p.loadAssets(ctx, refs)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]models.SearchResult, 0, len(assets))
+	for _, asset := range assets {
+		result := ranked[asset.GetID()]
+		result.Asset = asset
+		results = append(results, result)
+	}
+	return results, nil
 }
@@ -0,0 +1,210 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheOptions tune how CachedStore refreshes its entries. The zero value
+// keeps an entry until the cache expires it.
+type CacheOptions struct {
+	// SoftTTL is how long an entry is fresh. A stale entry is still served
+	// while a single background load replaces it, until the cache's own,
+	// longer TTL removes it. Zero never marks entries stale.
+	SoftTTL time.Duration
+	// EarlyExpiry, when positive, refreshes entries at random shortly before
+	// they go stale, the earlier the slower they were to load and the larger
+	// EarlyExpiry is, so that popular keys don't all go stale at once. 1 is
+	// a sensible value. It has no effect without SoftTTL.
+	EarlyExpiry float64
+}
+
+// cacheEntry is what CachedStore writes under a key: the value with the
+// time it goes stale and how long it took to load.
+type cacheEntry struct {
+	Value      string        `json:"v"`
+	FreshUntil time.Time     `json:"fresh_until,omitempty"`
+	LoadTime   time.Duration `json:"load_time,omitempty"`
+}
+
+// cacheable describes one kind of cached value: how to load it from the
+// database and how to turn it into the string the cache holds and back.
+type cacheable[T any] struct {
+	load   func(ctx context.Context) (T, error)
+	encode func(value T) (string, error)
+	decode func(cached string) (T, error)
+	// index, if set, records the key in the sets invalidation looks in. It
+	// runs before the value is written, so an invalidation racing with the
+	// write can't miss it.
+	index func(ctx context.Context, value T) error
+}
+
+// readThrough serves key from the cache, loading it on a miss. Concurrent
+// misses on the same key share a single load. A stale entry is served as is
+// while one background load refreshes it.
+//
+// While invalidations wait to be repaired the cache may be stale, so it is
+// bypassed altogether.
+func readThrough[T any](ctx context.Context, c *CachedStore, key string, kind cacheable[T]) (T, error) {
+	if c.Degraded() {
+		return kind.load(ctx)
+	}
+	if cached, err := c.cache.Get(ctx, key); err == nil && cached != "" {
+		var entry cacheEntry
+		if err := json.Unmarshal([]byte(cached), &entry); err == nil {
+			if value, err := kind.decode(entry.Value); err == nil {
+				if c.stale(entry) {
+					c.flight.DoChan(key, func() (interface{}, error) {
+						return loadAndWrite(context.WithoutCancel(ctx), c, key, kind)
+					})
+				}
+				return value, nil
+			}
+		}
+		log.Printf("cached_store: discarding unreadable cache entry %s", key)
+	}
+
+	// The load outlives a caller that gives up, since others may be
+	// waiting on it.
+	value, err, _ := c.flight.Do(key, func() (interface{}, error) {
+		return loadAndWrite(context.WithoutCancel(ctx), c, key, kind)
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return value.(T), nil
+}
+
+// loadAndWrite loads a value from the database and writes it to the cache,
+// unless the key was invalidated while it loaded: the value may predate the
+// change. A value that isn't cached is still returned.
+func loadAndWrite[T any](ctx context.Context, c *CachedStore, key string, kind cacheable[T]) (T, error) {
+	load := c.loads.begin(key)
+	defer c.loads.end(key, load)
+
+	start := time.Now()
+	value, err := kind.load(ctx)
+	if err != nil {
+		return value, err
+	}
+	load.mu.Lock()
+	defer load.mu.Unlock()
+	if !load.invalidated {
+		_ = write(ctx, c, key, kind, value, time.Since(start))
+	}
+	return value, nil
+}
+
+// inFlightLoads tracks the loads in progress, by cache key, so that an
+// invalidation can stop them from writing back what they read before it.
+type inFlightLoads struct {
+	mu    sync.Mutex
+	loads map[string]*inFlightLoad
+}
+
+// inFlightLoad is one load in progress. Its mutex is held while the load
+// writes back, so an invalidation either waits for the write, and deletes
+// the key after it, or marks the load before it writes.
+type inFlightLoad struct {
+	mu          sync.Mutex
+	invalidated bool
+}
+
+func (l *inFlightLoads) begin(key string) *inFlightLoad {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loads == nil {
+		l.loads = make(map[string]*inFlightLoad)
+	}
+	load := &inFlightLoad{}
+	l.loads[key] = load
+	return load
+}
+
+func (l *inFlightLoads) end(key string, load *inFlightLoad) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.loads[key] == load {
+		delete(l.loads, key)
+	}
+}
+
+// invalidate marks the loads in progress of the keys the invalidation drops:
+// its keys, its owners' listings and, if it names assets, every favourites
+// page, since which pages hold an asset is only known once they are written.
+// It must run before the keys are deleted.
+func (l *inFlightLoads) invalidate(inv pendingInvalidation) {
+	dropped := func(key string) bool {
+		for _, k := range inv.keys {
+			if key == k {
+				return true
+			}
+		}
+		for _, owner := range inv.owners {
+			if strings.HasPrefix(key, "assets:"+owner.String()+":") {
+				return true
+			}
+		}
+		return len(inv.assets) > 0 && strings.HasPrefix(key, "favourites:")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, load := range l.loads {
+		if dropped(key) {
+			load.mu.Lock()
+			load.invalidated = true
+			load.mu.Unlock()
+		}
+	}
+}
+
+// write stores a value under key, fresh for SoftTTL.
+func write[T any](ctx context.Context, c *CachedStore, key string, kind cacheable[T], value T, loadTime time.Duration) error {
+	entry := cacheEntry{LoadTime: loadTime}
+	if c.opts.SoftTTL > 0 {
+		entry.FreshUntil = time.Now().Add(c.opts.SoftTTL)
+	}
+
+	var err error
+	entry.Value, err = kind.encode(value)
+	if err != nil {
+		log.Printf("cached_store: not caching %s: %v", key, err)
+		return err
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("cached_store: not caching %s: %v", key, err)
+		return err
+	}
+	if kind.index != nil {
+		if err := kind.index(ctx, value); err != nil {
+			return err
+		}
+	}
+	return c.cache.Set(ctx, key, string(b))
+}
+
+// stale reports whether an entry should be refreshed. With EarlyExpiry set,
+// an entry may be refreshed before it goes stale (probabilistic early
+// expiration, as in "Optimal Probabilistic Cache Stampede Prevention").
+func (c *CachedStore) stale(entry cacheEntry) bool {
+	if entry.FreshUntil.IsZero() {
+		return false
+	}
+	now := time.Now()
+	if c.opts.EarlyExpiry > 0 {
+		// 1-rand.Float64() is in (0, 1], so the logarithm is finite and
+		// never positive.
+		early := -float64(entry.LoadTime) * c.opts.EarlyExpiry * math.Log(1-rand.Float64())
+		now = now.Add(time.Duration(early))
+	}
+	return !now.Before(entry.FreshUntil)
+}
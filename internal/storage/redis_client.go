@@ -2,40 +2,180 @@ package storage
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// RedisOptions say how to reach Redis. The zero value is a single server on
+// localhost:6379 holding entries for 5 minutes.
+type RedisOptions struct {
+	// Addrs are host:port pairs: the server, the Sentinels when MasterName
+	// is set, or some of the nodes when Cluster is set.
+	Addrs []string
+	// MasterName is the name Sentinel knows the master by.
+	MasterName string
+	Cluster    bool
+
+	Username         string
+	Password         string
+	SentinelUsername string
+	SentinelPassword string
+	DB               int // not supported by Cluster
+
+	TLS           bool
+	TLSCAFile     string // PEM bundle to trust instead of the system's roots
+	TLSServerName string // overrides the name certificates are checked against
+
+	// Zero leaves these to go-redis.
+	PoolSize     int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	TTL time.Duration // how long entries live
+}
+
+// RedisClient is the Cache and InvalidationBus backed by Redis: a single
+// server, a Sentinel-managed master or a cluster.
 type RedisClient struct {
-	Client *redis.Client
+	Client redis.UniversalClient
 	TTL    time.Duration
 }
 
-func NewRedisClient(addr string) *RedisClient {
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		OnConnect: func(ctx context.Context, cn *redis.Conn) error {
-			return nil
-		},
-		// Password: "", // TODO: add password
-		// DB: 0,
+// NewRedisClient checks the options and returns a client for them. It
+// doesn't connect: go-redis dials on first use.
+func NewRedisClient(opts RedisOptions) (*RedisClient, error) {
+	if len(opts.Addrs) == 0 {
+		opts.Addrs = []string{"localhost:6379"}
+	}
+	if opts.TTL == 0 {
+		opts.TTL = 5 * time.Minute
+	}
+	switch {
+	case opts.TTL < 0:
+		return nil, fmt.Errorf("redis: negative TTL %v", opts.TTL)
+	case opts.Cluster && opts.MasterName != "":
+		return nil, errors.New("redis: choose either Sentinel or Cluster, not both")
+	case opts.Cluster && opts.DB != 0:
+		return nil, errors.New("redis: Cluster only has database 0")
+	case len(opts.Addrs) > 1 && !opts.Cluster && opts.MasterName == "":
+		return nil, fmt.Errorf("redis: %d addresses given for a single server; set a Sentinel master name or Cluster", len(opts.Addrs))
+	}
+
+	var tlsConfig *tls.Config
+	if opts.TLS {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: opts.TLSServerName}
+		if opts.TLSCAFile != "" {
+			pem, err := os.ReadFile(opts.TLSCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("redis: read CA file: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("redis: no certificates in CA file %s", opts.TLSCAFile)
+			}
+		}
+	} else if opts.TLSCAFile != "" || opts.TLSServerName != "" {
+		return nil, errors.New("redis: TLS settings given without TLS")
+	}
+
+	rdb := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		IsClusterMode:    opts.Cluster,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		SentinelUsername: opts.SentinelUsername,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.DB,
+		TLSConfig:        tlsConfig,
+		PoolSize:         opts.PoolSize,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
 	})
 
 	return &RedisClient{
 		Client: rdb,
-		TTL:    5 * time.Minute,
-	}
+		TTL:    opts.TTL,
+	}, nil
 }
 
+// Get returns an empty value for a missing key, so that a miss isn't
+// mistaken for Redis failing.
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	return r.Client.Get(ctx, key).Result()
+	value, err := r.Client.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return value, err
 }
 
 func (r *RedisClient) Set(ctx context.Context, key string, value string) error {
 	return r.Client.Set(ctx, key, value, r.TTL).Err()
 }
 
-func (r *RedisClient) Del(ctx context.Context, key string) error {
-	return r.Client.Del(ctx, key).Err()
+// Del drops keys. A cluster refuses a DEL whose keys live in different
+// slots, which invalidations' keys almost always do, so there each key gets
+// its own DEL, pipelined to the node holding it.
+func (r *RedisClient) Del(ctx context.Context, keys ...string) error {
+	if _, cluster := r.Client.(*redis.ClusterClient); !cluster || len(keys) < 2 {
+		return r.Client.Del(ctx, keys...).Err()
+	}
+	pipe := r.Client.Pipeline()
+	for _, key := range keys {
+		pipe.Del(ctx, key)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisClient) SAdd(ctx context.Context, key string, members ...string) error {
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	pipe := r.Client.TxPipeline()
+	pipe.SAdd(ctx, key, args...)
+	pipe.Expire(ctx, key, r.TTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisClient) SMembers(ctx context.Context, key string) ([]string, error) {
+	return r.Client.SMembers(ctx, key).Result()
+}
+
+// invalidationChannel is the pub/sub channel TieredCache instances announce
+// invalidations on.
+const invalidationChannel = "cache-invalidations"
+
+func (r *RedisClient) Publish(ctx context.Context, message string) error {
+	return r.Client.Publish(ctx, invalidationChannel, message).Err()
+}
+
+// Subscribe hands every invalidation to handle until ctx is done. The
+// subscription reconnects by itself, also when Redis is down to begin with;
+// messages sent while it is down are lost.
+func (r *RedisClient) Subscribe(ctx context.Context, handle func(message string)) error {
+	sub := r.Client.Subscribe(ctx, invalidationChannel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			handle(msg.Payload)
+		}
+	}
 }
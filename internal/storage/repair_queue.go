@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxPendingRepairs bounds how many keys, owners and assets the repair queue
+// holds. Past it invalidations are dropped, and the entries they were meant
+// to remove live until the cache expires them.
+const maxPendingRepairs = 10000
+
+// repairQueue collects the invalidations CachedStore failed to make, to be
+// made again once the cache is back. Besides plain keys it holds owners
+// whose listings, and assets whose favourites pages, are to be dropped: the
+// sets naming those entries may not have been readable either.
+type repairQueue struct {
+	mu     sync.Mutex
+	keys   map[string]bool
+	owners map[uuid.UUID]bool
+	assets map[string]bool
+}
+
+// pendingInvalidation is a batch of invalidations, made or queued together.
+type pendingInvalidation struct {
+	keys   []string
+	owners []uuid.UUID
+	assets []string
+}
+
+func (q *repairQueue) add(inv pendingInvalidation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.keys == nil {
+		q.keys = make(map[string]bool)
+		q.owners = make(map[uuid.UUID]bool)
+		q.assets = make(map[string]bool)
+	}
+	if len(q.keys)+len(q.owners)+len(q.assets) >= maxPendingRepairs {
+		log.Printf("cached_store: repair queue full, dropping invalidation of keys %v, owners %v and assets %v",
+			inv.keys, inv.owners, inv.assets)
+		return
+	}
+	for _, key := range inv.keys {
+		q.keys[key] = true
+	}
+	for _, owner := range inv.owners {
+		q.owners[owner] = true
+	}
+	for _, asset := range inv.assets {
+		q.assets[asset] = true
+	}
+}
+
+// snapshot returns what the queue holds. It stays queued, so that reads
+// keep bypassing the cache, until done is called with it.
+func (q *repairQueue) snapshot() pendingInvalidation {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var inv pendingInvalidation
+	for key := range q.keys {
+		inv.keys = append(inv.keys, key)
+	}
+	for owner := range q.owners {
+		inv.owners = append(inv.owners, owner)
+	}
+	for asset := range q.assets {
+		inv.assets = append(inv.assets, asset)
+	}
+	return inv
+}
+
+// done removes invalidations that have been made.
+func (q *repairQueue) done(inv pendingInvalidation) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, key := range inv.keys {
+		delete(q.keys, key)
+	}
+	for _, owner := range inv.owners {
+		delete(q.owners, owner)
+	}
+	for _, asset := range inv.assets {
+		delete(q.assets, asset)
+	}
+}
+
+func (q *repairQueue) pending() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.keys)+len(q.owners)+len(q.assets) > 0
+}
+
+// invalidate drops the keys, the owners' listings and the favourites pages
+// holding the assets. If any of it fails the whole batch is queued for
+// repair, and reads bypass the cache until it is made.
+func (c *CachedStore) invalidate(ctx context.Context, inv pendingInvalidation) {
+	if err := c.tryInvalidate(ctx, inv); err != nil {
+		c.repairs.add(inv)
+	}
+}
+
+func (c *CachedStore) tryInvalidate(ctx context.Context, inv pendingInvalidation) error {
+	c.loads.invalidate(inv)
+	keys := append([]string(nil), inv.keys...)
+	for _, owner := range inv.owners {
+		listings, err := c.listingKeys(ctx, owner)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, listings...)
+	}
+	for _, assetID := range inv.assets {
+		favourites, err := c.favouritedByKeys(ctx, assetID)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, favourites...)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.cache.Del(ctx, keys...)
+}
+
+// Degraded reports whether invalidations are waiting to be repaired. Until
+// they are, the cache may hold stale entries, so reads bypass it.
+func (c *CachedStore) Degraded() bool {
+	return c.repairs.pending()
+}
+
+// Repair retries the invalidations that failed. Those that fail again stay
+// queued.
+func (c *CachedStore) Repair(ctx context.Context) error {
+	if c.cache == nil || !c.repairs.pending() {
+		return nil
+	}
+	inv := c.repairs.snapshot()
+	if err := c.tryInvalidate(ctx, inv); err != nil {
+		return err
+	}
+	c.repairs.done(inv)
+	log.Printf("cached_store: repaired %d keys, %d owners' listings and %d assets' favourites pages",
+		len(inv.keys), len(inv.owners), len(inv.assets))
+	return nil
+}
+
+// RunRepairs calls Repair every interval until ctx is done. Run it in its
+// own goroutine.
+func (c *CachedStore) RunRepairs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = c.Repair(ctx)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"assetsApp/internal/models"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// The weights of the fields of a search document. They are the ones ts_rank
+// gives the A to D labels of the search vector PostgresStore writes, so both
+// stores rank alike.
+const (
+	weightTitle   = 1.0
+	weightBody    = 0.4
+	weightDetails = 0.2
+	weightCodes   = 0.1
+)
+
+// searchDoc is the tokenised text of one asset, which MemoryStore keeps per
+// asset ID and searches instead of Postgres' tsvector. Fields are ordered by
+// weight, highest first.
+type searchDoc []searchField
+
+type searchField struct {
+	text   string
+	weight float64
+	tokens []string
+}
+
+// newSearchDoc indexes the text the asset's type gives search, as
+// PostgresStore does.
+func newSearchDoc(asset models.Asset) searchDoc {
+	text := models.SearchTextOf(asset)
+	doc := searchDoc{
+		{text: text.Title, weight: weightTitle},
+		{text: text.Body, weight: weightBody},
+		{text: text.Details, weight: weightDetails},
+		{text: text.Codes, weight: weightCodes},
+	}
+	for i := range doc {
+		doc[i].tokens = tokenize(doc[i].text)
+	}
+	return doc
+}
+
+// tokenize lower-cases text and splits it into runs of letters and digits.
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// matchesTerm reports whether a token matches a query term. Matching by
+// prefix stands in for stemming: "purchase" finds "purchases".
+func matchesTerm(token string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(token, term) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f searchField) matches(terms []string) bool {
+	for _, token := range f.tokens {
+		if matchesTerm(token, terms) {
+			return true
+		}
+	}
+	return false
+}
+
+// rank scores the document against the query terms, each of which must
+// match somewhere. A term scores the weight of the best field it is in, and
+// the rank is the average over the terms.
+func (d searchDoc) rank(terms []string) (float64, bool) {
+	var score float64
+	for _, term := range terms {
+		best := 0.0
+		for _, f := range d {
+			if f.weight > best && f.matches([]string{term}) {
+				best = f.weight
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		score += best
+	}
+	return score / float64(len(terms)), true
+}
+
+// snippet returns the best field that matches any of the terms, HTML-escaped,
+// with the matching words wrapped in <mark> and </mark> as ts_headline does.
+func (d searchDoc) snippet(terms []string) string {
+	for _, f := range d {
+		if f.matches(terms) {
+			return highlight(f.text, terms)
+		}
+	}
+	return ""
+}
+
+func highlight(text string, terms []string) string {
+	var b strings.Builder
+	start := -1
+	word := func(end int) {
+		if w := text[start:end]; matchesTerm(strings.ToLower(w), terms) {
+			b.WriteString("<mark>" + html.EscapeString(w) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(w))
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			word(i)
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if start >= 0 {
+		word(len(text))
+	}
+	return b.String()
+}
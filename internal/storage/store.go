@@ -2,18 +2,129 @@ package storage
 
 import (
 	"assetsApp/internal/models"
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// AnyVersion skips the version check of Remove and Update.
+const AnyVersion int64 = 0
 
+// AssetStore persists assets and favourites per user. Every method takes the
+// caller's context and reports failures with the errors in errors.go.
 type AssetStore interface {
-	Get(userID uuid.UUID) []models.Asset
-	Add(userID uuid.UUID, asset models.Asset)
-	Remove(userID uuid.UUID, assetID string) bool
-	EditDescription(userID uuid.UUID, assetID, newDesc string) bool
-
-	GetFavourites(userID uuid.UUID) []models.Favourite
-	AddFavourite(userID uuid.UUID, assetID, assetType string) bool
-	RemoveFavourite(userID uuid.UUID, assetID string) bool
+	Get(ctx context.Context, userID uuid.UUID, opts ListOptions) (AssetPage, error)
+	GetByID(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error)
+	Add(ctx context.Context, userID uuid.UUID, asset models.Asset) error
+	// Remove deletes the asset if its stored version is version, or whatever
+	// its version when version is AnyVersion.
+	Remove(ctx context.Context, userID uuid.UUID, assetID string, version int64) error
+	// Update replaces the stored asset with the same ID. The asset type cannot
+	// change. Unless asset.GetVersion() is AnyVersion it must equal the stored
+	// version; on success asset carries the new version.
+	Update(ctx context.Context, userID uuid.UUID, asset models.Asset) error
+
+	// GetFavourites lists a user's favourites, by default pinned ones first
+	// and then by position.
+	GetFavourites(ctx context.Context, userID uuid.UUID, opts ListOptions) (FavouritePage, error)
+	// AddFavourite favourites an asset at the end of the user's order. It
+	// fails with ErrNotFound if the asset doesn't exist and with ErrConflict
+	// if the user has already favourited it.
+	AddFavourite(ctx context.Context, userID uuid.UUID, assetID, assetType string) error
+	// RemoveFavourite fails with ErrNotFound if the user hasn't favourited
+	// the asset.
+	RemoveFavourite(ctx context.Context, userID uuid.UUID, assetID string) error
+	// UpdateFavourite sets the fields of a favourite the update sets. It
+	// fails with ErrNotFound if the user hasn't favourited the asset.
+	UpdateFavourite(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error
+	// MoveFavourite moves a favourite to just before targetID in the user's
+	// order, or just after it if after is set, renumbering positions from 1.
+	// It fails with ErrNotFound unless both assets are favourites.
+	MoveFavourite(ctx context.Context, userID uuid.UUID, assetID, targetID string, after bool) error
+
+	// Access returns the owner of an asset and the permission userID has on
+	// it. It fails with ErrNotFound if the asset doesn't exist or userID
+	// neither owns it nor has it shared with them.
+	Access(ctx context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error)
+	// GetShares lists the grants on an asset owned by ownerID.
+	GetShares(ctx context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error)
+	// Share grants share.UserID access to an asset owned by share.OwnerID,
+	// replacing any earlier grant to the same user.
+	Share(ctx context.Context, share models.Share) error
+	// Unshare revokes a grant, along with the grantee's favourite of the asset.
+	Unshare(ctx context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error
+	// GetSharedWithMe lists the assets other users have shared with userID.
+	GetSharedWithMe(ctx context.Context, userID uuid.UUID, opts ListOptions) (SharedPage, error)
+
+	// ListUsers lists every user. Users can only be sorted by ID.
+	ListUsers(ctx context.Context, opts ListOptions) (UserPage, error)
+	// Reassign makes newOwnerID the owner of an asset and returns the
+	// previous owner. A share the new owner held on the asset is dropped.
+	Reassign(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error)
+	// PurgeUser deletes a user with their assets, favourites and shares,
+	// including other users' favourites of and grants on those assets.
+	PurgeUser(ctx context.Context, userID uuid.UUID) error
+
+	// Collections group a user's own assets. Every collection method fails
+	// with ErrNotFound if userID doesn't own the collection, and the
+	// membership methods also if userID doesn't own the asset.
+	GetCollections(ctx context.Context, userID uuid.UUID) ([]models.Collection, error)
+	GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error)
+	// AddCollection stores a new, empty collection and sets its CreatedAt. A
+	// user's collection names are unique.
+	AddCollection(ctx context.Context, userID uuid.UUID, collection *models.Collection) error
+	RenameCollection(ctx context.Context, userID, collectionID uuid.UUID, name string) error
+	RemoveCollection(ctx context.Context, userID, collectionID uuid.UUID) error
+	// GetCollectionAssets returns a collection's assets in collection order.
+	GetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error)
+	// AddToCollection appends an asset to a collection. Adding a member again
+	// leaves it where it is.
+	AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error
+	RemoveFromCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error
+	// SetCollectionAssets replaces a collection's members with assetIDs, in
+	// that order.
+	SetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) error
+
+	// GetTags lists the tags on an asset owned by ownerID, sorted.
+	GetTags(ctx context.Context, ownerID uuid.UUID, assetID string) ([]string, error)
+	// AddTag tags an asset owned by ownerID. Adding a tag again is a no-op.
+	AddTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error
+	// RemoveTag fails with ErrNotFound if the asset doesn't carry the tag.
+	RemoveTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error
+	// TagCounts counts the user's assets per tag, most used first and then
+	// by tag.
+	TagCounts(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error)
+
+	// Search finds up to limit of the user's own assets matching a free-text
+	// query, best match first. Every word of the query must match.
+	Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error)
 }
 
+// APIKeyStore persists API keys. Only a hash of each key's secret is kept.
+type APIKeyStore interface {
+	AddAPIKey(ctx context.Context, key models.APIKey, hash []byte) error
+	// GetAPIKey finds a key, revoked or not, by its prefix and returns it with
+	// its hash.
+	GetAPIKey(ctx context.Context, prefix string) (models.APIKey, []byte, error)
+	ListAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	// RevokeAPIKey marks a key revoked as of at. Revoking it again keeps the
+	// first time.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// Authorize checks that userID holds at least the need permission on an asset
+// and returns the asset's owner, whose ID the other store methods expect. A
+// user without any access gets ErrNotFound, so assets aren't revealed to
+// strangers; one with too little gets ErrForbidden.
+func Authorize(ctx context.Context, store AssetStore, userID uuid.UUID, assetID string, need models.Permission) (uuid.UUID, error) {
+	ownerID, perm, err := store.Access(ctx, userID, assetID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !perm.Allows(need) {
+		return uuid.Nil, fmt.Errorf("asset %s: %s access needed, have %s: %w", assetID, need, perm, ErrForbidden)
+	}
+	return ownerID, nil
+}
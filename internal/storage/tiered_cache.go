@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InvalidationBus carries cache invalidations between instances of the
+// service. RedisClient implements it with pub/sub.
+type InvalidationBus interface {
+	Publish(ctx context.Context, message string) error
+	// Subscribe calls handle with every message published by any instance,
+	// this one included, until ctx is done.
+	Subscribe(ctx context.Context, handle func(message string)) error
+}
+
+// TieredCache is a Cache that keeps recently read entries in a bounded
+// in-process LRU in front of a shared cache such as Redis. Writes and
+// deletes go to the shared cache and are announced on the bus, so that every
+// instance evicts its local copy. Sets are not kept locally.
+//
+// An instance that misses announcements, for instance while its
+// subscription reconnects, serves its local copies until their TTL runs out,
+// so the TTL should be short.
+type TieredCache struct {
+	remote Cache
+	bus    InvalidationBus
+	local  *lruCache
+	origin string // tells this instance's announcements from others'
+}
+
+// invalidation is the message TieredCache publishes when keys change.
+type invalidation struct {
+	Origin string   `json:"origin"`
+	Keys   []string `json:"keys"`
+}
+
+// NewTieredCache keeps up to size entries from remote for at most ttl. A nil
+// bus leaves other instances' local copies to expire.
+func NewTieredCache(remote Cache, bus InvalidationBus, size int, ttl time.Duration) *TieredCache {
+	return &TieredCache{
+		remote: remote,
+		bus:    bus,
+		local:  newLRUCache(size, ttl),
+		origin: uuid.NewString(),
+	}
+}
+
+// Listen evicts the keys other instances announce until ctx is done. Run it
+// in its own goroutine.
+func (t *TieredCache) Listen(ctx context.Context) error {
+	if t.bus == nil {
+		return nil
+	}
+	return t.bus.Subscribe(ctx, func(message string) {
+		var inv invalidation
+		if err := json.Unmarshal([]byte(message), &inv); err != nil {
+			log.Printf("tiered_cache: ignoring malformed invalidation: %v", err)
+			return
+		}
+		if inv.Origin == t.origin {
+			return
+		}
+		t.local.del(inv.Keys...)
+	})
+}
+
+func (t *TieredCache) Get(ctx context.Context, key string) (string, error) {
+	if value, ok := t.local.get(key); ok {
+		return value, nil
+	}
+	// A set or invalidation of the key while it is read from remote may
+	// have made the value stale; fill then leaves it out.
+	fill := t.local.beginFill(key)
+	value, err := t.remote.Get(ctx, key)
+	if err != nil {
+		t.local.fill(key, fill, "")
+		return value, err
+	}
+	t.local.fill(key, fill, value)
+	return value, nil
+}
+
+func (t *TieredCache) Set(ctx context.Context, key string, value string) error {
+	if err := t.remote.Set(ctx, key, value); err != nil {
+		t.local.del(key)
+		return err
+	}
+	t.local.set(key, value)
+	t.announce(ctx, key)
+	return nil
+}
+
+func (t *TieredCache) Del(ctx context.Context, keys ...string) error {
+	t.local.del(keys...)
+	err := t.remote.Del(ctx, keys...)
+	t.announce(ctx, keys...)
+	return err
+}
+
+func (t *TieredCache) SAdd(ctx context.Context, key string, members ...string) error {
+	return t.remote.SAdd(ctx, key, members...)
+}
+
+func (t *TieredCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	return t.remote.SMembers(ctx, key)
+}
+
+func (t *TieredCache) announce(ctx context.Context, keys ...string) {
+	if t.bus == nil || len(keys) == 0 {
+		return
+	}
+	b, err := json.Marshal(invalidation{Origin: t.origin, Keys: keys})
+	if err != nil {
+		return
+	}
+	if err := t.bus.Publish(ctx, string(b)); err != nil {
+		log.Printf("tiered_cache: failed to announce invalidation of %v: %v", keys, err)
+	}
+}
+
+// lruCache is a bounded, expiring map of strings, dropping the least
+// recently used entry when full. It is safe for concurrent use.
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	order *list.List // front is most recently used
+	items map[string]*list.Element
+	// fills in progress, by key; setting or deleting the key cancels them
+	fills    map[string]uint64
+	lastFill uint64
+}
+
+type lruEntry struct {
+	key     string
+	value   string
+	expires time.Time
+}
+
+func newLRUCache(size int, ttl time.Duration) *lruCache {
+	return &lruCache{
+		size:  size,
+		ttl:   ttl,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+		fills: make(map[string]uint64),
+	}
+}
+
+func (c *lruCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+	entry := el.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.items, key)
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return entry.value, true
+}
+
+// beginFill starts reading key from elsewhere, to be stored with fill.
+func (c *lruCache) beginFill(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastFill++
+	c.fills[key] = c.lastFill
+	return c.lastFill
+}
+
+// fill stores the value read since beginFill returned id, unless the key was
+// set or deleted meanwhile or the value is empty.
+func (c *lruCache) fill(key string, id uint64, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fills[key] != id {
+		return
+	}
+	delete(c.fills, key)
+	if value != "" {
+		c.setLocked(key, value)
+	}
+}
+
+func (c *lruCache) set(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.fills, key)
+	c.setLocked(key, value)
+}
+
+func (c *lruCache) setLocked(key, value string) {
+	if c.size <= 0 {
+		return
+	}
+	expires := time.Now().Add(c.ttl)
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value, entry.expires = value, expires
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruEntry).key)
+	}
+}
+
+func (c *lruCache) del(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.fills, key)
+		if el, ok := c.items[key]; ok {
+			c.order.Remove(el)
+			delete(c.items, key)
+		}
+	}
+}
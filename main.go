@@ -1,12 +1,19 @@
 package main
 
 import (
+	"assetsApp/internal/auth"
 	"assetsApp/internal/config"
 	"assetsApp/internal/handlers"
+	"assetsApp/internal/migrations"
+	apiKeyServices "assetsApp/internal/services/apikey"
 	assetServices "assetsApp/internal/services/asset"
+	collectionServices "assetsApp/internal/services/collection"
 	favouriteServices "assetsApp/internal/services/favourite"
+	shareServices "assetsApp/internal/services/share"
+	tagServices "assetsApp/internal/services/tag"
 	"assetsApp/internal/storage"
 	"context"
+	"flag"
 	"log"
 	"net/http"
 
@@ -15,6 +22,9 @@ import (
 )
 
 func main() {
+	migrate := flag.Bool("migrate", false, "apply pending database migrations before serving")
+	flag.Parse()
+
 	log.Println("Starting the application...")
 	cfg := config.LoadConfig()
 	// -------------------- STORAGE --------------------
@@ -28,38 +38,161 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if *migrate {
+		migrator, err := migrations.NewMigrator(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migrator.Up(context.Background()); err != nil {
+			log.Fatal(err)
+		}
+	}
 	dbStore := storage.NewPostgresStore(db)
-	redisClient := storage.NewRedisClient(cfg.RedisAddr)
-	store := storage.NewCachedStore(dbStore, redisClient)
+	redisClient, err := storage.NewRedisClient(storage.RedisOptions{
+		Addrs:            cfg.RedisAddrs,
+		MasterName:       cfg.RedisSentinelMaster,
+		Cluster:          cfg.RedisCluster,
+		Username:         cfg.RedisUsername,
+		Password:         cfg.RedisPassword,
+		SentinelUsername: cfg.RedisSentinelUsername,
+		SentinelPassword: cfg.RedisSentinelPassword,
+		DB:               cfg.RedisDB,
+		TLS:              cfg.RedisTLS,
+		TLSCAFile:        cfg.RedisTLSCAFile,
+		TLSServerName:    cfg.RedisTLSServerName,
+		PoolSize:         cfg.RedisPoolSize,
+		DialTimeout:      cfg.RedisDialTimeout,
+		ReadTimeout:      cfg.RedisReadTimeout,
+		WriteTimeout:     cfg.RedisWriteTimeout,
+		TTL:              cfg.CacheTTL,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	var cache storage.Cache = storage.NewBreakerCache(redisClient, storage.BreakerOptions{
+		Failures: cfg.CacheBreakerFailures,
+		Cooldown: cfg.CacheBreakerCooldown,
+		Timeout:  cfg.CacheTimeout,
+	})
+	if cfg.CacheLocalSize > 0 {
+		tiered := storage.NewTieredCache(cache, redisClient, cfg.CacheLocalSize, cfg.CacheLocalTTL)
+		go func() {
+			if err := tiered.Listen(context.Background()); err != nil {
+				log.Printf("cache invalidation listener stopped: %v", err)
+			}
+		}()
+		cache = tiered
+	}
+	store := storage.NewCachedStore(dbStore, cache, storage.CacheOptions{
+		SoftTTL:     cfg.CacheSoftTTL,
+		EarlyExpiry: cfg.CacheEarlyExpiry,
+	})
+	go store.RunRepairs(context.Background(), cfg.CacheRepairInterval)
+
+	// -------------------- AUTH --------------------
+	keys := auth.NewKeySet()
+	if cfg.JWTSecret != "" {
+		keys.AddHMAC("", []byte(cfg.JWTSecret))
+	}
+	if cfg.JWTPublicKeyFile != "" {
+		if err := keys.LoadRSAPublicKeyFile("", cfg.JWTPublicKeyFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.JWKSFile != "" {
+		if err := keys.LoadJWKSFile(cfg.JWKSFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if keys.Len() == 0 {
+		log.Fatal("No usable JWT signing keys were loaded")
+	}
+	verifier := auth.NewVerifier(keys, cfg.JWTIssuer, cfg.JWTAudience)
 
 	// -------------------- SERVICES --------------------
 	assetService := assetServices.NewAssetService(store)
 	favouriteService := favouriteServices.NewFavouriteService(store)
+	shareService := shareServices.NewShareService(store)
+	collectionService := collectionServices.NewCollectionService(store)
+	tagService := tagServices.NewTagService(store)
+	apiKeyService := apiKeyServices.NewAPIKeyService(dbStore)
 
 	// -------------------- HANDLERS --------------------
 	assetHandler := handlers.NewAssetHandler(assetService)
 	favouriteHandler := handlers.NewFavouriteHandler(favouriteService)
+	shareHandler := handlers.NewShareHandler(shareService)
+	collectionHandler := handlers.NewCollectionHandler(collectionService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	adminHandler := handlers.NewAdminHandler(assetService)
 
 	// -------------------- ROUTER --------------------
 	r := mux.NewRouter()
 
-	// Asset routes
-	r.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
-	r.HandleFunc("/users/{userId}/assets", assetHandler.AddAsset).Methods("POST")
-	r.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.EditAsset).Methods("PUT")
-	r.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
-
-	// Favourite routes
-	r.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
-	r.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.AddFavourite).Methods("POST")
-	r.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.RemoveFavourite).Methods("DELETE")
-
 	// Health check
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
 
+	// Everything else needs a bearer token for the user in the path, or an
+	// API key with the scope the route needs
+	api := r.NewRoute().Subrouter()
+	api.Use(auth.Middleware(verifier, apiKeyService))
+
+	// Asset routes
+	api.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets", assetHandler.AddAsset).Methods("POST")
+	// Registered before {assetId}, which would otherwise match "search"
+	api.HandleFunc("/users/{userId}/assets/search", assetHandler.SearchAssets).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.ReplaceAsset).Methods("PUT")
+	api.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.PatchAsset).Methods("PATCH")
+	api.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
+
+	// Favourite routes
+	api.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	api.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.AddFavourite).Methods("POST")
+	api.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.RemoveFavourite).Methods("DELETE")
+	api.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.UpdateFavourite).Methods("PATCH")
+	api.HandleFunc("/users/{userId}/favourites/{assetId}/position", favouriteHandler.MoveFavourite).Methods("PUT")
+
+	// Share routes
+	api.HandleFunc("/users/{userId}/assets/{assetId}/shares", shareHandler.GetShares).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets/{assetId}/shares/{granteeId}", shareHandler.ShareAsset).Methods("PUT")
+	api.HandleFunc("/users/{userId}/assets/{assetId}/shares/{granteeId}", shareHandler.UnshareAsset).Methods("DELETE")
+	api.HandleFunc("/users/{userId}/shared", shareHandler.GetSharedWithMe).Methods("GET")
+
+	// Collection routes
+	api.HandleFunc("/users/{userId}/collections", collectionHandler.GetCollections).Methods("GET")
+	api.HandleFunc("/users/{userId}/collections", collectionHandler.CreateCollection).Methods("POST")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.GetCollection).Methods("GET")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.RenameCollection).Methods("PUT")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.RemoveCollection).Methods("DELETE")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}/assets", collectionHandler.GetCollectionAssets).Methods("GET")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}/assets", collectionHandler.SetCollectionAssets).Methods("PUT")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}/assets/{assetId}", collectionHandler.AddCollectionAsset).Methods("PUT")
+	api.HandleFunc("/users/{userId}/collections/{collectionId}/assets/{assetId}", collectionHandler.RemoveCollectionAsset).Methods("DELETE")
+
+	// Tag routes
+	api.HandleFunc("/users/{userId}/tags", tagHandler.GetTagCloud).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets/{assetId}/tags", tagHandler.GetTags).Methods("GET")
+	api.HandleFunc("/users/{userId}/assets/{assetId}/tags/{tag}", tagHandler.AddTag).Methods("PUT")
+	api.HandleFunc("/users/{userId}/assets/{assetId}/tags/{tag}", tagHandler.RemoveTag).Methods("DELETE")
+
+	// Admin routes
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Use(auth.RequireRole("admin"))
+	admin.HandleFunc("/api-keys", apiKeyHandler.GetAPIKeys).Methods("GET")
+	admin.HandleFunc("/api-keys", apiKeyHandler.CreateAPIKey).Methods("POST")
+	admin.HandleFunc("/api-keys/{keyId}", apiKeyHandler.RevokeAPIKey).Methods("DELETE")
+	admin.HandleFunc("/users", adminHandler.GetUsers).Methods("GET")
+	admin.HandleFunc("/users/{userId}", adminHandler.PurgeUser).Methods("DELETE")
+	admin.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	admin.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
+	admin.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	admin.HandleFunc("/assets/{assetId}/owner", adminHandler.ReassignAsset).Methods("PUT")
+
 	// -------------------- START SERVER --------------------
 	log.Println("Server running on :8080")
 	log.Fatal(http.ListenAndServe(":8080", r))
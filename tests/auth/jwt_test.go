@@ -0,0 +1,154 @@
+package auth_test
+
+import (
+	"assetsApp/internal/auth"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var secret = []byte("test-secret")
+
+// mint signs a token for subject that expires in ttl.
+func mint(t *testing.T, method jwt.SigningMethod, key interface{}, kid, subject string, ttl time.Duration) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    "test-issuer",
+		Audience:  jwt.ClaimStrings{"assets"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func hmacVerifier() *auth.Verifier {
+	return hmacVerifierFor("test-issuer")
+}
+
+func hmacVerifierFor(issuer string) *auth.Verifier {
+	keys := auth.NewKeySet()
+	keys.AddHMAC("", secret)
+	return auth.NewVerifier(keys, issuer, "assets")
+}
+
+// writeJWKS writes a JSON Web Key Set holding pub under kid.
+func writeJWKS(t *testing.T, kid string, pub *rsa.PublicKey) string {
+	t.Helper()
+	doc := map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+	}}}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestVerifier_HS256(t *testing.T) {
+	userID := uuid.New()
+	verifier := hmacVerifier()
+
+	p, err := verifier.Verify(mint(t, jwt.SigningMethodHS256, secret, "", userID.String(), time.Hour))
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if p.UserID != userID {
+		t.Errorf("expected principal %v, got %v", userID, p.UserID)
+	}
+}
+
+func TestVerifier_RS256FromJWKS(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := auth.NewKeySet()
+	if err := keys.LoadJWKSFile(writeJWKS(t, "key-1", &priv.PublicKey)); err != nil {
+		t.Fatalf("LoadJWKSFile returned error: %v", err)
+	}
+	keys.AddHMAC("", secret)
+	verifier := auth.NewVerifier(keys, "", "")
+
+	userID := uuid.New()
+	p, err := verifier.Verify(mint(t, jwt.SigningMethodRS256, priv, "key-1", userID.String(), time.Hour))
+	if err != nil {
+		t.Fatalf("Verify returned error: %v", err)
+	}
+	if p.UserID != userID {
+		t.Errorf("expected principal %v, got %v", userID, p.UserID)
+	}
+
+	// An RS256 public key must never be accepted as an HS256 secret.
+	pubAsSecret := priv.PublicKey.N.Bytes()
+	if _, err := verifier.Verify(mint(t, jwt.SigningMethodHS256, pubAsSecret, "key-1", userID.String(), time.Hour)); err == nil {
+		t.Error("expected a token signed with the public key as HMAC secret to be rejected")
+	}
+}
+
+func TestVerifier_RejectsInvalidTokens(t *testing.T) {
+	userID := uuid.New().String()
+	verifier := hmacVerifier()
+
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.RegisteredClaims{
+		Subject:   userID,
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	noExpiry, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:  userID,
+		Issuer:   "test-issuer",
+		Audience: jwt.ClaimStrings{"assets"},
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherIssuer := hmacVerifierFor("someone-else")
+
+	tests := []struct {
+		name     string
+		verifier *auth.Verifier
+		token    string
+	}{
+		{"garbage", verifier, "not-a-token"},
+		{"wrong secret", verifier, mint(t, jwt.SigningMethodHS256, []byte("other"), "", userID, time.Hour)},
+		{"expired", verifier, mint(t, jwt.SigningMethodHS256, secret, "", userID, -time.Hour)},
+		{"alg none", verifier, unsigned},
+		{"no expiry", verifier, noExpiry},
+		{"subject not a user ID", verifier, mint(t, jwt.SigningMethodHS256, secret, "", "alice", time.Hour)},
+		{"wrong issuer", otherIssuer, mint(t, jwt.SigningMethodHS256, secret, "", userID, time.Hour)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.verifier.Verify(tt.token); !errors.Is(err, auth.ErrInvalidToken) {
+				t.Errorf("expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package auth_test
+
+import (
+	"assetsApp/internal/auth"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func TestMiddleware(t *testing.T) {
+	userID := uuid.New()
+	var seen auth.Principal
+
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(hmacVerifier(), nil))
+	router.HandleFunc("/users/{userId}/assets", func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = auth.FromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	valid := mint(t, jwt.SigningMethodHS256, secret, "", userID.String(), time.Hour)
+	tests := []struct {
+		name          string
+		user          string
+		authorization string
+		want          int
+	}{
+		{"no header", userID.String(), "", http.StatusUnauthorized},
+		{"wrong scheme", userID.String(), "Basic " + valid, http.StatusUnauthorized},
+		{"expired token", userID.String(), "Bearer " + mint(t, jwt.SigningMethodHS256, secret, "", userID.String(), -time.Hour), http.StatusUnauthorized},
+		{"another user", uuid.New().String(), "Bearer " + valid, http.StatusForbidden},
+		{"malformed user", "me", "Bearer " + valid, http.StatusForbidden},
+		{"own user", userID.String(), "Bearer " + valid, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/users/"+tt.user+"/assets", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, tt.want)
+			}
+			if tt.want == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected a WWW-Authenticate challenge")
+			}
+		})
+	}
+
+	if seen.UserID != userID {
+		t.Errorf("expected principal %v in the request context, got %v", userID, seen.UserID)
+	}
+}
+
+// stubKeys accepts the single key "good" with the given scopes.
+type stubKeys struct {
+	scopes []string
+	err    error
+}
+
+func (s stubKeys) VerifyAPIKey(_ context.Context, key string) (auth.Principal, error) {
+	if s.err != nil {
+		return auth.Principal{}, s.err
+	}
+	if key != "good" {
+		return auth.Principal{}, auth.ErrInvalidToken
+	}
+	return auth.Principal{APIKeyID: uuid.New(), Scopes: s.scopes}, nil
+}
+
+func newKeyRouter(keys auth.APIKeyVerifier) *mux.Router {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(hmacVerifier(), keys))
+	router.HandleFunc("/users/{userId}/assets", ok).Methods("GET", "POST")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares", ok).Methods("GET")
+	router.HandleFunc("/users/{userId}/favourites/{assetId}", ok).Methods("POST")
+	router.HandleFunc("/users/{userId}/shared", ok).Methods("GET")
+	router.HandleFunc("/users/{userId}/collections", ok).Methods("GET", "POST")
+	router.HandleFunc("/users/{userId}/tags", ok).Methods("GET")
+	router.HandleFunc("/admin/api-keys", ok).Methods("GET")
+	return router
+}
+
+func TestMiddleware_APIKeys(t *testing.T) {
+	router := newKeyRouter(stubKeys{scopes: []string{auth.ScopeAssetsRead, auth.ScopeFavouritesWrite}})
+	user := "/users/" + uuid.New().String()
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		want          int
+	}{
+		{"read assets of any user", "GET", user + "/assets", "ApiKey good", http.StatusOK},
+		{"read shares", "GET", user + "/assets/a1/shares", "ApiKey good", http.StatusOK},
+		{"read shared with me", "GET", user + "/shared", "ApiKey good", http.StatusOK},
+		{"read collections", "GET", user + "/collections", "ApiKey good", http.StatusOK},
+		{"write collections without scope", "POST", user + "/collections", "ApiKey good", http.StatusForbidden},
+		{"read tag cloud", "GET", user + "/tags", "ApiKey good", http.StatusOK},
+		{"write favourites", "POST", user + "/favourites/a1", "apikey good", http.StatusOK},
+		{"write assets without scope", "POST", user + "/assets", "ApiKey good", http.StatusForbidden},
+		{"admin route", "GET", "/admin/api-keys", "ApiKey good", http.StatusForbidden},
+		{"unknown key", "GET", user + "/assets", "ApiKey bad", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", tt.authorization)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_APIKeysDisabledOrUnavailable(t *testing.T) {
+	path := "/users/" + uuid.New().String() + "/assets"
+
+	for name, tt := range map[string]struct {
+		keys auth.APIKeyVerifier
+		want int
+	}{
+		"disabled":    {nil, http.StatusUnauthorized},
+		"unavailable": {stubKeys{err: errors.New("connection refused")}, http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "ApiKey good")
+		rr := httptest.NewRecorder()
+		newKeyRouter(tt.keys).ServeHTTP(rr, req)
+
+		if rr.Code != tt.want {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", name, rr.Code, tt.want)
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	admin, user := uuid.New().String(), uuid.New().String()
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(hmacVerifier(), nil))
+	adminRoutes := router.PathPrefix("/admin").Subrouter()
+	adminRoutes.Use(auth.RequireRole("admin"))
+	adminRoutes.HandleFunc("/api-keys", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	adminToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   admin,
+		"iss":   "test-issuer",
+		"aud":   "assets",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	}).SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for token, want := range map[string]int{
+		adminToken: http.StatusOK,
+		mint(t, jwt.SigningMethodHS256, secret, "", user, time.Hour): http.StatusForbidden,
+	} {
+		req := httptest.NewRequest("GET", "/admin/api-keys", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != want {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, want)
+		}
+	}
+}
+
+func TestMiddleware_AdminRoutesAreNotBoundToTheTokenUser(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router := mux.NewRouter()
+	router.Use(auth.Middleware(hmacVerifier(), stubKeys{scopes: auth.Scopes}))
+	router.HandleFunc("/admin/users/{userId}/assets", ok).Methods("GET")
+
+	path := "/admin/users/" + uuid.New().String() + "/assets"
+	for authorization, want := range map[string]int{
+		"Bearer " + mint(t, jwt.SigningMethodHS256, secret, "", uuid.New().String(), time.Hour): http.StatusOK,
+		"ApiKey good": http.StatusForbidden,
+	} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", authorization)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != want {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", strings.Fields(authorization)[0], rr.Code, want)
+		}
+	}
+}
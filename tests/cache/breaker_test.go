@@ -0,0 +1,153 @@
+package cache_test
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+var errRedisDown = errors.New("redis down")
+
+func TestBreakerCache_OpensAfterFailuresAndClosesOnceBack(t *testing.T) {
+	ctx := context.Background()
+	remote := mocks.NewMockCache()
+	breaker := storage.NewBreakerCache(remote, storage.BreakerOptions{Failures: 3, Cooldown: 50 * time.Millisecond})
+
+	remote.Fail(errRedisDown)
+	for i := 0; i < 3; i++ {
+		_, err := breaker.Get(ctx, "a")
+		assert.ErrorIs(t, err, errRedisDown)
+	}
+	assert.True(t, breaker.Open())
+	_, err := breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrCacheUnavailable)
+	assert.ErrorIs(t, breaker.Del(ctx, "a"), storage.ErrCacheUnavailable)
+	assert.Equal(t, 3, remote.Gets(), "an open breaker should not call the cache")
+
+	// After the cooldown one call probes; it fails, so the breaker reopens.
+	time.Sleep(60 * time.Millisecond)
+	_, err = breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, errRedisDown)
+	_, err = breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrCacheUnavailable)
+
+	remote.Fail(nil)
+	time.Sleep(60 * time.Millisecond)
+	assert.NoError(t, breaker.Set(ctx, "a", "1"))
+	assert.False(t, breaker.Open())
+	value, err := breaker.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+}
+
+func TestBreakerCache_SlowCallsCountAsFailures(t *testing.T) {
+	ctx := context.Background()
+	remote := mocks.NewMockCache()
+	remote.Slow(time.Second)
+	breaker := storage.NewBreakerCache(remote, storage.BreakerOptions{Failures: 1, Cooldown: time.Minute, Timeout: 10 * time.Millisecond})
+
+	began := time.Now()
+	_, err := breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, storage.ErrCacheUnavailable)
+	assert.Less(t, time.Since(began), 500*time.Millisecond)
+}
+
+func TestBreakerCache_MissesAndCancelledCallsDontCount(t *testing.T) {
+	remote := mocks.NewMockCache()
+	breaker := storage.NewBreakerCache(remote, storage.BreakerOptions{Failures: 1, Cooldown: time.Minute})
+
+	value, err := breaker.Get(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	remote.Slow(time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = breaker.Get(ctx, "a")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, breaker.Open())
+}
+
+func TestCachedStore_FailedInvalidationsAreRepaired(t *testing.T) {
+	ctx := context.Background()
+	owner, fan := uuid.New(), uuid.New()
+	store, db, cache := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.AddFavourite(ctx, fan, "insight-1", "insight"))
+	assert.NoError(t, store.AddFavourite(ctx, fan, "insight-2", "insight"))
+
+	favourites := func() []models.Favourite {
+		t.Helper()
+		page, err := store.GetFavourites(ctx, fan, storage.ListOptions{})
+		assert.NoError(t, err)
+		return page.Items
+	}
+	assert.Len(t, favourites(), 2)
+
+	// Redis is down while the asset changes and a favourite is removed, so
+	// neither invalidation gets through. Redis comes back holding the old
+	// page.
+	cache.Fail(errRedisDown)
+	assert.NoError(t, store.Update(ctx, owner, &models.Insight{ID: "insight-1", Description: "edited"}))
+	assert.NoError(t, store.RemoveFavourite(ctx, fan, "insight-2"))
+	cache.Fail(nil)
+	assert.True(t, store.Degraded())
+
+	reads := db.getFavourites.Load()
+	items := favourites()
+	if assert.Len(t, items, 1, "a degraded store should bypass the stale cache") {
+		assert.Equal(t, "edited", items[0].Asset.(*models.Insight).Description)
+	}
+	assert.Equal(t, reads+1, db.getFavourites.Load())
+
+	assert.NoError(t, store.Repair(ctx))
+	assert.False(t, store.Degraded())
+	assert.NotContains(t, cache.Keys(), "favourites:"+fan.String())
+	assert.Len(t, favourites(), 1)
+	reads = db.getFavourites.Load()
+	assert.Len(t, favourites(), 1)
+	assert.Equal(t, reads, db.getFavourites.Load(), "once repaired the cache should be used again")
+}
+
+func TestCachedStore_RepairKeepsFailedInvalidationsQueued(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, _, cache := newCachedStore(t, owner, storage.CacheOptions{})
+
+	cache.Fail(errRedisDown)
+	assert.NoError(t, store.AddTag(ctx, owner, "insight-1", "EMEA"))
+	assert.ErrorIs(t, store.Repair(ctx), errRedisDown)
+	assert.True(t, store.Degraded())
+
+	cache.Fail(nil)
+	assert.NoError(t, store.Repair(ctx))
+	assert.False(t, store.Degraded())
+}
+
+func TestCachedStore_ServesFromDatabaseWhileBreakerIsOpen(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	db := &countingStore{MemoryStore: storage.NewMemoryStore()}
+	assert.NoError(t, db.Add(ctx, owner, &models.Insight{ID: "insight-1", Description: "first"}))
+	remote := mocks.NewMockCache()
+	remote.Slow(time.Second)
+	breaker := storage.NewBreakerCache(remote, storage.BreakerOptions{Failures: 1, Cooldown: time.Minute, Timeout: 10 * time.Millisecond})
+	store := storage.NewCachedStore(db, breaker, storage.CacheOptions{})
+
+	began := time.Now()
+	for i := 0; i < 5; i++ {
+		asset, err := store.GetByID(ctx, owner, "insight-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "first", asset.(*models.Insight).Description)
+	}
+	assert.Less(t, time.Since(began), 500*time.Millisecond, "reads should not wait on a cache that is down")
+	assert.True(t, breaker.Open())
+}
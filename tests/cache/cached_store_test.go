@@ -0,0 +1,192 @@
+package cache_test
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"assetsApp/tests/mocks"
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingStore is a MemoryStore that counts the reads reaching it. Reads
+// of favourites take delay, like a slow query.
+type countingStore struct {
+	*storage.MemoryStore
+	gets          atomic.Int64
+	getByIDs      atomic.Int64
+	getFavourites atomic.Int64
+	delay         time.Duration
+	// afterGetFavourites, if set, runs once a favourites page has been read
+	afterGetFavourites func()
+}
+
+func (s *countingStore) Get(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.AssetPage, error) {
+	s.gets.Add(1)
+	return s.MemoryStore.Get(ctx, userID, opts)
+}
+
+func (s *countingStore) GetByID(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	s.getByIDs.Add(1)
+	return s.MemoryStore.GetByID(ctx, userID, assetID)
+}
+
+func (s *countingStore) GetFavourites(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.FavouritePage, error) {
+	s.getFavourites.Add(1)
+	time.Sleep(s.delay)
+	page, err := s.MemoryStore.GetFavourites(ctx, userID, opts)
+	if s.afterGetFavourites != nil {
+		s.afterGetFavourites()
+	}
+	return page, err
+}
+
+// newCachedStore returns a CachedStore over a counting MemoryStore in which
+// owner has the insights "insight-1" and "insight-2".
+func newCachedStore(t *testing.T, owner uuid.UUID, opts storage.CacheOptions) (*storage.CachedStore, *countingStore, *mocks.MockCache) {
+	t.Helper()
+	db := &countingStore{MemoryStore: storage.NewMemoryStore()}
+	for _, id := range []string{"insight-1", "insight-2"} {
+		if err := db.Add(context.Background(), owner, &models.Insight{ID: id, Description: "first"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cache := mocks.NewMockCache()
+	return storage.NewCachedStore(db, cache, opts), db, cache
+}
+
+func assetIDs(assets []models.Asset) []string {
+	ids := []string{}
+	for _, asset := range assets {
+		ids = append(ids, asset.GetID())
+	}
+	return ids
+}
+
+func TestCachedStore_ListingsReadThrough(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{})
+
+	for i := 0; i < 2; i++ {
+		page, err := store.Get(ctx, owner, storage.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"insight-1", "insight-2"}, assetIDs(page.Items))
+	}
+	_, err := store.Get(ctx, owner, storage.ListOptions{Limit: storage.DefaultPageLimit, Sort: storage.SortByID})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, db.gets.Load(), "the same page should be read from the database once")
+
+	page, err := store.Get(ctx, owner, storage.ListOptions{Limit: 1, Sort: storage.SortByTitle, Desc: true})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.EqualValues(t, 2, db.gets.Load(), "another page is cached separately")
+
+	assert.NoError(t, store.Add(ctx, owner, &models.Insight{ID: "insight-3"}))
+	page, err = store.Get(ctx, owner, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insight-1", "insight-2", "insight-3"}, assetIDs(page.Items))
+
+	assert.NoError(t, store.Remove(ctx, owner, "insight-1", storage.AnyVersion))
+	page, err = store.Get(ctx, owner, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insight-2", "insight-3"}, assetIDs(page.Items))
+}
+
+func TestCachedStore_TagAndCollectionListingsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.AddTag(ctx, owner, "insight-1", "EMEA"))
+
+	for i := 0; i < 2; i++ {
+		page, err := store.Get(ctx, owner, storage.ListOptions{Tags: []string{"EMEA"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"insight-1"}, assetIDs(page.Items))
+	}
+	assert.EqualValues(t, 2, db.gets.Load())
+
+	assert.NoError(t, store.AddTag(ctx, owner, "insight-2", "EMEA"))
+	page, err := store.Get(ctx, owner, storage.ListOptions{Tags: []string{"EMEA"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insight-1", "insight-2"}, assetIDs(page.Items))
+}
+
+func TestCachedStore_WritesAssetsThrough(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{})
+
+	assert.NoError(t, store.Add(ctx, owner, &models.Insight{ID: "insight-3", Description: "new"}))
+	asset, err := store.GetByID(ctx, owner, "insight-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", asset.(*models.Insight).Description)
+	assert.EqualValues(t, 0, db.getByIDs.Load(), "an added asset should be served from the cache")
+
+	assert.NoError(t, store.Update(ctx, owner, &models.Insight{ID: "insight-3", Description: "updated"}))
+	reads := db.getByIDs.Load()
+	asset, err = store.GetByID(ctx, owner, "insight-3")
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", asset.(*models.Insight).Description)
+	assert.EqualValues(t, 2, asset.GetVersion())
+	assert.Equal(t, reads, db.getByIDs.Load(), "an updated asset should be served from the cache")
+
+	assert.NoError(t, store.Remove(ctx, owner, "insight-3", storage.AnyVersion))
+	_, err = store.GetByID(ctx, owner, "insight-3")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestCachedStore_ChangesDropEveryFavouritesPageHoldingTheAsset(t *testing.T) {
+	ctx := context.Background()
+	owner, fan, other := uuid.New(), uuid.New(), uuid.New()
+	store, db, cache := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.Add(ctx, other, &models.Insight{ID: "other-insight", Description: "other"}))
+	for _, user := range []uuid.UUID{owner, fan} {
+		assert.NoError(t, store.AddFavourite(ctx, user, "insight-1", "insight"))
+	}
+	assert.NoError(t, store.AddFavourite(ctx, other, "other-insight", "insight"))
+
+	description := func(user uuid.UUID) string {
+		t.Helper()
+		page, err := store.GetFavourites(ctx, user, storage.ListOptions{})
+		assert.NoError(t, err)
+		if len(page.Items) == 0 {
+			return ""
+		}
+		return page.Items[0].Asset.(*models.Insight).Description
+	}
+	for _, user := range []uuid.UUID{owner, fan, other} {
+		description(user)
+	}
+	reads := db.getFavourites.Load()
+	assert.Equal(t, "first", description(fan))
+	assert.Equal(t, reads, db.getFavourites.Load(), "favourites should be served from the cache")
+
+	assert.NoError(t, store.Update(ctx, owner, &models.Insight{ID: "insight-1", Description: "updated"}))
+	assert.Equal(t, "updated", description(owner))
+	assert.Equal(t, "updated", description(fan))
+	assert.Contains(t, cache.Keys(), "favourites:"+other.String(), "unrelated favourites should stay cached")
+
+	assert.NoError(t, store.Remove(ctx, owner, "insight-1", storage.AnyVersion))
+	assert.Equal(t, "", description(fan))
+}
+
+func TestCachedStore_PurgeUserDropsOtherUsersFavourites(t *testing.T) {
+	ctx := context.Background()
+	owner, fan := uuid.New(), uuid.New()
+	store, _, _ := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.AddFavourite(ctx, fan, "insight-2", "insight"))
+
+	page, err := store.GetFavourites(ctx, fan, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+
+	assert.NoError(t, store.PurgeUser(ctx, owner))
+	page, err = store.GetFavourites(ctx, fan, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
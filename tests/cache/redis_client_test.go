@@ -0,0 +1,235 @@
+package cache_test
+
+import (
+	"assetsApp/internal/storage"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRedisClient_PicksTopology(t *testing.T) {
+	tests := []struct {
+		name string
+		opts storage.RedisOptions
+		want interface{}
+	}{
+		{"single server", storage.RedisOptions{Addrs: []string{"redis:6380"}, DB: 2}, &redis.Client{}},
+		{"sentinel", storage.RedisOptions{Addrs: []string{"s1:26379", "s2:26379"}, MasterName: "cache"}, &redis.Client{}},
+		{"cluster", storage.RedisOptions{Addrs: []string{"node:7000"}, Cluster: true}, &redis.ClusterClient{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := storage.NewRedisClient(tt.opts)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer client.Client.Close()
+			assert.IsType(t, tt.want, client.Client)
+			assert.Equal(t, 5*time.Minute, client.TTL)
+		})
+	}
+
+	client, err := storage.NewRedisClient(storage.RedisOptions{Addrs: []string{"redis:6380"}, DB: 2, PoolSize: 7, TTL: time.Hour})
+	assert.NoError(t, err)
+	defer client.Client.Close()
+	opts := client.Client.(*redis.Client).Options()
+	assert.Equal(t, "redis:6380", opts.Addr)
+	assert.Equal(t, 2, opts.DB)
+	assert.Equal(t, 7, opts.PoolSize)
+	assert.Equal(t, time.Hour, client.TTL)
+}
+
+func TestNewRedisClient_RejectsInconsistentOptions(t *testing.T) {
+	missingCA := filepath.Join(t.TempDir(), "missing.pem")
+	emptyCA := filepath.Join(t.TempDir(), "empty.pem")
+	assert.NoError(t, os.WriteFile(emptyCA, []byte("not a certificate"), 0o600))
+
+	for name, opts := range map[string]storage.RedisOptions{
+		"sentinel and cluster":         {Addrs: []string{"a:1"}, MasterName: "cache", Cluster: true},
+		"cluster with a database":      {Addrs: []string{"a:1"}, Cluster: true, DB: 1},
+		"several addresses":            {Addrs: []string{"a:1", "b:1"}},
+		"negative TTL":                 {TTL: -time.Second},
+		"TLS settings without TLS":     {TLSServerName: "redis"},
+		"unreadable CA file":           {TLS: true, TLSCAFile: missingCA},
+		"CA file without certificates": {TLS: true, TLSCAFile: emptyCA},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := storage.NewRedisClient(opts)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRedisClient_DelAcrossClusterSlots(t *testing.T) {
+	ctx := context.Background()
+	node := newFakeClusterNode(t)
+	client, err := storage.NewRedisClient(storage.RedisOptions{Addrs: []string{node.addr}, Cluster: true})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer client.Client.Close()
+
+	keys := []string{"favourites:a", "favourites:b", "asset:a:chart-1", "asset-listings:a"}
+	assert.NotEqual(t, clusterSlot(keys[0]), clusterSlot(keys[1]))
+	for _, key := range keys {
+		assert.NoError(t, client.Set(ctx, key, "1"))
+	}
+	assert.ErrorContains(t, client.Client.Del(ctx, keys...).Err(), "CROSSSLOT", "the node should enforce slots")
+
+	assert.NoError(t, client.Del(ctx, keys...))
+	for _, key := range keys {
+		value, err := client.Get(ctx, key)
+		assert.NoError(t, err)
+		assert.Equal(t, "", value, key)
+	}
+}
+
+// fakeClusterNode is a one-node Redis Cluster serving every slot. It knows
+// just enough commands for RedisClient's Get, Set and Del, and, like Redis,
+// refuses a DEL whose keys hash to different slots.
+type fakeClusterNode struct {
+	addr   string
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func newFakeClusterNode(t *testing.T) *fakeClusterNode {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	node := &fakeClusterNode{addr: ln.Addr().String(), values: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go node.serve(conn)
+		}
+	}()
+	return node
+}
+
+func (n *fakeClusterNode) serve(conn net.Conn) {
+	defer conn.Close()
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		n.reply(w, args)
+		if r.Buffered() == 0 {
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (n *fakeClusterNode) reply(w *bufio.Writer, args []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "PING":
+		w.WriteString("+PONG\r\n")
+	case "CLUSTER":
+		host, port, _ := net.SplitHostPort(n.addr)
+		fmt.Fprintf(w, "*1\r\n*3\r\n:0\r\n:16383\r\n*3\r\n$%d\r\n%s\r\n:%s\r\n$4\r\nnode\r\n", len(host), host, port)
+	case "SET":
+		n.values[args[1]] = args[2]
+		w.WriteString("+OK\r\n")
+	case "GET":
+		if value, ok := n.values[args[1]]; ok {
+			fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+		} else {
+			w.WriteString("$-1\r\n")
+		}
+	case "DEL":
+		for _, key := range args[2:] {
+			if clusterSlot(key) != clusterSlot(args[1]) {
+				w.WriteString("-CROSSSLOT Keys in request don't hash to the same slot\r\n")
+				return
+			}
+		}
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := n.values[key]; ok {
+				delete(n.values, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	default:
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+// readCommand reads one command, sent as an array of bulk strings.
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected %q", line)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+// clusterSlot is the slot Redis Cluster assigns a key: CRC16 of the key, or
+// of its {hash tag}, modulo 16384.
+func clusterSlot(key string) int {
+	if start := strings.IndexByte(key, '{'); start >= 0 {
+		if end := strings.IndexByte(key[start+1:], '}'); end > 0 {
+			key = key[start+1 : start+1+end]
+		}
+	}
+	var crc uint16
+	for i := 0; i < len(key); i++ {
+		crc ^= uint16(key[i]) << 8
+		for bit := 0; bit < 8; bit++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return int(crc) % 16384
+}
@@ -0,0 +1,137 @@
+package cache_test
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// hammer reads the user's favourites from n goroutines at once and returns
+// the description of the first favourite each of them saw, along with the
+// longest a read took.
+func hammer(t *testing.T, store storage.AssetStore, userID uuid.UUID, n int) ([]string, time.Duration) {
+	t.Helper()
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		seen    []string
+		slowest time.Duration
+	)
+	start := make(chan struct{})
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			began := time.Now()
+			page, err := store.GetFavourites(context.Background(), userID, storage.ListOptions{})
+			took := time.Since(began)
+			if !assert.NoError(t, err) || !assert.Len(t, page.Items, 1) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, page.Items[0].Asset.(*models.Insight).Description)
+			if took > slowest {
+				slowest = took
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return seen, slowest
+}
+
+func TestCachedStore_ConcurrentMissesShareOneLoad(t *testing.T) {
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.AddFavourite(context.Background(), owner, "insight-1", "insight"))
+	db.delay = 50 * time.Millisecond
+
+	seen, _ := hammer(t, store, owner, 50)
+	assert.Len(t, seen, 50)
+	assert.EqualValues(t, 1, db.getFavourites.Load(), "concurrent misses should share one load")
+
+	hammer(t, store, owner, 50)
+	assert.EqualValues(t, 1, db.getFavourites.Load(), "later reads should be served from the cache")
+}
+
+func TestCachedStore_ServesStaleWhileOneLoadRefreshes(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{SoftTTL: 20 * time.Millisecond})
+	assert.NoError(t, store.AddFavourite(ctx, owner, "insight-1", "insight"))
+	_, err := store.GetFavourites(ctx, owner, storage.ListOptions{})
+	assert.NoError(t, err)
+
+	// Change the asset behind the cache's back and let the entry go stale.
+	assert.NoError(t, db.MemoryStore.Update(ctx, owner, &models.Insight{ID: "insight-1", Description: "edited"}))
+	time.Sleep(30 * time.Millisecond)
+	db.delay = 200 * time.Millisecond
+
+	seen, slowest := hammer(t, store, owner, 50)
+	for _, description := range seen {
+		assert.Equal(t, "first", description, "a stale entry should be served while it is refreshed")
+	}
+	assert.Less(t, slowest, db.delay, "no read should wait for the refresh")
+
+	assert.Eventually(t, func() bool {
+		page, err := store.GetFavourites(ctx, owner, storage.ListOptions{})
+		return err == nil && len(page.Items) == 1 && page.Items[0].Asset.(*models.Insight).Description == "edited"
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.EqualValues(t, 2, db.getFavourites.Load(), "one background load should refresh the stale entry")
+}
+
+func TestCachedStore_EarlyExpiry(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+
+	// Without early expiry an entry is fresh for the whole SoftTTL.
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{SoftTTL: time.Hour})
+	assert.NoError(t, store.AddFavourite(ctx, owner, "insight-1", "insight"))
+	db.delay = time.Millisecond
+	hammer(t, store, owner, 20)
+	hammer(t, store, owner, 20)
+	assert.EqualValues(t, 1, db.getFavourites.Load())
+
+	// An entry that took 1ms to load, weighed a million million times,
+	// is due long before an hour is up.
+	store, db, _ = newCachedStore(t, owner, storage.CacheOptions{SoftTTL: time.Hour, EarlyExpiry: 1e12})
+	assert.NoError(t, store.AddFavourite(ctx, owner, "insight-1", "insight"))
+	db.delay = time.Millisecond
+	hammer(t, store, owner, 20)
+	assert.EqualValues(t, 1, db.getFavourites.Load())
+	hammer(t, store, owner, 20)
+	assert.Eventually(t, func() bool { return db.getFavourites.Load() >= 2 }, 2*time.Second, 5*time.Millisecond,
+		"an entry due for early expiry should be refreshed in the background")
+}
+
+func TestCachedStore_LoadInvalidatedMidwayIsNotCached(t *testing.T) {
+	ctx := context.Background()
+	owner := uuid.New()
+	store, db, _ := newCachedStore(t, owner, storage.CacheOptions{})
+	assert.NoError(t, store.AddFavourite(ctx, owner, "insight-1", "insight"))
+
+	// The asset changes after the load has read the page but before it is
+	// written back.
+	var once sync.Once
+	db.afterGetFavourites = func() {
+		once.Do(func() {
+			assert.NoError(t, store.Update(ctx, owner, &models.Insight{ID: "insight-1", Description: "edited"}))
+		})
+	}
+	page, err := store.GetFavourites(ctx, owner, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "first", page.Items[0].Asset.(*models.Insight).Description)
+
+	page, err = store.GetFavourites(ctx, owner, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "edited", page.Items[0].Asset.(*models.Insight).Description, "the page read before the change should not be cached")
+	assert.EqualValues(t, 2, db.getFavourites.Load())
+}
@@ -0,0 +1,149 @@
+package cache_test
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"assetsApp/tests/mocks"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTieredCache_ServesRepeatedReadsLocally(t *testing.T) {
+	ctx := context.Background()
+	remote := mocks.NewMockCache()
+	tiered := storage.NewTieredCache(remote, nil, 10, time.Minute)
+
+	assert.NoError(t, remote.Set(ctx, "a", "1"))
+	for i := 0; i < 3; i++ {
+		value, err := tiered.Get(ctx, "a")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", value)
+	}
+	assert.Equal(t, 1, remote.Gets(), "only the first read should reach the shared cache")
+
+	assert.NoError(t, tiered.Set(ctx, "b", "2"))
+	value, err := tiered.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+	assert.Equal(t, 1, remote.Gets(), "a value just written should be served locally")
+
+	assert.NoError(t, tiered.Del(ctx, "a"))
+	value, err = tiered.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+}
+
+func TestTieredCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	remote := mocks.NewMockCache()
+	tiered := storage.NewTieredCache(remote, nil, 2, time.Minute)
+
+	assert.NoError(t, tiered.Set(ctx, "a", "1"))
+	assert.NoError(t, tiered.Set(ctx, "b", "2"))
+	_, _ = tiered.Get(ctx, "a")
+	assert.NoError(t, tiered.Set(ctx, "c", "3"))
+
+	for _, key := range []string{"a", "c"} {
+		_, err := tiered.Get(ctx, key)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 0, remote.Gets(), "recently used entries should stay local")
+
+	value, err := tiered.Get(ctx, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value)
+	assert.Equal(t, 1, remote.Gets(), "the least recently used entry should have been evicted")
+}
+
+func TestTieredCache_LocalEntriesExpire(t *testing.T) {
+	ctx := context.Background()
+	remote := mocks.NewMockCache()
+	tiered := storage.NewTieredCache(remote, nil, 10, 20*time.Millisecond)
+
+	assert.NoError(t, tiered.Set(ctx, "a", "1"))
+	_, _ = tiered.Get(ctx, "a")
+	assert.Equal(t, 0, remote.Gets())
+
+	time.Sleep(30 * time.Millisecond)
+	value, err := tiered.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+	assert.Equal(t, 1, remote.Gets(), "an expired local entry should be read again from the shared cache")
+}
+
+func TestTieredCache_RemoveFavouriteEvictsOnEveryReplica(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	owner, fan := uuid.New(), uuid.New()
+
+	// Two replicas sharing a database, Redis and its pub/sub.
+	db := storage.NewMemoryStore()
+	assert.NoError(t, db.Add(ctx, owner, &models.Insight{ID: "insight-1", Description: "first"}))
+	remote, bus := mocks.NewMockCache(), mocks.NewMockBus()
+	replica := func() *storage.CachedStore {
+		tiered := storage.NewTieredCache(remote, bus, 100, time.Minute)
+		go func() { _ = tiered.Listen(ctx) }()
+		return storage.NewCachedStore(db, tiered, storage.CacheOptions{})
+	}
+	a, b := replica(), replica()
+	assert.Eventually(t, func() bool { return bus.Subscribers() == 2 }, time.Second, time.Millisecond)
+
+	assert.NoError(t, a.AddFavourite(ctx, fan, "insight-1", "insight"))
+	page, err := b.GetFavourites(ctx, fan, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	gets := remote.Gets()
+	page, err = b.GetFavourites(ctx, fan, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, gets, remote.Gets(), "replica b should serve the favourites from its local tier")
+
+	assert.NoError(t, a.RemoveFavourite(ctx, fan, "insight-1"))
+	page, err = b.GetFavourites(ctx, fan, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items, "removing on replica a should evict replica b's local copy")
+}
+
+// hookedCache runs afterGet once a Get has read its value.
+type hookedCache struct {
+	*mocks.MockCache
+	afterGet func()
+}
+
+func (c *hookedCache) Get(ctx context.Context, key string) (string, error) {
+	value, err := c.MockCache.Get(ctx, key)
+	if c.afterGet != nil {
+		c.afterGet()
+	}
+	return value, err
+}
+
+func TestTieredCache_InvalidationDuringAReadIsNotUndone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	remote, bus := &hookedCache{MockCache: mocks.NewMockCache()}, mocks.NewMockBus()
+	a := storage.NewTieredCache(remote, bus, 10, time.Minute)
+	b := storage.NewTieredCache(remote, bus, 10, time.Minute)
+	go func() { _ = b.Listen(ctx) }()
+	assert.Eventually(t, func() bool { return bus.Subscribers() == 1 }, time.Second, time.Millisecond)
+	assert.NoError(t, remote.Set(ctx, "a", "1"))
+
+	// Replica a writes the key after b has read it from remote, but before b
+	// fills its local tier.
+	var once sync.Once
+	remote.afterGet = func() {
+		once.Do(func() { assert.NoError(t, a.Set(ctx, "a", "2")) })
+	}
+	value, err := b.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "1", value)
+
+	value, err = b.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", value, "b should not have kept the value the invalidation replaced")
+}
@@ -0,0 +1,147 @@
+// Package contract holds behaviour every storage.AssetStore must share. Each
+// suite is written once and run against every store implementation.
+package contract
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Favourites checks how a store adds, removes, lists and orders favourites.
+// newStore is called for every subtest and must return a store without data.
+func Favourites(t *testing.T, newStore func(t *testing.T) storage.AssetStore) {
+	ctx := context.Background()
+
+	// setup returns a fresh store in which owner has the charts chart1 to
+	// chart3.
+	setup := func(t *testing.T) (storage.AssetStore, uuid.UUID) {
+		t.Helper()
+		store := newStore(t)
+		owner := uuid.New()
+		for _, id := range []string{"chart1", "chart2", "chart3"} {
+			if !assert.NoError(t, store.Add(ctx, owner, &models.Chart{ID: id})) {
+				t.FailNow()
+			}
+		}
+		return store, owner
+	}
+	ids := func(t *testing.T, store storage.AssetStore, userID uuid.UUID) []string {
+		t.Helper()
+		page, err := store.GetFavourites(ctx, userID, storage.ListOptions{})
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, fav := range page.Items {
+			ids = append(ids, fav.Asset.GetID())
+		}
+		return ids
+	}
+
+	t.Run("adding again conflicts and keeps one favourite", func(t *testing.T) {
+		store, owner := setup(t)
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.ErrorIs(t, store.AddFavourite(ctx, owner, "chart1", "chart"), storage.ErrConflict)
+		assert.Equal(t, []string{"chart1"}, ids(t, store, owner))
+	})
+
+	t.Run("adding a missing asset is not found", func(t *testing.T) {
+		store, owner := setup(t)
+		assert.ErrorIs(t, store.AddFavourite(ctx, owner, "missing", "chart"), storage.ErrNotFound)
+		assert.Empty(t, ids(t, store, owner))
+	})
+
+	t.Run("removing twice is not found the second time", func(t *testing.T) {
+		store, owner := setup(t)
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.NoError(t, store.RemoveFavourite(ctx, owner, "chart1"))
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, owner, "chart1"), storage.ErrNotFound)
+		assert.Empty(t, ids(t, store, owner))
+	})
+
+	t.Run("removing a favourite that was never added is not found", func(t *testing.T) {
+		store, owner := setup(t)
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, owner, "chart1"), storage.ErrNotFound)
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, owner, "missing"), storage.ErrNotFound)
+	})
+
+	t.Run("favourites belong to one user", func(t *testing.T) {
+		store, owner := setup(t)
+		other := uuid.New()
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, other, "chart1", "chart"))
+		assert.NoError(t, store.RemoveFavourite(ctx, other, "chart1"))
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, other, "chart1"), storage.ErrNotFound)
+		assert.Equal(t, []string{"chart1"}, ids(t, store, owner))
+		assert.Empty(t, ids(t, store, other))
+	})
+
+	t.Run("new favourites go to the end", func(t *testing.T) {
+		store, owner := setup(t)
+		for _, id := range []string{"chart3", "chart1", "chart2"} {
+			assert.NoError(t, store.AddFavourite(ctx, owner, id, "chart"))
+		}
+		assert.NoError(t, store.RemoveFavourite(ctx, owner, "chart3"))
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart3", "chart"))
+		assert.Equal(t, []string{"chart1", "chart2", "chart3"}, ids(t, store, owner))
+
+		page, err := store.GetFavourites(ctx, owner, storage.ListOptions{})
+		assert.NoError(t, err)
+		for i, want := range []int{2, 3, 4} {
+			fav := page.Items[i]
+			assert.Equal(t, want, fav.Position, fav.Asset.GetID())
+			assert.Equal(t, owner, fav.UserID)
+			assert.False(t, fav.CreatedAt.IsZero())
+			assert.False(t, fav.Pinned)
+			assert.Empty(t, fav.Note)
+		}
+	})
+
+	t.Run("removing an asset drops every user's favourite of it", func(t *testing.T) {
+		store, owner := setup(t)
+		fan := uuid.New()
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, fan, "chart1", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, fan, "chart2", "chart"))
+
+		assert.NoError(t, store.Remove(ctx, owner, "chart1", storage.AnyVersion))
+		assert.Empty(t, ids(t, store, owner))
+		assert.Equal(t, []string{"chart2"}, ids(t, store, fan))
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, fan, "chart1"), storage.ErrNotFound)
+	})
+
+	t.Run("reassigning drops the previous owner's favourite", func(t *testing.T) {
+		store, owner := setup(t)
+		newOwner, fan := uuid.New(), uuid.New()
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart2", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, fan, "chart1", "chart"))
+
+		previous, err := store.Reassign(ctx, "chart1", newOwner)
+		assert.NoError(t, err)
+		assert.Equal(t, owner, previous)
+		assert.Equal(t, []string{"chart2"}, ids(t, store, owner))
+		assert.Equal(t, []string{"chart1"}, ids(t, store, fan))
+		assert.ErrorIs(t, store.RemoveFavourite(ctx, owner, "chart1"), storage.ErrNotFound)
+	})
+
+	t.Run("moving and updating need existing favourites", func(t *testing.T) {
+		store, owner := setup(t)
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart1", "chart"))
+		assert.NoError(t, store.AddFavourite(ctx, owner, "chart2", "chart"))
+		pinned := true
+
+		assert.ErrorIs(t, store.MoveFavourite(ctx, owner, "chart3", "chart1", false), storage.ErrNotFound)
+		assert.ErrorIs(t, store.MoveFavourite(ctx, owner, "chart1", "chart3", true), storage.ErrNotFound)
+		assert.ErrorIs(t, store.MoveFavourite(ctx, owner, "chart1", "chart1", true), storage.ErrValidation)
+		assert.ErrorIs(t, store.UpdateFavourite(ctx, owner, "chart3", models.FavouriteUpdate{Pinned: &pinned}), storage.ErrNotFound)
+
+		assert.NoError(t, store.MoveFavourite(ctx, owner, "chart1", "chart2", true))
+		assert.Equal(t, []string{"chart2", "chart1"}, ids(t, store, owner))
+		assert.NoError(t, store.UpdateFavourite(ctx, owner, "chart1", models.FavouriteUpdate{Pinned: &pinned}))
+		assert.Equal(t, []string{"chart1", "chart2"}, ids(t, store, owner))
+	})
+}
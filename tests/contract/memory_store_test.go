@@ -0,0 +1,13 @@
+package contract_test
+
+import (
+	"assetsApp/internal/storage"
+	"assetsApp/tests/contract"
+	"testing"
+)
+
+func TestMemoryStore_Favourites(t *testing.T) {
+	contract.Favourites(t, func(t *testing.T) storage.AssetStore {
+		return storage.NewMemoryStore()
+	})
+}
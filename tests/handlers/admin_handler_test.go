@@ -0,0 +1,184 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	favouriteServices "assetsApp/internal/services/favourite"
+	shareServices "assetsApp/internal/services/share"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newAdminRouter mounts the admin routes, without the auth middleware, next
+// to the user routes the assertions need, all on store.
+func newAdminRouter(store storage.AssetStore) *mux.Router {
+	assetService := assetServices.NewAssetService(store)
+	assetHandler := handlers.NewAssetHandler(assetService)
+	favouriteHandler := handlers.NewFavouriteHandler(favouriteServices.NewFavouriteService(store))
+	shareHandler := handlers.NewShareHandler(shareServices.NewShareService(store))
+	adminHandler := handlers.NewAdminHandler(assetService)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
+	router.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares", shareHandler.GetShares).Methods("GET")
+
+	admin := router.PathPrefix("/admin").Subrouter()
+	admin.HandleFunc("/users", adminHandler.GetUsers).Methods("GET")
+	admin.HandleFunc("/users/{userId}", adminHandler.PurgeUser).Methods("DELETE")
+	admin.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	admin.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	admin.HandleFunc("/assets/{assetId}/owner", adminHandler.ReassignAsset).Methods("PUT")
+	return router
+}
+
+func TestAdminHandler_GetUsers(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	if err := store.Add(ctx, alice, &models.Insight{ID: "insight-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddFavourite(ctx, bob, "insight-1", "insight"); err != nil {
+		t.Fatal(err)
+	}
+	router := newAdminRouter(store)
+
+	rr := serve(t, router, "GET", "/admin/users?limit=1", "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var page storage.UserPage
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Items) != 1 || page.NextCursor == "" {
+		t.Fatalf("expected one user and a cursor, got %+v", page)
+	}
+
+	rr = serve(t, router, "GET", "/admin/users?limit=1&cursor="+page.NextCursor, "", nil)
+	var rest storage.UserPage
+	if err := json.NewDecoder(rr.Body).Decode(&rest); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(rest.Items) != 1 || rest.NextCursor != "" {
+		t.Fatalf("expected the last user, got %+v", rest)
+	}
+	got := map[uuid.UUID]bool{page.Items[0].ID: true, rest.Items[0].ID: true}
+	if !got[alice] || !got[bob] {
+		t.Errorf("expected both users, got %v", got)
+	}
+
+	for sort, want := range map[string]int{
+		"id":          http.StatusOK,
+		"-id":         http.StatusOK,
+		"title":       http.StatusBadRequest,
+		"-created_at": http.StatusBadRequest,
+		"type":        http.StatusBadRequest,
+		"position":    http.StatusBadRequest,
+	} {
+		if rr := serve(t, router, "GET", "/admin/users?sort="+sort, "", nil); rr.Code != want {
+			t.Errorf("sorting users by %s: got %v want %v", sort, rr.Code, want)
+		}
+	}
+}
+
+func TestAdminHandler_InspectAndReassign(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	if err := store.Add(ctx, alice, &models.Insight{ID: "insight-1", Description: "moving"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Share(ctx, models.Share{AssetID: "insight-1", OwnerID: alice, UserID: bob, Permission: models.PermissionViewer}); err != nil {
+		t.Fatal(err)
+	}
+	router := newAdminRouter(store)
+
+	rr := serve(t, router, "GET", "/admin/users/"+alice.String()+"/assets", "", nil)
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"insight-1"`) {
+		t.Fatalf("expected alice's asset, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(t, router, "PUT", "/admin/assets/insight-1/owner", `{"user_id": "`+bob.String()+`"}`, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var moved struct {
+		OwnerID         uuid.UUID `json:"owner_id"`
+		PreviousOwnerID uuid.UUID `json:"previous_owner_id"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&moved); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if moved.OwnerID != bob || moved.PreviousOwnerID != alice {
+		t.Errorf("unexpected response %+v", moved)
+	}
+
+	if rr := serve(t, router, "GET", "/users/"+alice.String()+"/assets/insight-1", "", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("previous owner: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	rr = serve(t, router, "GET", "/users/"+bob.String()+"/assets/insight-1/shares", "", nil)
+	if rr.Code != http.StatusOK || rr.Body.String() != "[]\n" {
+		t.Errorf("expected bob to own the asset with his own share dropped, got %v %s", rr.Code, rr.Body.String())
+	}
+
+	for body, want := range map[string]int{
+		`{"user_id": "` + alice.String() + `"}`: http.StatusOK,
+		`{}`:                                    http.StatusUnprocessableEntity,
+		`{"user_id": "me"}`:                     http.StatusBadRequest,
+	} {
+		if rr := serve(t, router, "PUT", "/admin/assets/insight-1/owner", body, nil); rr.Code != want {
+			t.Errorf("reassign with %s: got %v want %v", body, rr.Code, want)
+		}
+	}
+	if rr := serve(t, router, "PUT", "/admin/assets/missing/owner", `{"user_id": "`+bob.String()+`"}`, nil); rr.Code != http.StatusNotFound {
+		t.Errorf("reassigning a missing asset: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestAdminHandler_PurgeUser(t *testing.T) {
+	store := storage.NewMemoryStore()
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	if err := store.Add(ctx, alice, &models.Insight{ID: "alice-insight"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Add(ctx, bob, &models.Insight{ID: "bob-insight"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range []error{
+		store.Share(ctx, models.Share{AssetID: "alice-insight", OwnerID: alice, UserID: bob, Permission: models.PermissionViewer}),
+		store.AddFavourite(ctx, bob, "alice-insight", "insight"),
+		store.AddFavourite(ctx, bob, "bob-insight", "insight"),
+	} {
+		if step != nil {
+			t.Fatal(step)
+		}
+	}
+	router := newAdminRouter(store)
+
+	if rr := serve(t, router, "DELETE", "/admin/users/"+alice.String(), "", nil); rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr := serve(t, router, "DELETE", "/admin/users/"+alice.String(), "", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("purging twice: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+
+	rr := serve(t, router, "GET", "/admin/users/"+bob.String()+"/favourites", "", nil)
+	if strings.Contains(rr.Body.String(), "alice-insight") || !strings.Contains(rr.Body.String(), "bob-insight") {
+		t.Errorf("expected only bob's own favourite to remain, got %s", rr.Body.String())
+	}
+	rr = serve(t, router, "GET", "/admin/users", "", nil)
+	if strings.Contains(rr.Body.String(), alice.String()) {
+		t.Errorf("expected alice to be gone, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,81 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	apiKeyServices "assetsApp/internal/services/apikey"
+	"assetsApp/internal/storage"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+func newAPIKeyRouter() *mux.Router {
+	handler := handlers.NewAPIKeyHandler(apiKeyServices.NewAPIKeyService(storage.NewMemoryStore()))
+	router := mux.NewRouter()
+	router.HandleFunc("/admin/api-keys", handler.GetAPIKeys).Methods("GET")
+	router.HandleFunc("/admin/api-keys", handler.CreateAPIKey).Methods("POST")
+	router.HandleFunc("/admin/api-keys/{keyId}", handler.RevokeAPIKey).Methods("DELETE")
+	return router
+}
+
+func TestAPIKeyHandler_Lifecycle(t *testing.T) {
+	router := newAPIKeyRouter()
+
+	rr := serve(t, router, "POST", "/admin/api-keys", `{"name": "dashboards", "scopes": ["assets:read", "favourites:write"]}`, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v (%s)", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var created struct {
+		models.APIKey
+		Key string `json:"key"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, "ak_"+created.Prefix) || created.Name != "dashboards" {
+		t.Errorf("unexpected key %+v", created)
+	}
+	if got := rr.Header().Get("Location"); got != "/admin/api-keys/"+created.ID.String() {
+		t.Errorf("unexpected Location %q", got)
+	}
+
+	rr = serve(t, router, "GET", "/admin/api-keys", "", nil)
+	if strings.Contains(rr.Body.String(), created.Key) || strings.Contains(rr.Body.String(), `"key"`) {
+		t.Errorf("listing must not reveal the key: %s", rr.Body.String())
+	}
+
+	if rr := serve(t, router, "DELETE", "/admin/api-keys/"+created.ID.String(), "", nil); rr.Code != http.StatusOK {
+		t.Errorf("revoke returned %v", rr.Code)
+	}
+	rr = serve(t, router, "GET", "/admin/api-keys", "", nil)
+	var keys []models.APIKey
+	if err := json.NewDecoder(rr.Body).Decode(&keys); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("expected one revoked key, got %+v", keys)
+	}
+}
+
+func TestAPIKeyHandler_Errors(t *testing.T) {
+	router := newAPIKeyRouter()
+
+	tests := []struct {
+		method, path, body string
+		want               int
+	}{
+		{"POST", "/admin/api-keys", `{"name": "x", "scopes": ["everything"]}`, http.StatusUnprocessableEntity},
+		{"POST", "/admin/api-keys", `{"name":`, http.StatusBadRequest},
+		{"DELETE", "/admin/api-keys/not-a-uuid", "", http.StatusBadRequest},
+		{"DELETE", "/admin/api-keys/6f1c2d3e-0000-4000-8000-000000000000", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rr := serve(t, router, tt.method, tt.path, tt.body, nil); rr.Code != tt.want {
+			t.Errorf("%s %s: handler returned wrong status code: got %v want %v", tt.method, tt.path, rr.Code, tt.want)
+		}
+	}
+}
@@ -4,11 +4,15 @@ import (
 	"assetsApp/internal/handlers"
 	"assetsApp/internal/models"
 	assetServices "assetsApp/internal/services/asset"
+	"assetsApp/internal/storage"
 	"assetsApp/tests/mocks"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/google/uuid"
@@ -18,11 +22,11 @@ import (
 func TestAssetHandler_GetAssets(t *testing.T) {
 	userID := uuid.New()
 	mockStore := &mocks.MockAssetStore{
-		GetFunc: func(uid uuid.UUID) []models.Asset {
+		GetFunc: func(_ context.Context, uid uuid.UUID, _ storage.ListOptions) (storage.AssetPage, error) {
 			if uid == userID {
-				return []models.Asset{&models.Chart{ID: "test-chart"}}
+				return storage.AssetPage{Items: []models.Asset{&models.Chart{ID: "test-chart"}}}, nil
 			}
-			return nil
+			return storage.AssetPage{}, nil
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
@@ -42,13 +46,60 @@ func TestAssetHandler_GetAssets(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var assets []map[string]interface{}
-	if err := json.Unmarshal(rr.Body.Bytes(), &assets); err != nil {
+	var page struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("could not parse response body: %v", err)
 	}
 
-	if len(assets) != 1 {
-		t.Errorf("expected 1 asset, got %d", len(assets))
+	if len(page.Items) != 1 {
+		t.Errorf("expected 1 asset, got %d", len(page.Items))
+	}
+}
+
+func TestAssetHandler_GetAsset(t *testing.T) {
+	userID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		GetByIDFunc: func(_ context.Context, uid uuid.UUID, assetID string) (models.Asset, error) {
+			if uid == userID && assetID == "aud-1" {
+				return &models.Audience{ID: assetID, Gender: "Female", AgeGroup: "24-35"}, nil
+			}
+			return nil, fmt.Errorf("asset %s: %w", assetID, storage.ErrNotFound)
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.GetAsset).Methods("GET")
+
+	req, err := http.NewRequest("GET", "/users/"+userID.String()+"/assets/aud-1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var body map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body["type"] != "audience" || body["id"] != "aud-1" || body["gender"] != "Female" {
+		t.Errorf("unexpected body: %v", body)
+	}
+
+	req, err = http.NewRequest("GET", "/users/"+userID.String()+"/assets/missing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
 	}
 }
 
@@ -56,8 +107,8 @@ func TestAssetHandler_AddAsset(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		AddFunc: func(uid uuid.UUID, asset models.Asset) {
-			// No-op for this test
+		AddFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			return nil // No-op for this test
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
@@ -87,22 +138,106 @@ func TestAssetHandler_AddAsset(t *testing.T) {
 	if status := rr.Code; status != http.StatusCreated {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
 	}
+	if loc, want := rr.Header().Get("Location"), "/users/"+userID.String()+"/assets/"+assetID; loc != want {
+		t.Errorf("handler returned wrong Location: got %q want %q", loc, want)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("handler returned wrong Content-Type: got %q want %q", ct, "application/json")
+	}
+	if rr.Header().Get("ETag") == "" {
+		t.Error("expected an ETag")
+	}
+	var resp map[string]interface{}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp["type"] != "chart" || resp["id"] != assetID {
+		t.Errorf("expected the chart with its type field, got %v", resp)
+	}
 }
 
-func TestAssetHandler_EditAsset(t *testing.T) {
+func TestAssetHandler_AddAsset_GeneratesID(t *testing.T) {
 	userID := uuid.New()
-	assetID := uuid.New().String()
+	var stored models.Asset
 	mockStore := &mocks.MockAssetStore{
-		EditDescriptionFunc: func(uid uuid.UUID, aid string, description string) bool {
-			return uid == userID && aid == assetID
+		AddFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			stored = asset
+			return nil
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
 	handler := handlers.NewAssetHandler(service)
 
-	editBody := map[string]string{"description": "New Description"}
-	body, _ := json.Marshal(editBody)
+	body := []byte(`{"type": "insight", "description": "No ID supplied"}`)
+	req, err := http.NewRequest("POST", "/users/"+userID.String()+"/assets", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.AddAsset).Methods("POST")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+	}
+	if stored == nil {
+		t.Fatal("expected the asset to be stored")
+	}
+	if _, err := uuid.Parse(stored.GetID()); err != nil {
+		t.Errorf("expected a generated UUID, got %q", stored.GetID())
+	}
+
+	var resp models.Insight
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.ID != stored.GetID() {
+		t.Errorf("expected response ID %q, got %q", stored.GetID(), resp.ID)
+	}
+	if loc, want := rr.Header().Get("Location"), "/users/"+userID.String()+"/assets/"+stored.GetID(); loc != want {
+		t.Errorf("handler returned wrong Location: got %q want %q", loc, want)
+	}
+}
+
+func TestAssetHandler_AddAsset_DuplicateAcrossUsers(t *testing.T) {
+	service := assetServices.NewAssetService(storage.NewMemoryStore())
+	handler := handlers.NewAssetHandler(service)
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.AddAsset).Methods("POST")
+
+	body := `{"type": "insight", "id": "shared-id", "description": "first"}`
+	for i, want := range []int{http.StatusCreated, http.StatusConflict} {
+		req, err := http.NewRequest("POST", "/users/"+uuid.New().String()+"/assets", bytes.NewBufferString(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != want {
+			t.Errorf("request %d: handler returned wrong status code: got %v want %v", i, rr.Code, want)
+		}
+	}
+}
+
+func TestAssetHandler_ReplaceAsset(t *testing.T) {
+	userID := uuid.New()
+	assetID := uuid.New().String()
+	var stored models.Asset
+	mockStore := &mocks.MockAssetStore{
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			if uid == userID && asset.GetID() == assetID {
+				stored = asset
+				return nil
+			}
+			return storage.ErrNotFound
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
 
+	body := []byte(`{"type": "insight", "description": "New Description"}`)
 	req, err := http.NewRequest("PUT", "/users/"+userID.String()+"/assets/"+assetID, bytes.NewBuffer(body))
 	if err != nil {
 		t.Fatal(err)
@@ -110,11 +245,128 @@ func TestAssetHandler_EditAsset(t *testing.T) {
 
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
-	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.EditAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.ReplaceAsset).Methods("PUT")
 	router.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusOK {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if stored == nil || stored.GetDescription() != "New Description" {
+		t.Errorf("expected the replacement to be stored, got %+v", stored)
+	}
+}
+
+func TestAssetHandler_ReplaceAsset_IDMismatch(t *testing.T) {
+	userID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			t.Error("store should not be called when the IDs differ")
+			return nil
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+
+	body := []byte(`{"type": "insight", "id": "other", "description": "New Description"}`)
+	req, err := http.NewRequest("PUT", "/users/"+userID.String()+"/assets/insight-1", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.ReplaceAsset).Methods("PUT")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestAssetHandler_PatchAsset(t *testing.T) {
+	userID := uuid.New()
+	store := storage.NewMemoryStore()
+	chart := &models.Chart{
+		ID:          "chart-1",
+		Title:       "Old Title",
+		Description: "Old Description",
+		XAxisTitle:  "X",
+		Data:        []models.ChartData{{DatapointCode: "A", Value: 1}},
+	}
+	if err := store.Add(context.Background(), userID, chart); err != nil {
+		t.Fatal(err)
+	}
+	handler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+
+	body := []byte(`{"title": "New Title", "description": null, "data": [{"datapoint_code": "B", "value": 2}]}`)
+	req, err := http.NewRequest("PATCH", "/users/"+userID.String()+"/assets/chart-1", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.PatchAsset).Methods("PATCH")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v (%s)", status, http.StatusOK, rr.Body.String())
+	}
+
+	got, err := store.GetByID(context.Background(), userID, "chart-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &models.Chart{
+		Meta:       models.Meta{Version: 2},
+		ID:         "chart-1",
+		Title:      "New Title",
+		XAxisTitle: "X",
+		Data:       []models.ChartData{{DatapointCode: "B", Value: 2}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected patched chart %+v, got %+v", want, got)
+	}
+	if etag := rr.Header().Get("ETag"); etag != `"2"` {
+		t.Errorf("expected ETag \"2\", got %q", etag)
+	}
+}
+
+func TestAssetHandler_PatchAsset_Invalid(t *testing.T) {
+	userID := uuid.New()
+	store := storage.NewMemoryStore()
+	if err := store.Add(context.Background(), userID, &models.Audience{ID: "aud-1", Gender: "Male"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.PatchAsset).Methods("PATCH")
+
+	tests := map[string]struct {
+		contentType string
+		body        string
+		want        int
+	}{
+		"json content type":   {"application/json", `{"gender": "Female"}`, http.StatusUnsupportedMediaType},
+		"invalid value":       {"application/merge-patch+json", `{"purchases": -1}`, http.StatusUnprocessableEntity},
+		"type change":         {"application/merge-patch+json", `{"type": "chart"}`, http.StatusUnprocessableEntity},
+		"malformed patch":     {"application/merge-patch+json", `{"gender":`, http.StatusBadRequest},
+		"charset is accepted": {"application/merge-patch+json; charset=utf-8", `{"gender": "Female"}`, http.StatusOK},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest("PATCH", "/users/"+userID.String()+"/assets/aud-1", bytes.NewBufferString(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+			if rr.Code != tc.want {
+				t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, tc.want)
+			}
+		})
 	}
 }
 
@@ -122,8 +374,11 @@ func TestAssetHandler_RemoveAsset(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		RemoveFunc: func(uid uuid.UUID, aid string) bool {
-			return uid == userID && aid == assetID
+		RemoveFunc: func(_ context.Context, uid uuid.UUID, aid string, _ int64) error {
+			if uid == userID && aid == assetID {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
@@ -181,19 +436,18 @@ func TestAssetHandler_AddAsset_InvalidBody(t *testing.T) {
 	}
 }
 
-func TestAssetHandler_EditAsset_NotFound(t *testing.T) {
+func TestAssetHandler_ReplaceAsset_NotFound(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		EditDescriptionFunc: func(uid uuid.UUID, aid string, description string) bool {
-			return false // Simulate not found
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			return storage.ErrNotFound // Simulate not found
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
 	handler := handlers.NewAssetHandler(service)
 
-	editBody := map[string]string{"description": "New Description"}
-	body, _ := json.Marshal(editBody)
+	body := []byte(`{"type": "insight", "description": "New Description"}`)
 
 	req, err := http.NewRequest("PUT", "/users/"+userID.String()+"/assets/"+assetID, bytes.NewBuffer(body))
 	if err != nil {
@@ -202,7 +456,7 @@ func TestAssetHandler_EditAsset_NotFound(t *testing.T) {
 
 	rr := httptest.NewRecorder()
 	router := mux.NewRouter()
-	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.EditAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.ReplaceAsset).Methods("PUT")
 	router.ServeHTTP(rr, req)
 
 	if status := rr.Code; status != http.StatusNotFound {
@@ -214,8 +468,8 @@ func TestAssetHandler_RemoveAsset_NotFound(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		RemoveFunc: func(uid uuid.UUID, aid string) bool {
-			return false // Simulate not found
+		RemoveFunc: func(_ context.Context, uid uuid.UUID, aid string, _ int64) error {
+			return storage.ErrNotFound // Simulate not found
 		},
 	}
 	service := assetServices.NewAssetService(mockStore)
@@ -236,3 +490,187 @@ func TestAssetHandler_RemoveAsset_NotFound(t *testing.T) {
 	}
 }
 
+func TestAssetHandler_AddAsset_Conflict(t *testing.T) {
+	userID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		AddFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			return fmt.Errorf("asset %s: %w", asset.GetID(), storage.ErrConflict)
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+
+	body := []byte(`{"type": "insight", "id": "insight-1", "description": "dup"}`)
+	req, err := http.NewRequest("POST", "/users/"+userID.String()+"/assets", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.AddAsset).Methods("POST")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+	}
+}
+
+func TestAssetHandler_AddAsset_InvalidPayload(t *testing.T) {
+	userID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		AddFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			t.Error("store should not be called for an invalid asset")
+			return nil
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+
+	body := []byte(`{"type": "audience", "gender": "Other", "social_hours": "lots", "colour": "red"}`)
+	req, err := http.NewRequest("POST", "/users/"+userID.String()+"/assets", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.AddAsset).Methods("POST")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+
+	var resp models.ValidationError
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode violations: %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, v := range resp.Violations {
+		fields[v.Field] = true
+	}
+	for _, want := range []string{"gender", "social_hours", "colour"} {
+		if !fields[want] {
+			t.Errorf("expected a violation for %q, got %+v", want, resp.Violations)
+		}
+	}
+}
+
+func TestAssetHandler_GetAssets_StoreUnavailable(t *testing.T) {
+	userID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		GetFunc: func(_ context.Context, uid uuid.UUID, _ storage.ListOptions) (storage.AssetPage, error) {
+			return storage.AssetPage{}, fmt.Errorf("list assets: %w", storage.ErrUnavailable)
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+
+	req, err := http.NewRequest("GET", "/users/"+userID.String()+"/assets", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.GetAssets).Methods("GET")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusServiceUnavailable {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusServiceUnavailable)
+	}
+}
+
+func TestAssetHandler_ReplaceAsset_ValidationError(t *testing.T) {
+	userID := uuid.New()
+	assetID := uuid.New().String()
+	mockStore := &mocks.MockAssetStore{
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			return fmt.Errorf("asset %s is a chart, not an insight: %w", asset.GetID(), storage.ErrValidation)
+		},
+	}
+	service := assetServices.NewAssetService(mockStore)
+	handler := handlers.NewAssetHandler(service)
+
+	body := []byte(`{"type": "insight", "description": "New Description"}`)
+	req, err := http.NewRequest("PUT", "/users/"+userID.String()+"/assets/"+assetID, bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.ReplaceAsset).Methods("PUT")
+	router.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusUnprocessableEntity {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestAssetHandler_GetAssets_Pagination(t *testing.T) {
+	userID := uuid.New()
+	store := storage.NewMemoryStore()
+	for _, id := range []string{"c", "a", "e", "b", "d"} {
+		if err := store.Add(context.Background(), userID, &models.Insight{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	handler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.GetAssets).Methods("GET")
+
+	type page struct {
+		Items      []map[string]interface{} `json:"items"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	fetch := func(query string) page {
+		req := httptest.NewRequest("GET", "/users/"+userID.String()+"/assets?"+query, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var p page
+		if err := json.Unmarshal(rr.Body.Bytes(), &p); err != nil {
+			t.Fatalf("could not parse response body: %v", err)
+		}
+		return p
+	}
+
+	var ids []string
+	query := "limit=2&sort=-id"
+	for i := 0; i < 5; i++ {
+		p := fetch(query)
+		for _, item := range p.Items {
+			ids = append(ids, item["id"].(string))
+		}
+		if p.NextCursor == "" {
+			break
+		}
+		query = "limit=2&sort=-id&cursor=" + p.NextCursor
+	}
+
+	want := []string{"e", "d", "c", "b", "a"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("expected %v across pages, got %v", want, ids)
+	}
+}
+
+func TestAssetHandler_GetAssets_InvalidListOptions(t *testing.T) {
+	userID := uuid.New()
+	handler := handlers.NewAssetHandler(assetServices.NewAssetService(&mocks.MockAssetStore{}))
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.GetAssets).Methods("GET")
+
+	for _, query := range []string{"limit=0", "limit=abc", "sort=colour", "cursor=not-a-cursor", "sort=title&limit=100000"} {
+		req := httptest.NewRequest("GET", "/users/"+userID.String()+"/assets?"+query, nil)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", query, rr.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	collectionServices "assetsApp/internal/services/collection"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newCollectionRouter wires the asset and collection routes to one
+// MemoryStore in which owner has the insights "insight-1" to "insight-3".
+func newCollectionRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	for _, id := range []string{"insight-1", "insight-2", "insight-3"} {
+		if err := store.Add(context.Background(), owner, &models.Insight{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	collectionHandler := handlers.NewCollectionHandler(collectionServices.NewCollectionService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
+	router.HandleFunc("/users/{userId}/collections", collectionHandler.GetCollections).Methods("GET")
+	router.HandleFunc("/users/{userId}/collections", collectionHandler.CreateCollection).Methods("POST")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.GetCollection).Methods("GET")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.RenameCollection).Methods("PUT")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}", collectionHandler.RemoveCollection).Methods("DELETE")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}/assets", collectionHandler.GetCollectionAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}/assets", collectionHandler.SetCollectionAssets).Methods("PUT")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}/assets/{assetId}", collectionHandler.AddCollectionAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/collections/{collectionId}/assets/{assetId}", collectionHandler.RemoveCollectionAsset).Methods("DELETE")
+	return router
+}
+
+// createCollection posts a collection and returns it as created.
+func createCollection(t *testing.T, router *mux.Router, user uuid.UUID, name string) models.Collection {
+	t.Helper()
+	rr := serve(t, router, "POST", "/users/"+user.String()+"/collections", `{"name": "`+name+`"}`, nil)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var collection models.Collection
+	if err := json.NewDecoder(rr.Body).Decode(&collection); err != nil {
+		t.Fatalf("failed to decode collection: %v", err)
+	}
+	if want := "/users/" + user.String() + "/collections/" + collection.ID.String(); rr.Header().Get("Location") != want {
+		t.Errorf("expected Location %q, got %q", want, rr.Header().Get("Location"))
+	}
+	return collection
+}
+
+// collectionAssetIDs lists the IDs returned by a GET of path, which must
+// answer an items envelope of insights.
+func collectionAssetIDs(t *testing.T, router *mux.Router, path string) []string {
+	t.Helper()
+	rr := serve(t, router, "GET", path, "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET %s returned %v: %s", path, rr.Code, rr.Body.String())
+	}
+	var page struct {
+		Items []models.Insight `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode listing: %v", err)
+	}
+	ids := make([]string, len(page.Items))
+	for i, item := range page.Items {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCollectionHandler_Lifecycle(t *testing.T) {
+	owner, stranger := uuid.New(), uuid.New()
+	router := newCollectionRouter(t, owner)
+	collection := createCollection(t, router, owner, "Q3 report")
+	path := "/users/" + owner.String() + "/collections/" + collection.ID.String()
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"duplicate name", "POST", "/users/" + owner.String() + "/collections", `{"name": "Q3 report"}`, http.StatusConflict},
+		{"blank name", "POST", "/users/" + owner.String() + "/collections", `{"name": "  "}`, http.StatusUnprocessableEntity},
+		{"add asset", "PUT", path + "/assets/insight-2", "", http.StatusOK},
+		{"add another", "PUT", path + "/assets/insight-1", "", http.StatusOK},
+		{"adding again is a no-op", "PUT", path + "/assets/insight-2", "", http.StatusOK},
+		{"unknown asset", "PUT", path + "/assets/missing", "", http.StatusNotFound},
+		{"stranger cannot read it", "GET", "/users/" + stranger.String() + "/collections/" + collection.ID.String(), "", http.StatusNotFound},
+		{"malformed collection ID", "GET", "/users/" + owner.String() + "/collections/nope", "", http.StatusBadRequest},
+		{"rename", "PUT", path, `{"name": "Q4 report"}`, http.StatusOK},
+	}
+	for _, step := range steps {
+		rr := serve(t, router, step.method, step.path, step.body, nil)
+		if rr.Code != step.want {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v (%s)", step.name, rr.Code, step.want, rr.Body.String())
+		}
+	}
+
+	if got := collectionAssetIDs(t, router, path+"/assets"); !equalIDs(got, []string{"insight-2", "insight-1"}) {
+		t.Errorf("expected assets in the order added, got %v", got)
+	}
+
+	rr := serve(t, router, "GET", path, "", nil)
+	var got models.Collection
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode collection: %v", err)
+	}
+	if got.Name != "Q4 report" || !equalIDs(got.AssetIDs, []string{"insight-2", "insight-1"}) {
+		t.Errorf("unexpected collection: %+v", got)
+	}
+
+	if rr := serve(t, router, "DELETE", path+"/assets/insight-2", "", nil); rr.Code != http.StatusOK {
+		t.Fatalf("remove returned %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve(t, router, "DELETE", path+"/assets/insight-2", "", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected removing a non-member to be 404, got %v", rr.Code)
+	}
+
+	if rr := serve(t, router, "DELETE", path, "", nil); rr.Code != http.StatusOK {
+		t.Fatalf("delete returned %v: %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve(t, router, "GET", path, "", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected deleted collection to be 404, got %v", rr.Code)
+	}
+	if got := collectionAssetIDs(t, router, "/users/"+owner.String()+"/assets"); len(got) != 3 {
+		t.Errorf("expected deleting a collection to keep its assets, got %v", got)
+	}
+}
+
+func TestCollectionHandler_SetCollectionAssets(t *testing.T) {
+	owner := uuid.New()
+	router := newCollectionRouter(t, owner)
+	collection := createCollection(t, router, owner, "Ordered")
+	path := "/users/" + owner.String() + "/collections/" + collection.ID.String() + "/assets"
+
+	rr := serve(t, router, "PUT", path, `{"asset_ids": ["insight-3", "insight-1", "insight-2"]}`, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("set returned %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := collectionAssetIDs(t, router, path); !equalIDs(got, []string{"insight-3", "insight-1", "insight-2"}) {
+		t.Errorf("expected the order given, got %v", got)
+	}
+
+	rr = serve(t, router, "PUT", path, `{"asset_ids": ["insight-1", "insight-1"]}`, nil)
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected duplicate IDs to be 422, got %v", rr.Code)
+	}
+	rr = serve(t, router, "PUT", path, `{"asset_ids": ["insight-1", "missing"]}`, nil)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown asset to be 404, got %v", rr.Code)
+	}
+	if got := collectionAssetIDs(t, router, path); !equalIDs(got, []string{"insight-3", "insight-1", "insight-2"}) {
+		t.Errorf("expected a rejected set to leave the collection alone, got %v", got)
+	}
+}
+
+func TestAssetHandler_GetAssets_CollectionFilter(t *testing.T) {
+	owner := uuid.New()
+	router := newCollectionRouter(t, owner)
+	collection := createCollection(t, router, owner, "Filter")
+	base := "/users/" + owner.String()
+	for _, id := range []string{"insight-3", "insight-1"} {
+		if rr := serve(t, router, "PUT", base+"/collections/"+collection.ID.String()+"/assets/"+id, "", nil); rr.Code != http.StatusOK {
+			t.Fatalf("add returned %v: %s", rr.Code, rr.Body.String())
+		}
+	}
+
+	if got := collectionAssetIDs(t, router, base+"/assets?collection="+collection.ID.String()); !equalIDs(got, []string{"insight-1", "insight-3"}) {
+		t.Errorf("expected the collection's assets sorted by id, got %v", got)
+	}
+
+	// Deleting an asset takes it out of its collections.
+	if rr := serve(t, router, "DELETE", base+"/assets/insight-3", "", nil); rr.Code != http.StatusOK {
+		t.Fatalf("delete returned %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := collectionAssetIDs(t, router, base+"/assets?collection="+collection.ID.String()); !equalIDs(got, []string{"insight-1"}) {
+		t.Errorf("expected the deleted asset to leave the collection, got %v", got)
+	}
+
+	if rr := serve(t, router, "GET", base+"/assets?collection=nope", "", nil); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected a malformed collection to be 400, got %v", rr.Code)
+	}
+	if rr := serve(t, router, "GET", base+"/assets?collection="+uuid.NewString(), "", nil); rr.Code != http.StatusNotFound {
+		t.Errorf("expected an unknown collection to be 404, got %v", rr.Code)
+	}
+}
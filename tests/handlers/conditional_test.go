@@ -0,0 +1,140 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	"assetsApp/internal/storage"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newConditionalRouter serves the single-asset and listing routes from a
+// MemoryStore holding one insight, "insight-1", at version 1.
+func newConditionalRouter(t *testing.T, userID uuid.UUID) *mux.Router {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	if err := store.Add(context.Background(), userID, &models.Insight{ID: "insight-1", Description: "first"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", handler.GetAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.GetAsset).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.ReplaceAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.PatchAsset).Methods("PATCH")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", handler.RemoveAsset).Methods("DELETE")
+	return router
+}
+
+func serve(t *testing.T, router *mux.Router, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, err := http.NewRequest(method, path, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestAssetHandler_GetAsset_ETag(t *testing.T) {
+	userID := uuid.New()
+	router := newConditionalRouter(t, userID)
+	path := "/users/" + userID.String() + "/assets/insight-1"
+
+	rr := serve(t, router, "GET", path, "", nil)
+	if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"1"` {
+		t.Fatalf("expected 200 with ETag \"1\", got %v with %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	rr = serve(t, router, "GET", path, "", map[string]string{"If-None-Match": `"1"`})
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotModified)
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("expected an empty 304 body, got %q", rr.Body.String())
+	}
+}
+
+func TestAssetHandler_IfMatch(t *testing.T) {
+	userID := uuid.New()
+	router := newConditionalRouter(t, userID)
+	path := "/users/" + userID.String() + "/assets/insight-1"
+	put := `{"type": "insight", "description": "second"}`
+
+	steps := []struct {
+		name    string
+		method  string
+		body    string
+		ifMatch string
+		want    int
+		etag    string
+	}{
+		{"put at current version", "PUT", put, `"1"`, http.StatusOK, `"2"`},
+		{"put at stale version", "PUT", put, `"1"`, http.StatusPreconditionFailed, ""},
+		{"weak tag never matches", "PUT", put, `W/"2"`, http.StatusPreconditionFailed, ""},
+		{"malformed tag", "PUT", put, `2`, http.StatusPreconditionFailed, ""},
+		{"patch at stale version", "PATCH", `{"description": "third"}`, `"1"`, http.StatusPreconditionFailed, ""},
+		{"patch at current version", "PATCH", `{"description": "third"}`, `"2"`, http.StatusOK, `"3"`},
+		{"patch without If-Match", "PATCH", `{"description": "fourth"}`, "", http.StatusOK, `"4"`},
+		{"delete at stale version", "DELETE", "", `"3"`, http.StatusPreconditionFailed, ""},
+		{"delete with wildcard", "DELETE", "", "*", http.StatusOK, ""},
+	}
+	for _, step := range steps {
+		header := map[string]string{}
+		if step.method == "PATCH" {
+			header["Content-Type"] = "application/merge-patch+json"
+		}
+		if step.ifMatch != "" {
+			header["If-Match"] = step.ifMatch
+		}
+		rr := serve(t, router, step.method, path, step.body, header)
+		if rr.Code != step.want {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", step.name, rr.Code, step.want)
+		}
+		if step.etag != "" && rr.Header().Get("ETag") != step.etag {
+			t.Errorf("%s: expected ETag %s, got %q", step.name, step.etag, rr.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestAssetHandler_GetAssets_IfNoneMatch(t *testing.T) {
+	userID := uuid.New()
+	router := newConditionalRouter(t, userID)
+	listPath := "/users/" + userID.String() + "/assets"
+
+	rr := serve(t, router, "GET", listPath, "", nil)
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected 200 with an ETag, got %v with %q", rr.Code, etag)
+	}
+
+	rr = serve(t, router, "GET", listPath, "", map[string]string{"If-None-Match": `"other", ` + etag})
+	if rr.Code != http.StatusNotModified {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusNotModified)
+	}
+
+	rr = serve(t, router, "PUT", listPath+"/insight-1", `{"type": "insight", "description": "changed"}`, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("PUT returned %v", rr.Code)
+	}
+
+	rr = serve(t, router, "GET", listPath, "", map[string]string{"If-None-Match": etag})
+	if rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code after a change: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr.Header().Get("ETag") == etag {
+		t.Error("expected the listing ETag to change with its content")
+	}
+}
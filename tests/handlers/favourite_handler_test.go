@@ -5,8 +5,10 @@ import (
 	"assetsApp/internal/handlers"
 	"assetsApp/internal/models"
 	favouriteServices "assetsApp/internal/services/favourite"
+	"assetsApp/internal/storage"
 	"assetsApp/tests/mocks"
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -19,11 +21,14 @@ import (
 func TestFavouriteHandler_GetFavourites(t *testing.T) {
 	userID := uuid.New()
 	mockStore := &mocks.MockAssetStore{
-		GetFavouritesFunc: func(uid uuid.UUID) []models.Favourite {
+		GetFavouritesFunc: func(_ context.Context, uid uuid.UUID, _ storage.ListOptions) (storage.FavouritePage, error) {
 			if uid == userID {
-				return []models.Favourite{{Asset: &models.Chart{ID: "test-chart"}}}
+				return storage.FavouritePage{
+					Items:      []models.Favourite{{Asset: &models.Chart{ID: "test-chart"}}},
+					NextCursor: "next-page",
+				}, nil
 			}
-			return nil
+			return storage.FavouritePage{}, nil
 		},
 	}
 	service := favouriteServices.NewFavouriteService(mockStore)
@@ -43,13 +48,19 @@ func TestFavouriteHandler_GetFavourites(t *testing.T) {
 		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 	}
 
-	var favs []map[string]interface{}
-	if err := json.Unmarshal(rr.Body.Bytes(), &favs); err != nil {
+	var page struct {
+		Items      []map[string]interface{} `json:"items"`
+		NextCursor string                   `json:"next_cursor"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &page); err != nil {
 		t.Fatalf("could not parse response body: %v", err)
 	}
 
-	if len(favs) != 1 {
-		t.Errorf("expected 1 favourite, got %d", len(favs))
+	if len(page.Items) != 1 {
+		t.Errorf("expected 1 favourite, got %d", len(page.Items))
+	}
+	if page.NextCursor != "next-page" {
+		t.Errorf("expected next_cursor %q, got %q", "next-page", page.NextCursor)
 	}
 }
 
@@ -57,8 +68,11 @@ func TestFavouriteHandler_AddFavourite(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		AddFavouriteFunc: func(uid uuid.UUID, aid string, assetType string) bool {
-			return uid == userID && aid == assetID
+		AddFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string, assetType string) error {
+			if uid == userID && aid == assetID {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 	service := favouriteServices.NewFavouriteService(mockStore)
@@ -86,8 +100,11 @@ func TestFavouriteHandler_RemoveFavourite(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		RemoveFavouriteFunc: func(uid uuid.UUID, aid string) bool {
-			return uid == userID && aid == assetID
+		RemoveFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string) error {
+			if uid == userID && aid == assetID {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 	service := favouriteServices.NewFavouriteService(mockStore)
@@ -103,8 +120,8 @@ func TestFavouriteHandler_RemoveFavourite(t *testing.T) {
 	router.HandleFunc("/users/{userId}/favourites/{assetId}", handler.RemoveFavourite).Methods("DELETE")
 	router.ServeHTTP(rr, req)
 
-	if status := rr.Code; status != http.StatusOK {
-		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	if status := rr.Code; status != http.StatusNoContent {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
 	}
 }
 
@@ -130,8 +147,8 @@ func TestFavouriteHandler_AddFavourite_NotFound(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		AddFavouriteFunc: func(uid uuid.UUID, aid string, assetType string) bool {
-			return false // Simulate not found
+		AddFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string, assetType string) error {
+			return storage.ErrNotFound // Simulate not found
 		},
 	}
 	service := favouriteServices.NewFavouriteService(mockStore)
@@ -159,8 +176,8 @@ func TestFavouriteHandler_RemoveFavourite_NotFound(t *testing.T) {
 	userID := uuid.New()
 	assetID := uuid.New().String()
 	mockStore := &mocks.MockAssetStore{
-		RemoveFavouriteFunc: func(uid uuid.UUID, aid string) bool {
-			return false // Simulate not found
+		RemoveFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string) error {
+			return storage.ErrNotFound // Simulate not found
 		},
 	}
 	service := favouriteServices.NewFavouriteService(mockStore)
@@ -181,3 +198,36 @@ func TestFavouriteHandler_RemoveFavourite_NotFound(t *testing.T) {
 	}
 }
 
+
+func TestFavouriteHandler_AddAndRemoveAreIdempotent(t *testing.T) {
+	userID := uuid.New()
+	store := storage.NewMemoryStore()
+	if err := store.Add(context.Background(), userID, &models.Chart{ID: "chart-1"}); err != nil {
+		t.Fatal(err)
+	}
+	handler := handlers.NewFavouriteHandler(favouriteServices.NewFavouriteService(store))
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/favourites/{assetId}", handler.AddFavourite).Methods("POST")
+	router.HandleFunc("/users/{userId}/favourites/{assetId}", handler.RemoveFavourite).Methods("DELETE")
+	fav := "/users/" + userID.String() + "/favourites/"
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{"add", "POST", fav + "chart-1", http.StatusCreated},
+		{"add again", "POST", fav + "chart-1", http.StatusOK},
+		{"remove", "DELETE", fav + "chart-1", http.StatusNoContent},
+		{"remove again", "DELETE", fav + "chart-1", http.StatusNotFound},
+		{"add missing asset", "POST", fav + "chart-9", http.StatusNotFound},
+		{"remove never favourited", "DELETE", fav + "chart-9", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rr := serve(t, router, step.method, step.path, `{"asset_type": "chart"}`, nil)
+		if rr.Code != step.want {
+			t.Errorf("%s: expected %d, got %d: %s", step.name, step.want, rr.Code, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	favouriteServices "assetsApp/internal/services/favourite"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newFavouriteOrderRouter wires the asset and favourite routes to one
+// MemoryStore in which owner has the insights "insight-1" to "insight-4" and
+// has favourited them in the order 3, 1, 4, 2.
+func newFavouriteOrderRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	for _, id := range []string{"insight-1", "insight-2", "insight-3", "insight-4"} {
+		if err := store.Add(ctx, owner, &models.Insight{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"insight-3", "insight-1", "insight-4", "insight-2"} {
+		if err := store.AddFavourite(ctx, owner, id, "insight"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	favouriteHandler := handlers.NewFavouriteHandler(favouriteServices.NewFavouriteService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	router.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.UpdateFavourite).Methods("PATCH")
+	router.HandleFunc("/users/{userId}/favourites/{assetId}/position", favouriteHandler.MoveFavourite).Methods("PUT")
+	return router
+}
+
+// favouriteItem is the part of a listed favourite the ordering tests look at.
+type favouriteItem struct {
+	Asset struct {
+		ID string `json:"id"`
+	} `json:"asset"`
+	CreatedAt time.Time `json:"created_at"`
+	Position  int       `json:"position"`
+	Pinned    bool      `json:"pinned"`
+	Note      string    `json:"note"`
+}
+
+func listFavourites(t *testing.T, router *mux.Router, path string) []favouriteItem {
+	t.Helper()
+	rr := serve(t, router, "GET", path, "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("GET %s: expected 200, got %d: %s", path, rr.Code, rr.Body.String())
+	}
+	var page struct {
+		Items []favouriteItem `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	return page.Items
+}
+
+func favouriteIDs(items []favouriteItem) []string {
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.Asset.ID
+	}
+	return ids
+}
+
+func TestFavouriteHandler_OrderPinAndNote(t *testing.T) {
+	owner := uuid.New()
+	router := newFavouriteOrderRouter(t, owner)
+	favs := "/users/" + owner.String() + "/favourites"
+
+	items := listFavourites(t, router, favs)
+	if got, want := favouriteIDs(items), []string{"insight-3", "insight-1", "insight-4", "insight-2"}; !equalIDs(got, want) {
+		t.Fatalf("expected favourites in the order they were added %v, got %v", want, got)
+	}
+	for i, item := range items {
+		if item.Position != i+1 || item.CreatedAt.IsZero() || item.Pinned || item.Note != "" {
+			t.Errorf("unexpected metadata on new favourite %+v", item)
+		}
+	}
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   []string
+	}{
+		{"move before", "PUT", favs + "/insight-2/position", `{"before": "insight-3"}`,
+			[]string{"insight-2", "insight-3", "insight-1", "insight-4"}},
+		{"move after", "PUT", favs + "/insight-3/position", `{"after": "insight-4"}`,
+			[]string{"insight-2", "insight-1", "insight-4", "insight-3"}},
+		{"pin with a note", "PATCH", favs + "/insight-4", `{"pinned": true, "note": "  weekly review  "}`,
+			[]string{"insight-4", "insight-2", "insight-1", "insight-3"}},
+		{"pinned favourite moves among the rest", "PUT", favs + "/insight-4/position", `{"after": "insight-3"}`,
+			[]string{"insight-4", "insight-2", "insight-1", "insight-3"}},
+		{"unpin keeps the note", "PATCH", favs + "/insight-4", `{"pinned": false}`,
+			[]string{"insight-2", "insight-1", "insight-3", "insight-4"}},
+	}
+	for _, step := range steps {
+		rr := serve(t, router, step.method, step.path, step.body, nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("%s: expected 200, got %d: %s", step.name, rr.Code, rr.Body.String())
+		}
+		if got := favouriteIDs(listFavourites(t, router, favs)); !equalIDs(got, step.want) {
+			t.Errorf("%s: expected %v, got %v", step.name, step.want, got)
+		}
+	}
+
+	items = listFavourites(t, router, favs)
+	last := items[len(items)-1]
+	if last.Note != "weekly review" || last.Pinned || last.Position != 4 {
+		t.Errorf("expected insight-4 unpinned at position 4 with its trimmed note, got %+v", last)
+	}
+
+	if got, want := favouriteIDs(listFavourites(t, router, favs+"?sort=id")), []string{"insight-1", "insight-2", "insight-3", "insight-4"}; !equalIDs(got, want) {
+		t.Errorf("sort=id: expected %v, got %v", want, got)
+	}
+	if got, want := favouriteIDs(listFavourites(t, router, favs+"?sort=-position")), []string{"insight-4", "insight-3", "insight-1", "insight-2"}; !equalIDs(got, want) {
+		t.Errorf("sort=-position: expected %v, got %v", want, got)
+	}
+}
+
+func TestFavouriteHandler_OrderPaginates(t *testing.T) {
+	owner := uuid.New()
+	router := newFavouriteOrderRouter(t, owner)
+	favs := "/users/" + owner.String() + "/favourites"
+	if rr := serve(t, router, "PATCH", favs+"/insight-2", `{"pinned": true}`, nil); rr.Code != http.StatusOK {
+		t.Fatalf("expected 200 pinning, got %d", rr.Code)
+	}
+
+	var got []string
+	path := favs + "?limit=3"
+	for path != "" {
+		rr := serve(t, router, "GET", path, "", nil)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("GET %s: expected 200, got %d: %s", path, rr.Code, rr.Body.String())
+		}
+		var page struct {
+			Items      []favouriteItem `json:"items"`
+			NextCursor string          `json:"next_cursor"`
+		}
+		if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, favouriteIDs(page.Items)...)
+		path = ""
+		if page.NextCursor != "" {
+			path = favs + "?limit=3&cursor=" + page.NextCursor
+		}
+	}
+	if want := []string{"insight-2", "insight-3", "insight-1", "insight-4"}; !equalIDs(got, want) {
+		t.Errorf("expected %v across pages, got %v", want, got)
+	}
+}
+
+func TestFavouriteHandler_OrderErrors(t *testing.T) {
+	owner := uuid.New()
+	router := newFavouriteOrderRouter(t, owner)
+	favs := "/users/" + owner.String() + "/favourites"
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"neither before nor after", "PUT", favs + "/insight-1/position", `{}`, http.StatusUnprocessableEntity},
+		{"both before and after", "PUT", favs + "/insight-1/position", `{"before": "insight-2", "after": "insight-3"}`, http.StatusUnprocessableEntity},
+		{"relative to itself", "PUT", favs + "/insight-1/position", `{"before": "insight-1"}`, http.StatusUnprocessableEntity},
+		{"target is not a favourite", "PUT", favs + "/insight-1/position", `{"before": "insight-9"}`, http.StatusNotFound},
+		{"moved asset is not a favourite", "PUT", favs + "/insight-9/position", `{"before": "insight-1"}`, http.StatusNotFound},
+		{"malformed body", "PUT", favs + "/insight-1/position", `{`, http.StatusBadRequest},
+		{"update a non-favourite", "PATCH", favs + "/insight-9", `{"pinned": true}`, http.StatusNotFound},
+		{"note too long", "PATCH", favs + "/insight-1", `{"note": "` + strings.Repeat("x", 1001) + `"}`, http.StatusUnprocessableEntity},
+		{"invalid user", "PATCH", "/users/invalid-uuid/favourites/insight-1", `{"pinned": true}`, http.StatusBadRequest},
+		{"assets can't be sorted by position", "GET", "/users/" + owner.String() + "/assets?sort=position", "", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rr := serve(t, router, tt.method, tt.path, tt.body, nil)
+		if rr.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.want, rr.Code, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	"assetsApp/internal/storage"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newFilterRouter serves the asset listing from a MemoryStore holding an
+// insight and four audiences for owner.
+func newFilterRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	assets := []models.Asset{
+		&models.Insight{ID: "insight-1"},
+		&models.Audience{ID: "aud-1", Gender: "Female", Country: "GR", AgeGroup: "18-24", SocialHours: 3, Purchases: 2},
+		&models.Audience{ID: "aud-2", Gender: "Female", Country: "GR", AgeGroup: "25-34", SocialHours: 5, Purchases: 12},
+		&models.Audience{ID: "aud-3", Gender: "Male", Country: "GR", AgeGroup: "18-24", SocialHours: 1, Purchases: 4},
+		&models.Audience{ID: "aud-4", Gender: "Female", Country: "DK", AgeGroup: "18-24", SocialHours: 4, Purchases: 0},
+	}
+	for _, a := range assets {
+		if err := store.Add(context.Background(), owner, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	return router
+}
+
+func TestAssetHandler_GetAssets_AttributeFilters(t *testing.T) {
+	owner := uuid.New()
+	router := newFilterRouter(t, owner)
+	base := "/users/" + owner.String() + "/assets"
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"type only", "?type=insight", []string{"insight-1"}},
+		{"equality", "?type=audience&country=GR", []string{"aud-1", "aud-2", "aud-3"}},
+		{"several values", "?type=audience&country=GR,DK&gender=Male", []string{"aud-3"}},
+		{"repeated values", "?type=audience&country=DK&country=GR&age_group=25-34", []string{"aud-2"}},
+		{"ranges", "?type=audience&social_hours_gte=3&purchases_lt=10", []string{"aud-1", "aud-4"}},
+		{"integer equality", "?type=audience&purchases=0", []string{"aud-4"}},
+		{"explicit eq", "?type=audience&purchases_eq=4", []string{"aud-3"}},
+		{"everything from the request", "?type=audience&country=GR&gender=Female&social_hours_gte=3&purchases_lt=10", []string{"aud-1"}},
+		{"with sorting", "?type=audience&gender=Female&sort=-id&limit=2", []string{"aud-4", "aud-2"}},
+		{"unrelated parameters are ignored without a type", "?_=12345", []string{"aud-1", "aud-2", "aud-3", "aud-4", "insight-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collectionAssetIDs(t, router, base+tt.query); !equalIDs(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAssetHandler_GetAssets_BadAttributeFilters(t *testing.T) {
+	owner := uuid.New()
+	router := newFilterRouter(t, owner)
+	base := "/users/" + owner.String() + "/assets"
+
+	for _, query := range []string{
+		"?type=podcast",
+		"?country=GR",
+		"?type=insight&country=GR",
+		"?type=audience&countyr=GR",
+		"?type=audience&country_gt=GR",
+		"?type=audience&purchases_lt=many",
+		"?type=audience&purchases_lt=1&purchases_lt=2",
+		"?type=audience&gender=",
+	} {
+		if rr := serve(t, router, "GET", base+query, "", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v (%s)", query, rr.Code, rr.Body.String())
+		}
+	}
+}
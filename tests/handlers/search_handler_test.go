@@ -0,0 +1,162 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newSearchRouter wires the asset routes, search included, to a MemoryStore
+// holding a chart, two insights and an audience for owner.
+func newSearchRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	assets := []models.Asset{
+		&models.Chart{ID: "chart-1", Title: "Purchases by age", XAxisTitle: "Age group", YAxisTitle: "Purchases",
+			Data: []models.ChartData{{DatapointCode: "SM_AGE_18_24", Value: 1}}},
+		&models.Insight{ID: "insight-1", Description: "Gen Z purchases peak on weekends"},
+		&models.Insight{ID: "insight-2", Description: "Millennials prefer email"},
+		&models.Audience{ID: "audience-1", Description: "Gen Z shoppers in Denmark"},
+	}
+	for _, a := range assets {
+		if err := store.Add(ctx, owner, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/search", assetHandler.SearchAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.ReplaceAsset).Methods("PUT")
+	return router
+}
+
+type searchResult struct {
+	Asset   struct{ ID string } `json:"asset"`
+	Rank    float64             `json:"rank"`
+	Snippet string              `json:"snippet"`
+}
+
+func search(t *testing.T, router *mux.Router, user uuid.UUID, query string) []searchResult {
+	t.Helper()
+	rr := serve(t, router, "GET", "/users/"+user.String()+"/assets/search?q="+url.QueryEscape(query), "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("search for %q returned %v: %s", query, rr.Code, rr.Body.String())
+	}
+	var page struct {
+		Items []searchResult `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode results: %v", err)
+	}
+	return page.Items
+}
+
+func resultIDs(results []searchResult) []string {
+	ids := make([]string, len(results))
+	for i, r := range results {
+		ids[i] = r.Asset.ID
+	}
+	return ids
+}
+
+func TestAssetHandler_SearchAssets(t *testing.T) {
+	owner := uuid.New()
+	router := newSearchRouter(t, owner)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"every word must match", "gen z purchases", []string{"insight-1"}},
+		{"title ranks above description", "purchases", []string{"chart-1", "insight-1"}},
+		{"prefix of a word", "purchase", []string{"chart-1", "insight-1"}},
+		{"case and punctuation", "GEN-Z", []string{"audience-1", "insight-1"}},
+		{"datapoint code", "SM_AGE_18_24", []string{"chart-1"}},
+		{"no match", "tractors", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resultIDs(search(t, router, owner, tt.query)); !equalIDs(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	if got := search(t, router, uuid.New(), "purchases"); len(got) != 0 {
+		t.Errorf("expected other users' assets to be left out, got %v", resultIDs(got))
+	}
+}
+
+func TestAssetHandler_SearchAssets_Snippets(t *testing.T) {
+	owner := uuid.New()
+	router := newSearchRouter(t, owner)
+
+	results := search(t, router, owner, "gen z purchases")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if want := "<mark>Gen</mark> <mark>Z</mark> <mark>purchases</mark> peak on weekends"; results[0].Snippet != want {
+		t.Errorf("expected snippet %q, got %q", want, results[0].Snippet)
+	}
+	if results[0].Rank <= 0 {
+		t.Errorf("expected a positive rank, got %v", results[0].Rank)
+	}
+
+	// The snippet is HTML-escaped, so only the <mark> tags are markup
+	path := "/users/" + owner.String() + "/assets/insight-2"
+	if rr := serve(t, router, "PUT", path, `{"type": "insight", "description": "Gen Z <script>alert(1)</script> & email"}`, nil); rr.Code != http.StatusOK {
+		t.Fatalf("replace returned %v: %s", rr.Code, rr.Body.String())
+	}
+	results = search(t, router, owner, "email")
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if want := "Gen Z &lt;script&gt;alert(1)&lt;/script&gt; &amp; <mark>email</mark>"; results[0].Snippet != want {
+		t.Errorf("expected snippet %q, got %q", want, results[0].Snippet)
+	}
+
+	// Replacing an asset reindexes it
+	if rr := serve(t, router, "PUT", path, `{"type": "insight", "description": "Gen Z prefer video"}`, nil); rr.Code != http.StatusOK {
+		t.Fatalf("replace returned %v: %s", rr.Code, rr.Body.String())
+	}
+	if got := resultIDs(search(t, router, owner, "video")); !equalIDs(got, []string{"insight-2"}) {
+		t.Errorf("expected the replaced asset to be found by its new text, got %v", got)
+	}
+	if got := search(t, router, owner, "email"); len(got) != 0 {
+		t.Errorf("expected the old text to be gone from the index, got %v", resultIDs(got))
+	}
+}
+
+func TestAssetHandler_SearchAssets_BadRequests(t *testing.T) {
+	owner := uuid.New()
+	router := newSearchRouter(t, owner)
+	base := "/users/" + owner.String() + "/assets/search"
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", http.StatusUnprocessableEntity},
+		{"?q=%20%20", http.StatusUnprocessableEntity},
+		{"?q=gen&limit=0", http.StatusBadRequest},
+		{"?q=gen&limit=1", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if rr := serve(t, router, "GET", base+tt.query, "", nil); rr.Code != tt.want {
+			t.Errorf("%q: expected %v, got %v (%s)", tt.query, tt.want, rr.Code, rr.Body.String())
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	favouriteServices "assetsApp/internal/services/favourite"
+	shareServices "assetsApp/internal/services/share"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newSharingRouter wires the asset, favourite and share routes to one
+// MemoryStore in which owner has a single insight, "insight-1".
+func newSharingRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	store := storage.NewMemoryStore()
+	if err := store.Add(context.Background(), owner, &models.Insight{ID: "insight-1", Description: "shared"}); err != nil {
+		t.Fatal(err)
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	favouriteHandler := handlers.NewFavouriteHandler(favouriteServices.NewFavouriteService(store))
+	shareHandler := handlers.NewShareHandler(shareServices.NewShareService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.PatchAsset).Methods("PATCH")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
+	router.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
+	router.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.AddFavourite).Methods("POST")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares", shareHandler.GetShares).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares/{granteeId}", shareHandler.ShareAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares/{granteeId}", shareHandler.UnshareAsset).Methods("DELETE")
+	router.HandleFunc("/users/{userId}/shared", shareHandler.GetSharedWithMe).Methods("GET")
+	return router
+}
+
+func TestShareHandler_SharingLifecycle(t *testing.T) {
+	owner, viewer, stranger := uuid.New(), uuid.New(), uuid.New()
+	router := newSharingRouter(t, owner)
+	asset := func(user uuid.UUID) string { return "/users/" + user.String() + "/assets/insight-1" }
+	shareWith := func(user uuid.UUID) string { return asset(owner) + "/shares/" + user.String() }
+	patch := map[string]string{"Content-Type": "application/merge-patch+json"}
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		header map[string]string
+		want   int
+	}{
+		{"stranger cannot see the asset", "GET", asset(stranger), "", nil, http.StatusNotFound},
+		{"stranger cannot favourite it", "POST", "/users/" + stranger.String() + "/favourites/insight-1", `{"asset_type": "insight"}`, nil, http.StatusNotFound},
+		{"owner shares as viewer", "PUT", shareWith(viewer), `{"permission": "viewer"}`, nil, http.StatusOK},
+		{"viewer can read", "GET", asset(viewer), "", nil, http.StatusOK},
+		{"viewer cannot edit", "PATCH", asset(viewer), `{"description": "mine"}`, patch, http.StatusForbidden},
+		{"viewer cannot list shares", "GET", asset(viewer) + "/shares", "", nil, http.StatusForbidden},
+		{"viewer can favourite", "POST", "/users/" + viewer.String() + "/favourites/insight-1", `{"asset_type": "insight"}`, nil, http.StatusCreated},
+		{"owner upgrades to editor", "PUT", shareWith(viewer), `{"permission": "editor"}`, nil, http.StatusOK},
+		{"editor can edit", "PATCH", asset(viewer), `{"description": "edited"}`, patch, http.StatusOK},
+		{"editor cannot delete", "DELETE", asset(viewer), "", nil, http.StatusForbidden},
+		{"editor cannot share", "PUT", asset(viewer) + "/shares/" + stranger.String(), `{"permission": "viewer"}`, nil, http.StatusForbidden},
+		{"owner cannot grant ownership", "PUT", shareWith(stranger), `{"permission": "owner"}`, nil, http.StatusUnprocessableEntity},
+		{"owner cannot share with themselves", "PUT", shareWith(owner), `{"permission": "viewer"}`, nil, http.StatusUnprocessableEntity},
+		{"owner revokes", "DELETE", shareWith(viewer), "", nil, http.StatusOK},
+		{"revoking twice", "DELETE", shareWith(viewer), "", nil, http.StatusNotFound},
+		{"former grantee cannot read", "GET", asset(viewer), "", nil, http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rr := serve(t, router, step.method, step.path, step.body, step.header)
+		if rr.Code != step.want {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v (%s)", step.name, rr.Code, step.want, rr.Body.String())
+		}
+	}
+
+	rr := serve(t, router, "GET", "/users/"+viewer.String()+"/favourites", "", nil)
+	if rr.Code != http.StatusOK || rr.Body.String() != "{\"items\":[]}\n" {
+		t.Errorf("expected revoking to drop the favourite, got %v %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestShareHandler_GetSharedWithMe(t *testing.T) {
+	owner, grantee := uuid.New(), uuid.New()
+	router := newSharingRouter(t, owner)
+
+	rr := serve(t, router, "PUT", "/users/"+owner.String()+"/assets/insight-1/shares/"+grantee.String(), `{"permission": "editor"}`, nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("share returned %v: %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(t, router, "GET", "/users/"+grantee.String()+"/shared", "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var page struct {
+		Items []struct {
+			OwnerID    uuid.UUID         `json:"owner_id"`
+			Permission models.Permission `json:"permission"`
+			Asset      models.Insight    `json:"asset"`
+		} `json:"items"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&page); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(page.Items) != 1 {
+		t.Fatalf("expected 1 shared asset, got %d", len(page.Items))
+	}
+	got := page.Items[0]
+	if got.OwnerID != owner || got.Permission != models.PermissionEditor || got.Asset.ID != "insight-1" {
+		t.Errorf("unexpected shared asset: %+v", got)
+	}
+
+	rr = serve(t, router, "GET", "/users/"+owner.String()+"/assets/insight-1/shares", "", nil)
+	var shares []models.Share
+	if err := json.NewDecoder(rr.Body).Decode(&shares); err != nil {
+		t.Fatalf("failed to decode shares: %v", err)
+	}
+	if len(shares) != 1 || shares[0].UserID != grantee || shares[0].Permission != models.PermissionEditor {
+		t.Errorf("unexpected shares: %+v", shares)
+	}
+
+	rr = serve(t, router, "GET", "/users/"+owner.String()+"/shared", "", nil)
+	if rr.Body.String() != "{\"items\":[]}\n" {
+		t.Errorf("expected nothing shared with the owner, got %s", rr.Body.String())
+	}
+}
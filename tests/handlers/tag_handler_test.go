@@ -0,0 +1,174 @@
+package handlers_test
+
+import (
+	"assetsApp/internal/handlers"
+	"assetsApp/internal/models"
+	assetServices "assetsApp/internal/services/asset"
+	shareServices "assetsApp/internal/services/share"
+	tagServices "assetsApp/internal/services/tag"
+	"assetsApp/internal/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// newTagRouter wires the asset, share and tag routes to one MemoryStore in
+// which owner has the insights "insight-1" to "insight-3", tagged as
+// "insight-1": EMEA and Q3, "insight-2": EMEA, "insight-3": APAC and Q3.
+func newTagRouter(t *testing.T, owner uuid.UUID) *mux.Router {
+	t.Helper()
+	ctx := context.Background()
+	store := storage.NewMemoryStore()
+	tagged := map[string][]string{
+		"insight-1": {"EMEA", "Q3"},
+		"insight-2": {"EMEA"},
+		"insight-3": {"APAC", "Q3"},
+	}
+	for id, tags := range tagged {
+		if err := store.Add(ctx, owner, &models.Insight{ID: id}); err != nil {
+			t.Fatal(err)
+		}
+		for _, tag := range tags {
+			if err := store.AddTag(ctx, owner, id, tag); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	assetHandler := handlers.NewAssetHandler(assetServices.NewAssetService(store))
+	shareHandler := handlers.NewShareHandler(shareServices.NewShareService(store))
+	tagHandler := handlers.NewTagHandler(tagServices.NewTagService(store))
+
+	router := mux.NewRouter()
+	router.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/shares/{granteeId}", shareHandler.ShareAsset).Methods("PUT")
+	router.HandleFunc("/users/{userId}/tags", tagHandler.GetTagCloud).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/tags", tagHandler.GetTags).Methods("GET")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/tags/{tag}", tagHandler.AddTag).Methods("PUT")
+	router.HandleFunc("/users/{userId}/assets/{assetId}/tags/{tag}", tagHandler.RemoveTag).Methods("DELETE")
+	return router
+}
+
+func TestAssetHandler_GetAssets_TagFilter(t *testing.T) {
+	owner := uuid.New()
+	router := newTagRouter(t, owner)
+	base := "/users/" + owner.String() + "/assets"
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"one tag", "?tag=EMEA", []string{"insight-1", "insight-2"}},
+		{"all tags by default", "?tag=EMEA&tag=Q3", []string{"insight-1"}},
+		{"repeated tag", "?tag=EMEA&tag=EMEA", []string{"insight-1", "insight-2"}},
+		{"any tag", "?tag=EMEA&tag=APAC&tag_match=any", []string{"insight-1", "insight-2", "insight-3"}},
+		{"unknown tag", "?tag=LATAM", []string{}},
+		{"with other options", "?tag=Q3&sort=-id&limit=1", []string{"insight-3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collectionAssetIDs(t, router, base+tt.query); !equalIDs(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	for _, query := range []string{"?tag=", "?tag=EMEA&tag_match=some"} {
+		if rr := serve(t, router, "GET", base+query, "", nil); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %v", query, rr.Code)
+		}
+	}
+}
+
+// tagCloud fetches a user's tag cloud.
+func tagCloud(t *testing.T, router *mux.Router, user uuid.UUID) []models.TagCount {
+	t.Helper()
+	rr := serve(t, router, "GET", "/users/"+user.String()+"/tags", "", nil)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("tag cloud returned %v: %s", rr.Code, rr.Body.String())
+	}
+	var cloud []models.TagCount
+	if err := json.NewDecoder(rr.Body).Decode(&cloud); err != nil {
+		t.Fatalf("failed to decode tag cloud: %v", err)
+	}
+	return cloud
+}
+
+func TestTagHandler_TagCloud(t *testing.T) {
+	owner := uuid.New()
+	router := newTagRouter(t, owner)
+
+	want := []models.TagCount{{Tag: "EMEA", Count: 2}, {Tag: "Q3", Count: 2}, {Tag: "APAC", Count: 1}}
+	if got := tagCloud(t, router, owner); !equalCloud(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if rr := serve(t, router, "DELETE", "/users/"+owner.String()+"/assets/insight-1", "", nil); rr.Code != http.StatusOK {
+		t.Fatalf("delete returned %v: %s", rr.Code, rr.Body.String())
+	}
+	want = []models.TagCount{{Tag: "APAC", Count: 1}, {Tag: "EMEA", Count: 1}, {Tag: "Q3", Count: 1}}
+	if got := tagCloud(t, router, owner); !equalCloud(got, want) {
+		t.Errorf("expected deleting an asset to drop its tags, got %v", got)
+	}
+
+	if got := tagCloud(t, router, uuid.New()); len(got) != 0 {
+		t.Errorf("expected an empty tag cloud for a new user, got %v", got)
+	}
+}
+
+func equalCloud(a, b []models.TagCount) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTagHandler_TaggingLifecycle(t *testing.T) {
+	owner, viewer := uuid.New(), uuid.New()
+	router := newTagRouter(t, owner)
+	tags := func(user uuid.UUID) string { return "/users/" + user.String() + "/assets/insight-2/tags" }
+
+	steps := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"owner tags", "PUT", tags(owner) + "/gen-z", "", http.StatusOK},
+		{"tagging again is a no-op", "PUT", tags(owner) + "/gen-z", "", http.StatusOK},
+		{"blank tag", "PUT", tags(owner) + "/%20", "", http.StatusUnprocessableEntity},
+		{"unknown asset", "PUT", "/users/" + owner.String() + "/assets/missing/tags/Q3", "", http.StatusNotFound},
+		{"stranger cannot see the tags", "GET", tags(viewer), "", http.StatusNotFound},
+		{"owner shares as viewer", "PUT", "/users/" + owner.String() + "/assets/insight-2/shares/" + viewer.String(), `{"permission": "viewer"}`, http.StatusOK},
+		{"viewer can see the tags", "GET", tags(viewer), "", http.StatusOK},
+		{"viewer cannot tag", "PUT", tags(viewer) + "/mine", "", http.StatusForbidden},
+		{"owner untags", "DELETE", tags(owner) + "/EMEA", "", http.StatusOK},
+		{"untagging twice", "DELETE", tags(owner) + "/EMEA", "", http.StatusNotFound},
+	}
+	for _, step := range steps {
+		rr := serve(t, router, step.method, step.path, step.body, nil)
+		if rr.Code != step.want {
+			t.Fatalf("%s: handler returned wrong status code: got %v want %v (%s)", step.name, rr.Code, step.want, rr.Body.String())
+		}
+	}
+
+	rr := serve(t, router, "GET", tags(owner), "", nil)
+	var got []string
+	if err := json.NewDecoder(rr.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode tags: %v", err)
+	}
+	if !equalIDs(got, []string{"gen-z"}) {
+		t.Errorf("expected only gen-z to be left, got %v", got)
+	}
+}
@@ -2,6 +2,7 @@ package integration_test
 
 import (
 	"assetsApp/internal/handlers"
+	"assetsApp/internal/migrations"
 	assetServices "assetsApp/internal/services/asset"
 	favouriteServices "assetsApp/internal/services/favourite"
 	"assetsApp/internal/storage"
@@ -74,6 +75,7 @@ func TestMain(m *testing.M) {
 	router = mux.NewRouter()
 	router.HandleFunc("/users/{userId}/assets", assetHandler.GetAssets).Methods("GET")
 	router.HandleFunc("/users/{userId}/assets", assetHandler.AddAsset).Methods("POST")
+	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.GetAsset).Methods("GET")
 	router.HandleFunc("/users/{userId}/assets/{assetId}", assetHandler.RemoveAsset).Methods("DELETE")
 	router.HandleFunc("/users/{userId}/favourites", favouriteHandler.GetFavourites).Methods("GET")
 	router.HandleFunc("/users/{userId}/favourites/{assetId}", favouriteHandler.AddFavourite).Methods("POST")
@@ -95,53 +97,11 @@ func TestMain(m *testing.M) {
 }
 
 func createTables() error {
-	ctx := context.Background()
-	_, err := pool.Exec(ctx, `
-		CREATE TABLE users (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255)
-		);
-		CREATE TABLE assets (
-			asset_id VARCHAR(255) PRIMARY KEY,
-			title VARCHAR(255),
-			description TEXT,
-			asset_type VARCHAR(50),
-			user_id UUID REFERENCES users(id)
-		);
-		CREATE TABLE charts (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			title VARCHAR(255),
-			description TEXT,
-			x_axis_title VARCHAR(255),
-			y_axis_title VARCHAR(255)
-		);
-		CREATE TABLE chart_data (
-			chart_id VARCHAR(255) REFERENCES charts(id),
-			datapoint_code VARCHAR(255),
-			value FLOAT,
-			PRIMARY KEY (chart_id, datapoint_code)
-		);
-		CREATE TABLE insights (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			description TEXT
-		);
-		CREATE TABLE audiences (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			gender VARCHAR(50),
-			country VARCHAR(255),
-			age_group VARCHAR(50),
-			social_hours INT,
-			purchases INT,
-			description TEXT
-		);
-		CREATE TABLE favourites (
-			user_id UUID REFERENCES users(id),
-			asset_id VARCHAR(255) REFERENCES assets(asset_id),
-			asset_type VARCHAR(50),
-			PRIMARY KEY (user_id, asset_id)
-		);
-	`)
-	return err
+	migrator, err := migrations.NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(context.Background())
 }
 
 func cleanup() {
@@ -181,6 +141,14 @@ func TestIntegration_AssetAndFavouriteFlow(t *testing.T) {
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusCreated, w.Code)
+	location := w.Header().Get("Location")
+	assert.Equal(t, "/users/"+userID.String()+"/assets/"+assetID, location)
+
+	req = httptest.NewRequest("GET", location, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"type":"chart"`)
 
 	// 2. Add asset to favourites
 	favouritePayload := []byte(`{"asset_type": "chart"}`)
@@ -200,14 +168,14 @@ func TestIntegration_AssetAndFavouriteFlow(t *testing.T) {
 	req = httptest.NewRequest("DELETE", "/users/"+userID.String()+"/favourites/"+assetID, nil)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNoContent, w.Code)
 
 	// 5. Get favourites and verify empty
 	req = httptest.NewRequest("GET", "/users/"+userID.String()+"/favourites", nil)
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "[]\n", w.Body.String())
+	assert.Equal(t, "{\"items\":[]}\n", w.Body.String())
 
 	// 6. Remove asset
 	req = httptest.NewRequest("DELETE", "/users/"+userID.String()+"/assets/"+assetID, nil)
@@ -220,5 +188,5 @@ func TestIntegration_AssetAndFavouriteFlow(t *testing.T) {
 	w = httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Equal(t, "null\n", w.Body.String())
+	assert.Equal(t, "{\"items\":[]}\n", w.Body.String())
 }
@@ -0,0 +1,79 @@
+package migrations_test
+
+import (
+	"assetsApp/internal/migrations"
+	"strings"
+	"testing"
+)
+
+func TestLoad_ReturnsSequentialVersions(t *testing.T) {
+	migs, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	if len(migs) == 0 {
+		t.Fatal("expected embedded migrations, got none")
+	}
+
+	for i, m := range migs {
+		if m.Version != i+1 {
+			t.Errorf("migration %d has version %d", i, m.Version)
+		}
+		if strings.TrimSpace(m.Up) == "" || strings.TrimSpace(m.Down) == "" {
+			t.Errorf("migration %04d_%s is missing an up or down script", m.Version, m.Name)
+		}
+		if len(m.Checksum) != 64 {
+			t.Errorf("migration %04d_%s has checksum %q, want a sha256 hex digest", m.Version, m.Name, m.Checksum)
+		}
+	}
+}
+
+func TestLoad_InitialSchemaCreatesCoreTables(t *testing.T) {
+	migs, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	for _, table := range []string{"users", "assets", "favourites", "charts", "chart_data", "insights", "audiences"} {
+		if !strings.Contains(migs[0].Up, "CREATE TABLE IF NOT EXISTS "+table+" ") {
+			t.Errorf("expected 0001 to create table %s", table)
+		}
+	}
+}
+
+func TestLoad_ChecksumsAreStable(t *testing.T) {
+	first, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+	second, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	for i := range first {
+		if first[i].Checksum != second[i].Checksum {
+			t.Errorf("checksum of %04d_%s changed between loads", first[i].Version, first[i].Name)
+		}
+	}
+}
+
+// 0001 is the baseline schema that databases created before migrations
+// already have, so its CREATE TABLE IF NOT EXISTS statements do nothing
+// there. Columns added since must come from ALTER TABLE in later migrations.
+func TestLoad_InitialSchemaIsTheBaseline(t *testing.T) {
+	migs, err := migrations.Load()
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if strings.Contains(migs[0].Up, "created_at") {
+		t.Error("0001 should not add columns the baseline schema lacks")
+	}
+	if !strings.Contains(migs[1].Up, "ALTER TABLE assets ADD COLUMN IF NOT EXISTS created_at") {
+		t.Error("expected 0002 to add assets.created_at to baseline databases")
+	}
+	if !strings.Contains(migs[1].Down, "DROP COLUMN IF EXISTS created_at") {
+		t.Error("expected 0002 to drop assets.created_at when reverted")
+	}
+}
@@ -0,0 +1,53 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+)
+
+// MockBus is an in-memory storage.InvalidationBus. Publish hands the message
+// to every subscriber before it returns.
+type MockBus struct {
+	mu          sync.Mutex
+	subscribers map[int]func(message string)
+	next        int
+}
+
+func NewMockBus() *MockBus {
+	return &MockBus{subscribers: make(map[int]func(message string))}
+}
+
+func (b *MockBus) Publish(_ context.Context, message string) error {
+	b.mu.Lock()
+	handlers := make([]func(string), 0, len(b.subscribers))
+	for _, handle := range b.subscribers {
+		handlers = append(handlers, handle)
+	}
+	b.mu.Unlock()
+	for _, handle := range handlers {
+		handle(message)
+	}
+	return nil
+}
+
+func (b *MockBus) Subscribe(ctx context.Context, handle func(message string)) error {
+	b.mu.Lock()
+	id := b.next
+	b.next++
+	b.subscribers[id] = handle
+	b.mu.Unlock()
+
+	<-ctx.Done()
+
+	b.mu.Lock()
+	delete(b.subscribers, id)
+	b.mu.Unlock()
+	return ctx.Err()
+}
+
+// Subscribers counts the current subscriptions.
+func (b *MockBus) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
@@ -0,0 +1,137 @@
+package mocks
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MockCache is an in-memory storage.Cache. Entries never expire. It is safe
+// for concurrent use.
+type MockCache struct {
+	mu      sync.Mutex
+	entries map[string]string
+	sets    map[string]map[string]bool
+	gets    int
+	err     error         // returned by every call, if set
+	delay   time.Duration // every call takes this long, or until its ctx is done
+}
+
+func NewMockCache() *MockCache {
+	return &MockCache{
+		entries: make(map[string]string),
+		sets:    make(map[string]map[string]bool),
+	}
+}
+
+func (c *MockCache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	c.gets++
+	c.mu.Unlock()
+	if err := c.wait(ctx); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key], nil
+}
+
+func (c *MockCache) Set(ctx context.Context, key string, value string) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = value
+	return nil
+}
+
+func (c *MockCache) Del(ctx context.Context, keys ...string) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range keys {
+		delete(c.entries, key)
+		delete(c.sets, key)
+	}
+	return nil
+}
+
+func (c *MockCache) SAdd(ctx context.Context, key string, members ...string) error {
+	if err := c.wait(ctx); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sets[key] == nil {
+		c.sets[key] = make(map[string]bool)
+	}
+	for _, m := range members {
+		c.sets[key][m] = true
+	}
+	return nil
+}
+
+func (c *MockCache) SMembers(ctx context.Context, key string) ([]string, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	members := make([]string, 0, len(c.sets[key]))
+	for m := range c.sets[key] {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// Keys lists the keys holding a value, sorted.
+func (c *MockCache) Keys() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Gets counts the calls to Get.
+func (c *MockCache) Gets() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.gets
+}
+
+// Fail makes every call return err, until Fail(nil).
+func (c *MockCache) Fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.err = err
+}
+
+// Slow makes every call take d.
+func (c *MockCache) Slow(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.delay = d
+}
+
+func (c *MockCache) wait(ctx context.Context) error {
+	c.mu.Lock()
+	err, delay := c.err, c.delay
+	c.mu.Unlock()
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
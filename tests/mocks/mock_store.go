@@ -1,67 +1,279 @@
-
 package mocks
 
 import (
 	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+
 	"github.com/google/uuid"
 )
 
 // MockAssetStore is a mock implementation of the AssetStore interface.
 type MockAssetStore struct {
-	GetFunc             func(userID uuid.UUID) []models.Asset
-	AddFunc             func(userID uuid.UUID, asset models.Asset)
-	RemoveFunc          func(userID uuid.UUID, assetID string) bool
-	EditDescriptionFunc func(userID uuid.UUID, assetID, newDesc string) bool
+	GetFunc     func(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.AssetPage, error)
+	GetByIDFunc func(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error)
+	AddFunc     func(ctx context.Context, userID uuid.UUID, asset models.Asset) error
+	RemoveFunc  func(ctx context.Context, userID uuid.UUID, assetID string, version int64) error
+	UpdateFunc  func(ctx context.Context, userID uuid.UUID, asset models.Asset) error
+
+	GetFavouritesFunc   func(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.FavouritePage, error)
+	AddFavouriteFunc    func(ctx context.Context, userID uuid.UUID, assetID, assetType string) error
+	RemoveFavouriteFunc func(ctx context.Context, userID uuid.UUID, assetID string) error
+	UpdateFavouriteFunc func(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error
+	MoveFavouriteFunc   func(ctx context.Context, userID uuid.UUID, assetID, targetID string, after bool) error
+
+	AccessFunc          func(ctx context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error)
+	GetSharesFunc       func(ctx context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error)
+	ShareFunc           func(ctx context.Context, share models.Share) error
+	UnshareFunc         func(ctx context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error
+	GetSharedWithMeFunc func(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.SharedPage, error)
+
+	ListUsersFunc func(ctx context.Context, opts storage.ListOptions) (storage.UserPage, error)
+	ReassignFunc  func(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error)
+	PurgeUserFunc func(ctx context.Context, userID uuid.UUID) error
+
+	GetCollectionsFunc       func(ctx context.Context, userID uuid.UUID) ([]models.Collection, error)
+	GetCollectionFunc        func(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error)
+	AddCollectionFunc        func(ctx context.Context, userID uuid.UUID, collection *models.Collection) error
+	RenameCollectionFunc     func(ctx context.Context, userID, collectionID uuid.UUID, name string) error
+	RemoveCollectionFunc     func(ctx context.Context, userID, collectionID uuid.UUID) error
+	GetCollectionAssetsFunc  func(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error)
+	AddToCollectionFunc      func(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error
+	RemoveFromCollectionFunc func(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error
+	SetCollectionAssetsFunc  func(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) error
+
+	GetTagsFunc   func(ctx context.Context, ownerID uuid.UUID, assetID string) ([]string, error)
+	AddTagFunc    func(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error
+	RemoveTagFunc func(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error
+	TagCountsFunc func(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error)
 
-	GetFavouritesFunc   func(userID uuid.UUID) []models.Favourite
-	AddFavouriteFunc    func(userID uuid.UUID, assetID, assetType string) bool
-	RemoveFavouriteFunc func(userID uuid.UUID, assetID string) bool
+	SearchFunc func(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error)
 }
 
-func (m *MockAssetStore) Get(userID uuid.UUID) []models.Asset {
+func (m *MockAssetStore) Get(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.AssetPage, error) {
 	if m.GetFunc != nil {
-		return m.GetFunc(userID)
+		return m.GetFunc(ctx, userID, opts)
 	}
-	return nil
+	return storage.AssetPage{}, nil
+}
+
+func (m *MockAssetStore) GetByID(ctx context.Context, userID uuid.UUID, assetID string) (models.Asset, error) {
+	if m.GetByIDFunc != nil {
+		return m.GetByIDFunc(ctx, userID, assetID)
+	}
+	return nil, storage.ErrNotFound
 }
 
-func (m *MockAssetStore) Add(userID uuid.UUID, asset models.Asset) {
+func (m *MockAssetStore) Add(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
 	if m.AddFunc != nil {
-		m.AddFunc(userID, asset)
+		return m.AddFunc(ctx, userID, asset)
 	}
+	return nil
 }
 
-func (m *MockAssetStore) Remove(userID uuid.UUID, assetID string) bool {
+func (m *MockAssetStore) Remove(ctx context.Context, userID uuid.UUID, assetID string, version int64) error {
 	if m.RemoveFunc != nil {
-		return m.RemoveFunc(userID, assetID)
+		return m.RemoveFunc(ctx, userID, assetID, version)
 	}
-	return false
+	return nil
 }
 
-func (m *MockAssetStore) EditDescription(userID uuid.UUID, assetID, newDesc string) bool {
-	if m.EditDescriptionFunc != nil {
-		return m.EditDescriptionFunc(userID, assetID, newDesc)
+func (m *MockAssetStore) Update(ctx context.Context, userID uuid.UUID, asset models.Asset) error {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(ctx, userID, asset)
 	}
-	return false
+	return nil
 }
 
-func (m *MockAssetStore) GetFavourites(userID uuid.UUID) []models.Favourite {
+func (m *MockAssetStore) GetFavourites(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.FavouritePage, error) {
 	if m.GetFavouritesFunc != nil {
-		return m.GetFavouritesFunc(userID)
+		return m.GetFavouritesFunc(ctx, userID, opts)
 	}
-	return nil
+	return storage.FavouritePage{}, nil
 }
 
-func (m *MockAssetStore) AddFavourite(userID uuid.UUID, assetID, assetType string) bool {
+func (m *MockAssetStore) AddFavourite(ctx context.Context, userID uuid.UUID, assetID, assetType string) error {
 	if m.AddFavouriteFunc != nil {
-		return m.AddFavouriteFunc(userID, assetID, assetType)
+		return m.AddFavouriteFunc(ctx, userID, assetID, assetType)
 	}
-	return false
+	return nil
 }
 
-func (m *MockAssetStore) RemoveFavourite(userID uuid.UUID, assetID string) bool {
+func (m *MockAssetStore) RemoveFavourite(ctx context.Context, userID uuid.UUID, assetID string) error {
 	if m.RemoveFavouriteFunc != nil {
-		return m.RemoveFavouriteFunc(userID, assetID)
+		return m.RemoveFavouriteFunc(ctx, userID, assetID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) UpdateFavourite(ctx context.Context, userID uuid.UUID, assetID string, update models.FavouriteUpdate) error {
+	if m.UpdateFavouriteFunc != nil {
+		return m.UpdateFavouriteFunc(ctx, userID, assetID, update)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) MoveFavourite(ctx context.Context, userID uuid.UUID, assetID, targetID string, after bool) error {
+	if m.MoveFavouriteFunc != nil {
+		return m.MoveFavouriteFunc(ctx, userID, assetID, targetID, after)
+	}
+	return nil
+}
+
+// Access defaults to making the caller the asset's owner, so tests that don't
+// exercise sharing behave as before.
+func (m *MockAssetStore) Access(ctx context.Context, userID uuid.UUID, assetID string) (uuid.UUID, models.Permission, error) {
+	if m.AccessFunc != nil {
+		return m.AccessFunc(ctx, userID, assetID)
+	}
+	return userID, models.PermissionOwner, nil
+}
+
+func (m *MockAssetStore) GetShares(ctx context.Context, ownerID uuid.UUID, assetID string) ([]models.Share, error) {
+	if m.GetSharesFunc != nil {
+		return m.GetSharesFunc(ctx, ownerID, assetID)
+	}
+	return nil, nil
+}
+
+func (m *MockAssetStore) Share(ctx context.Context, share models.Share) error {
+	if m.ShareFunc != nil {
+		return m.ShareFunc(ctx, share)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) Unshare(ctx context.Context, ownerID uuid.UUID, assetID string, userID uuid.UUID) error {
+	if m.UnshareFunc != nil {
+		return m.UnshareFunc(ctx, ownerID, assetID, userID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) GetSharedWithMe(ctx context.Context, userID uuid.UUID, opts storage.ListOptions) (storage.SharedPage, error) {
+	if m.GetSharedWithMeFunc != nil {
+		return m.GetSharedWithMeFunc(ctx, userID, opts)
+	}
+	return storage.SharedPage{}, nil
+}
+
+func (m *MockAssetStore) ListUsers(ctx context.Context, opts storage.ListOptions) (storage.UserPage, error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(ctx, opts)
+	}
+	return storage.UserPage{}, nil
+}
+
+func (m *MockAssetStore) Reassign(ctx context.Context, assetID string, newOwnerID uuid.UUID) (uuid.UUID, error) {
+	if m.ReassignFunc != nil {
+		return m.ReassignFunc(ctx, assetID, newOwnerID)
+	}
+	return uuid.Nil, nil
+}
+
+func (m *MockAssetStore) PurgeUser(ctx context.Context, userID uuid.UUID) error {
+	if m.PurgeUserFunc != nil {
+		return m.PurgeUserFunc(ctx, userID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) GetCollections(ctx context.Context, userID uuid.UUID) ([]models.Collection, error) {
+	if m.GetCollectionsFunc != nil {
+		return m.GetCollectionsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+// GetCollection reports every collection as missing unless GetCollectionFunc
+// is set.
+func (m *MockAssetStore) GetCollection(ctx context.Context, userID, collectionID uuid.UUID) (models.Collection, error) {
+	if m.GetCollectionFunc != nil {
+		return m.GetCollectionFunc(ctx, userID, collectionID)
+	}
+	return models.Collection{}, storage.ErrNotFound
+}
+
+func (m *MockAssetStore) AddCollection(ctx context.Context, userID uuid.UUID, collection *models.Collection) error {
+	if m.AddCollectionFunc != nil {
+		return m.AddCollectionFunc(ctx, userID, collection)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) RenameCollection(ctx context.Context, userID, collectionID uuid.UUID, name string) error {
+	if m.RenameCollectionFunc != nil {
+		return m.RenameCollectionFunc(ctx, userID, collectionID, name)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) RemoveCollection(ctx context.Context, userID, collectionID uuid.UUID) error {
+	if m.RemoveCollectionFunc != nil {
+		return m.RemoveCollectionFunc(ctx, userID, collectionID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) GetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID) ([]models.Asset, error) {
+	if m.GetCollectionAssetsFunc != nil {
+		return m.GetCollectionAssetsFunc(ctx, userID, collectionID)
+	}
+	return nil, nil
+}
+
+func (m *MockAssetStore) AddToCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	if m.AddToCollectionFunc != nil {
+		return m.AddToCollectionFunc(ctx, userID, collectionID, assetID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) RemoveFromCollection(ctx context.Context, userID, collectionID uuid.UUID, assetID string) error {
+	if m.RemoveFromCollectionFunc != nil {
+		return m.RemoveFromCollectionFunc(ctx, userID, collectionID, assetID)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) SetCollectionAssets(ctx context.Context, userID, collectionID uuid.UUID, assetIDs []string) error {
+	if m.SetCollectionAssetsFunc != nil {
+		return m.SetCollectionAssetsFunc(ctx, userID, collectionID, assetIDs)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) GetTags(ctx context.Context, ownerID uuid.UUID, assetID string) ([]string, error) {
+	if m.GetTagsFunc != nil {
+		return m.GetTagsFunc(ctx, ownerID, assetID)
+	}
+	return []string{}, nil
+}
+
+func (m *MockAssetStore) AddTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	if m.AddTagFunc != nil {
+		return m.AddTagFunc(ctx, ownerID, assetID, tag)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) RemoveTag(ctx context.Context, ownerID uuid.UUID, assetID, tag string) error {
+	if m.RemoveTagFunc != nil {
+		return m.RemoveTagFunc(ctx, ownerID, assetID, tag)
+	}
+	return nil
+}
+
+func (m *MockAssetStore) TagCounts(ctx context.Context, userID uuid.UUID) ([]models.TagCount, error) {
+	if m.TagCountsFunc != nil {
+		return m.TagCountsFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockAssetStore) Search(ctx context.Context, userID uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+	if m.SearchFunc != nil {
+		return m.SearchFunc(ctx, userID, query, limit)
 	}
-	return false
+	return nil, nil
 }
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"assetsApp/internal/models"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	original := &models.Audience{ID: "a1", Gender: "Male", Country: "GR", AgeGroup: "24-35", Purchases: 4, Description: "old"}
+
+	patched, err := models.ApplyMergePatch(original, map[string]interface{}{
+		"country":     "IT",
+		"description": nil,
+		"purchases":   float64(5),
+	})
+	if err != nil {
+		t.Fatalf("ApplyMergePatch returned error: %v", err)
+	}
+
+	want := &models.Audience{ID: "a1", Gender: "Male", Country: "IT", AgeGroup: "24-35", Purchases: 5}
+	if !reflect.DeepEqual(patched, want) {
+		t.Errorf("expected %+v, got %+v", want, patched)
+	}
+	if original.Country != "GR" {
+		t.Error("ApplyMergePatch must not modify the original asset")
+	}
+}
+
+func TestApplyMergePatch_Violations(t *testing.T) {
+	chart := &models.Chart{ID: "c1", Data: []models.ChartData{{DatapointCode: "A", Value: 1}}}
+	tests := map[string]map[string]interface{}{
+		"change id":     {"id": "c2"},
+		"change type":   {"type": "insight"},
+		"clear id":      {"id": nil},
+		"unknown field": {"colour": "red"},
+		"invalid data":  {"data": []interface{}{map[string]interface{}{"value": 2}}},
+		"wrong type":    {"title": 3},
+	}
+	for name, patch := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := models.ApplyMergePatch(chart, patch)
+			var verr *models.ValidationError
+			if !errors.As(err, &verr) {
+				t.Errorf("expected *models.ValidationError, got %v", err)
+			}
+		})
+	}
+}
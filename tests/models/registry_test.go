@@ -0,0 +1,131 @@
+package models_test
+
+import (
+	"assetsApp/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestCreateAsset_UsesRegisteredType(t *testing.T) {
+	asset, err := models.CreateAsset("chart", map[string]interface{}{
+		"id":    "chart-1",
+		"title": "Sales",
+		"data":  []interface{}{map[string]interface{}{"datapoint_code": "SM_AGE_18_24", "value": 4.5}},
+	})
+	if err != nil {
+		t.Fatalf("CreateAsset returned error: %v", err)
+	}
+	chart, ok := asset.(*models.Chart)
+	if !ok {
+		t.Fatalf("expected *models.Chart, got %T", asset)
+	}
+	if chart.ID != "chart-1" || chart.Title != "Sales" || len(chart.Data) != 1 {
+		t.Errorf("unexpected chart: %+v", chart)
+	}
+}
+
+func TestCreateAsset_UnknownType(t *testing.T) {
+	if _, err := models.CreateAsset("map", map[string]interface{}{"id": "m1"}); err == nil {
+		t.Fatal("expected an error for an unregistered type")
+	}
+}
+
+func TestCreateAsset_RunsValidation(t *testing.T) {
+	tests := map[string]struct {
+		assetType string
+		data      map[string]interface{}
+	}{
+		"missing id": {"insight", map[string]interface{}{"description": "no id"}},
+		"empty datapoint code": {"chart", map[string]interface{}{
+			"id":   "chart-1",
+			"data": []interface{}{map[string]interface{}{"datapoint_code": "", "value": 1}},
+		}},
+		"wrong field type": {"audience", map[string]interface{}{"id": "a1", "purchases": "many"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := models.CreateAsset(tc.assetType, tc.data); err == nil {
+				t.Fatal("expected a validation error")
+			}
+		})
+	}
+}
+
+func TestTypeNames_ListsBuiltInTypes(t *testing.T) {
+	want := []string{"audience", "chart", "insight"}
+	if got := models.TypeNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestEncodeDecodeAsset_RoundTrip(t *testing.T) {
+	assets := []models.Asset{
+		&models.Chart{ID: "c1", Title: "Chart", Data: []models.ChartData{{DatapointCode: "X", Value: 2}}},
+		&models.Insight{ID: "i1", Description: "Insight"},
+		&models.Audience{ID: "a1", Gender: "Female", Country: "GR", AgeGroup: "24-35", Purchases: 3},
+	}
+	for _, want := range assets {
+		typed, err := models.EncodeAsset(want)
+		if err != nil {
+			t.Fatalf("EncodeAsset(%s) returned error: %v", want.GetType(), err)
+		}
+		if typed.Type != want.GetType() {
+			t.Errorf("expected type %q, got %q", want.GetType(), typed.Type)
+		}
+		got, err := models.DecodeAsset(typed)
+		if err != nil {
+			t.Fatalf("DecodeAsset(%s) returned error: %v", want.GetType(), err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("round trip mismatch: expected %+v, got %+v", want, got)
+		}
+	}
+}
+
+func TestDecodeAsset_UnknownType(t *testing.T) {
+	if _, err := models.DecodeAsset(models.TypedAsset{Type: "map", Data: []byte(`{}`)}); err == nil {
+		t.Fatal("expected an error for an unregistered type")
+	}
+}
+
+func TestRegister_PanicsOnDuplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Register to panic on a duplicate type")
+		}
+	}()
+	models.Register(models.AssetType{Name: "chart", New: func() models.Asset { return &models.Chart{} }})
+}
+
+func TestRegister_PanicsOnMismatchedType(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected Register to panic when New returns another type")
+		}
+	}()
+	models.Register(models.AssetType{Name: "report", New: func() models.Asset { return &models.Insight{} }})
+}
+
+func TestSearchTextOf_UsesTheTypesSearchFunc(t *testing.T) {
+	tests := []struct {
+		name  string
+		asset models.Asset
+		want  models.SearchText
+	}{
+		{"title and description by default", &models.Insight{ID: "i1", Description: "Gen Z purchases"},
+			models.SearchText{Title: "Insight", Body: "Gen Z purchases"}},
+		{"chart axis titles and codes", &models.Chart{ID: "c1", Title: "Sales", XAxisTitle: "Month", YAxisTitle: "Revenue",
+			Data: []models.ChartData{{DatapointCode: "JAN"}, {DatapointCode: "FEB"}}},
+			models.SearchText{Title: "Sales", Details: "Month Revenue", Codes: "JAN FEB"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := models.SearchTextOf(tt.asset); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+	if got, want := tests[1].want.String(), "Sales Month Revenue JAN FEB"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,78 @@
+package models_test
+
+import (
+	"assetsApp/internal/models"
+	"errors"
+	"testing"
+)
+
+// violationFields runs CreateAsset and returns the fields it reports.
+func violationFields(t *testing.T, assetType string, data map[string]interface{}) []string {
+	t.Helper()
+	_, err := models.CreateAsset(assetType, data)
+	if err == nil {
+		return nil
+	}
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected *models.ValidationError, got %T: %v", err, err)
+	}
+	fields := make([]string, len(verr.Violations))
+	for i, v := range verr.Violations {
+		fields[i] = v.Field
+	}
+	return fields
+}
+
+func TestCreateAsset_Violations(t *testing.T) {
+	tests := map[string]struct {
+		assetType string
+		data      map[string]interface{}
+		want      []string
+	}{
+		"valid audience": {"audience", map[string]interface{}{
+			"type": "audience", "id": "a1", "gender": "Male", "age_group": "24-35", "social_hours": 2, "purchases": 0,
+		}, nil},
+		"blank id":         {"insight", map[string]interface{}{"id": "  "}, []string{"id"}},
+		"unknown type":     {"map", map[string]interface{}{"id": "m1"}, []string{"type"}},
+		"unknown field":    {"insight", map[string]interface{}{"id": "i1", "title": "x"}, []string{"title"}},
+		"wrong type":       {"audience", map[string]interface{}{"id": "a1", "social_hours": "lots"}, []string{"social_hours"}},
+		"gender":           {"audience", map[string]interface{}{"id": "a1", "gender": "male"}, []string{"gender"}},
+		"age group format": {"audience", map[string]interface{}{"id": "a1", "age_group": "24 to 35"}, []string{"age_group"}},
+		"age group bounds": {"audience", map[string]interface{}{"id": "a1", "age_group": "35-24"}, []string{"age_group"}},
+		"negative counts":  {"audience", map[string]interface{}{"id": "a1", "social_hours": -1, "purchases": -2}, []string{"social_hours", "purchases"}},
+		"empty datapoint code": {"chart", map[string]interface{}{
+			"id":   "c1",
+			"data": []interface{}{map[string]interface{}{"datapoint_code": "A", "value": 1}, map[string]interface{}{"value": 2}},
+		}, []string{"data[1].datapoint_code"}},
+		"duplicated datapoint code": {"chart", map[string]interface{}{
+			"id": "c1",
+			"data": []interface{}{
+				map[string]interface{}{"datapoint_code": "A", "value": 1},
+				map[string]interface{}{"datapoint_code": "B", "value": 2},
+				map[string]interface{}{"datapoint_code": "A", "value": 3},
+			},
+		}, []string{"data[2].datapoint_code"}},
+		"unknown nested field": {"chart", map[string]interface{}{
+			"id":   "c1",
+			"data": []interface{}{map[string]interface{}{"datapoint_code": "A", "colour": "red"}},
+		}, []string{"data.colour"}},
+		"all at once": {"audience", map[string]interface{}{
+			"gender": "x", "purchases": "many", "extra": true,
+		}, []string{"extra", "purchases", "id", "gender"}},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := violationFields(t, tc.assetType, tc.data)
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected violations for %v, got %v", tc.want, got)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("expected violations for %v, got %v", tc.want, got)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"assetsApp/internal/auth"
+	"assetsApp/internal/models"
+	apiKeyServices "assetsApp/internal/services/apikey"
+	"assetsApp/internal/storage"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyService_CreateAndVerify(t *testing.T) {
+	service := apiKeyServices.NewAPIKeyService(storage.NewMemoryStore())
+	ctx := context.Background()
+
+	key, secret, err := service.CreateKey(ctx, "dashboards", []string{auth.ScopeAssetsRead}, nil)
+	if err != nil {
+		t.Fatalf("CreateKey returned error: %v", err)
+	}
+	if !strings.HasPrefix(secret, "ak_"+key.Prefix+"_") {
+		t.Errorf("expected the key to start with its prefix %q, got %q", key.Prefix, secret)
+	}
+
+	p, err := service.VerifyAPIKey(ctx, secret)
+	if err != nil {
+		t.Fatalf("VerifyAPIKey returned error: %v", err)
+	}
+	if p.APIKeyID != key.ID || !p.HasScope(auth.ScopeAssetsRead) || p.HasScope(auth.ScopeAssetsWrite) {
+		t.Errorf("unexpected principal %+v", p)
+	}
+
+	for _, bad := range []string{secret + "0", "ak_000000000000_" + strings.Repeat("0", 64), "not-a-key"} {
+		if _, err := service.VerifyAPIKey(ctx, bad); !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("VerifyAPIKey(%q): expected ErrInvalidToken, got %v", bad, err)
+		}
+	}
+}
+
+func TestAPIKeyService_Revoke(t *testing.T) {
+	service := apiKeyServices.NewAPIKeyService(storage.NewMemoryStore())
+	ctx := context.Background()
+
+	key, secret, err := service.CreateKey(ctx, "dashboards", []string{auth.ScopeAssetsRead}, nil)
+	if err != nil {
+		t.Fatalf("CreateKey returned error: %v", err)
+	}
+	if err := service.RevokeKey(ctx, key.ID); err != nil {
+		t.Fatalf("RevokeKey returned error: %v", err)
+	}
+	if _, err := service.VerifyAPIKey(ctx, secret); !errors.Is(err, auth.ErrInvalidToken) {
+		t.Errorf("expected a revoked key to be rejected, got %v", err)
+	}
+
+	keys, err := service.ListKeys(ctx)
+	if err != nil {
+		t.Fatalf("ListKeys returned error: %v", err)
+	}
+	if len(keys) != 1 || keys[0].RevokedAt == nil {
+		t.Errorf("expected the revoked key to stay listed, got %+v", keys)
+	}
+}
+
+func TestAPIKeyService_CreateKeyValidation(t *testing.T) {
+	service := apiKeyServices.NewAPIKeyService(storage.NewMemoryStore())
+	past := time.Now().Add(-time.Hour)
+
+	_, _, err := service.CreateKey(context.Background(), " ", []string{auth.ScopeAssetsRead, "admin"}, &past)
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	var fields []string
+	for _, v := range verr.Violations {
+		fields = append(fields, v.Field)
+	}
+	if got := strings.Join(fields, ","); got != "name,scopes[1],expires_at" {
+		t.Errorf("unexpected violations %s", got)
+	}
+}
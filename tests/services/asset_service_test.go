@@ -3,7 +3,11 @@ package services_test
 import (
 	"assetsApp/internal/models"
 	assetServices "assetsApp/internal/services/asset"
+	"assetsApp/internal/storage"
 	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/google/uuid"
@@ -12,20 +16,23 @@ import (
 func TestAssetService_GetAssets(t *testing.T) {
 	userID := uuid.New()
 	mockStore := &mocks.MockAssetStore{
-		GetFunc: func(uid uuid.UUID) []models.Asset {
+		GetFunc: func(_ context.Context, uid uuid.UUID, _ storage.ListOptions) (storage.AssetPage, error) {
 			if uid == userID {
-				return []models.Asset{&models.Chart{ID: "test-chart"}}
+				return storage.AssetPage{Items: []models.Asset{&models.Chart{ID: "test-chart"}}}, nil
 			}
-			return nil
+			return storage.AssetPage{}, nil
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	assets := service.GetAssets(userID)
+	page, err := service.GetAssets(context.Background(), userID, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	if len(assets) != 1 {
-		t.Errorf("expected 1 asset, got %d", len(assets))
+	if len(page.Items) != 1 {
+		t.Errorf("expected 1 asset, got %d", len(page.Items))
 	}
 }
 
@@ -34,69 +41,107 @@ func TestAssetService_AddAsset(t *testing.T) {
 	asset := &models.Chart{ID: "test-chart"}
 	called := false
 	mockStore := &mocks.MockAssetStore{
-		AddFunc: func(uid uuid.UUID, a models.Asset) {
+		AddFunc: func(_ context.Context, uid uuid.UUID, a models.Asset) error {
 			if uid == userID && a.GetID() == asset.ID {
 				called = true
 			}
+			return nil
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	service.AddAsset(userID, asset)
+	if err := service.AddAsset(context.Background(), userID, asset); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if !called {
 		t.Error("AddAsset was not called on the store")
 	}
 }
 
-func TestAssetService_EditDescription(t *testing.T) {
+func TestAssetService_GetAsset(t *testing.T) {
 	userID := uuid.New()
-	assetID := "test-asset"
-	description := "New Description"
 	mockStore := &mocks.MockAssetStore{
-		EditDescriptionFunc: func(uid uuid.UUID, aid string, desc string) bool {
-			return uid == userID && aid == assetID && desc == description
+		GetByIDFunc: func(_ context.Context, uid uuid.UUID, assetID string) (models.Asset, error) {
+			if uid == userID && assetID == "test-chart" {
+				return &models.Chart{ID: assetID}, nil
+			}
+			return nil, storage.ErrNotFound
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	if !service.EditDescription(userID, assetID, description) {
-		t.Error("EditDescription returned false")
+	asset, err := service.GetAsset(context.Background(), userID, "test-chart")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asset.GetID() != "test-chart" {
+		t.Errorf("expected asset test-chart, got %s", asset.GetID())
+	}
+
+	if _, err := service.GetAsset(context.Background(), userID, "missing"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
 	}
 }
 
-func TestAssetService_RemoveAsset(t *testing.T) {
+func TestAssetService_PatchAsset(t *testing.T) {
 	userID := uuid.New()
-	assetID := "test-asset"
+	var updated models.Asset
 	mockStore := &mocks.MockAssetStore{
-		RemoveFunc: func(uid uuid.UUID, aid string) bool {
-			return uid == userID && aid == assetID
+		GetByIDFunc: func(_ context.Context, uid uuid.UUID, assetID string) (models.Asset, error) {
+			return &models.Insight{ID: assetID, Description: "old"}, nil
+		},
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			updated = asset
+			return nil
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	if !service.RemoveAsset(userID, assetID) {
-		t.Error("RemoveAsset returned false")
+	asset, err := service.PatchAsset(context.Background(), userID, "insight-1", map[string]interface{}{"description": "new"}, storage.AnyVersion)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asset.GetDescription() != "new" || updated != asset {
+		t.Errorf("expected the patched asset to be stored and returned, got %+v (stored %+v)", asset, updated)
 	}
 }
 
-func TestAssetService_EditNonExistentAsset(t *testing.T) {
+func TestAssetService_PatchMissingAsset(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		UpdateFunc: func(_ context.Context, uid uuid.UUID, asset models.Asset) error {
+			t.Error("Update should not be called for a missing asset")
+			return nil
+		},
+	}
+
+	service := assetServices.NewAssetService(mockStore)
+
+	_, err := service.PatchAsset(context.Background(), uuid.New(), "missing", map[string]interface{}{"description": "new"}, storage.AnyVersion)
+	if !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAssetService_RemoveAsset(t *testing.T) {
 	userID := uuid.New()
-	assetID := "non-existent-asset"
-	description := "New Description"
+	assetID := "test-asset"
 	mockStore := &mocks.MockAssetStore{
-		EditDescriptionFunc: func(uid uuid.UUID, aid string, desc string) bool {
-			return false // Simulate asset not found
+		RemoveFunc: func(_ context.Context, uid uuid.UUID, aid string, _ int64) error {
+			if uid == userID && aid == assetID {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	if service.EditDescription(userID, assetID, description) {
-		t.Error("EditDescription returned true for non-existent asset")
+	if err := service.RemoveAsset(context.Background(), userID, assetID, storage.AnyVersion); err != nil {
+		t.Errorf("RemoveAsset returned error: %v", err)
 	}
 }
 
@@ -104,14 +149,85 @@ func TestAssetService_RemoveNonExistentAsset(t *testing.T) {
 	userID := uuid.New()
 	assetID := "non-existent-asset"
 	mockStore := &mocks.MockAssetStore{
-		RemoveFunc: func(uid uuid.UUID, aid string) bool {
-			return false // Simulate asset not found
+		RemoveFunc: func(_ context.Context, uid uuid.UUID, aid string, _ int64) error {
+			return storage.ErrNotFound // Simulate asset not found
 		},
 	}
 
 	service := assetServices.NewAssetService(mockStore)
 
-	if service.RemoveAsset(userID, assetID) {
-		t.Error("RemoveAsset returned true for non-existent asset")
+	if err := service.RemoveAsset(context.Background(), userID, assetID, storage.AnyVersion); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for non-existent asset, got %v", err)
+	}
+}
+
+func TestAssetService_PassesContextToStore(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	var got context.Context
+	mockStore := &mocks.MockAssetStore{
+		GetFunc: func(c context.Context, _ uuid.UUID, _ storage.ListOptions) (storage.AssetPage, error) {
+			got = c
+			return storage.AssetPage{}, nil
+		},
+	}
+
+	service := assetServices.NewAssetService(mockStore)
+
+	if _, err := service.GetAssets(ctx, uuid.New(), storage.ListOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got == nil || got.Value(ctxKey{}) != "request" {
+		t.Error("request context was not passed to the store")
+	}
+}
+
+func TestAssetService_GetAssetsPassesListOptions(t *testing.T) {
+	opts := storage.ListOptions{Limit: 10, Sort: storage.SortByTitle, Desc: true}
+	var got storage.ListOptions
+	mockStore := &mocks.MockAssetStore{
+		GetFunc: func(_ context.Context, _ uuid.UUID, o storage.ListOptions) (storage.AssetPage, error) {
+			got = o
+			return storage.AssetPage{NextCursor: "next"}, nil
+		},
+	}
+
+	service := assetServices.NewAssetService(mockStore)
+
+	page, err := service.GetAssets(context.Background(), uuid.New(), opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, opts) {
+		t.Errorf("expected options %+v to reach the store, got %+v", opts, got)
+	}
+	if page.NextCursor != "next" {
+		t.Errorf("expected next cursor to be returned, got %q", page.NextCursor)
+	}
+}
+
+func TestAssetService_SearchAssets(t *testing.T) {
+	var gotQuery string
+	var gotLimit int
+	mockStore := &mocks.MockAssetStore{
+		SearchFunc: func(_ context.Context, _ uuid.UUID, query string, limit int) ([]models.SearchResult, error) {
+			gotQuery, gotLimit = query, limit
+			return nil, nil
+		},
+	}
+
+	service := assetServices.NewAssetService(mockStore)
+
+	if _, err := service.SearchAssets(context.Background(), uuid.New(), "  gen z ", 0); err != nil {
+		t.Fatalf("SearchAssets returned error: %v", err)
+	}
+	if gotQuery != "gen z" || gotLimit != storage.DefaultPageLimit {
+		t.Errorf("expected trimmed query and default limit, got %q and %d", gotQuery, gotLimit)
+	}
+
+	_, err := service.SearchAssets(context.Background(), uuid.New(), " ", 10)
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Violations[0].Field != "q" {
+		t.Errorf("expected a q violation for a blank query, got %v", err)
 	}
 }
@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"assetsApp/internal/models"
+	collectionServices "assetsApp/internal/services/collection"
+	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCollectionService_CreateCollection(t *testing.T) {
+	userID := uuid.New()
+	var stored *models.Collection
+	mockStore := &mocks.MockAssetStore{
+		AddCollectionFunc: func(_ context.Context, gotUser uuid.UUID, collection *models.Collection) error {
+			if gotUser != userID {
+				t.Errorf("expected user %v, got %v", userID, gotUser)
+			}
+			stored = collection
+			return nil
+		},
+	}
+
+	service := collectionServices.NewCollectionService(mockStore)
+
+	collection, err := service.CreateCollection(context.Background(), userID, "  Launch  ")
+	if err != nil {
+		t.Fatalf("CreateCollection returned error: %v", err)
+	}
+	if stored == nil || collection.ID == uuid.Nil || collection.ID != stored.ID {
+		t.Fatalf("expected a new ID to be stored and returned, got %+v and %+v", stored, collection)
+	}
+	if collection.Name != "Launch" {
+		t.Errorf("expected the name to be trimmed, got %q", collection.Name)
+	}
+}
+
+func TestCollectionService_RejectsBadNames(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		AddCollectionFunc: func(_ context.Context, _ uuid.UUID, _ *models.Collection) error {
+			t.Fatal("store must not be called for an invalid name")
+			return nil
+		},
+	}
+
+	service := collectionServices.NewCollectionService(mockStore)
+
+	for _, name := range []string{"", "   ", strings.Repeat("x", 256)} {
+		_, err := service.CreateCollection(context.Background(), uuid.New(), name)
+		var verr *models.ValidationError
+		if !errors.As(err, &verr) || verr.Violations[0].Field != "name" {
+			t.Errorf("expected a name violation for %q, got %v", name, err)
+		}
+	}
+}
+
+func TestCollectionService_SetAssetsRejectsDuplicates(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		SetCollectionAssetsFunc: func(_ context.Context, _, _ uuid.UUID, _ []string) error {
+			t.Fatal("store must not be called for an invalid list")
+			return nil
+		},
+	}
+
+	service := collectionServices.NewCollectionService(mockStore)
+
+	_, err := service.SetAssets(context.Background(), uuid.New(), uuid.New(), []string{"a", "", "a"})
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if len(verr.Violations) != 2 || verr.Violations[0].Field != "asset_ids[1]" || verr.Violations[1].Field != "asset_ids[2]" {
+		t.Errorf("unexpected violations: %+v", verr.Violations)
+	}
+}
@@ -3,7 +3,13 @@ package services_test
 import (
 	"assetsApp/internal/models"
 	favouriteServices "assetsApp/internal/services/favourite"
+	"assetsApp/internal/storage"
 	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
@@ -12,20 +18,23 @@ import (
 func TestFavouriteService_GetFavourites(t *testing.T) {
 	userID := uuid.New()
 	mockStore := &mocks.MockAssetStore{
-		GetFavouritesFunc: func(uid uuid.UUID) []models.Favourite {
+		GetFavouritesFunc: func(_ context.Context, uid uuid.UUID, _ storage.ListOptions) (storage.FavouritePage, error) {
 			if uid == userID {
-				return []models.Favourite{{Asset: &models.Chart{ID: "test-chart"}}}
+				return storage.FavouritePage{Items: []models.Favourite{{Asset: &models.Chart{ID: "test-chart"}}}}, nil
 			}
-			return nil
+			return storage.FavouritePage{}, nil
 		},
 	}
 
 	service := favouriteServices.NewFavouriteService(mockStore)
 
-	assets := service.GetFavourites(userID)
+	page, err := service.GetFavourites(context.Background(), userID, storage.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
-	if len(assets) != 1 {
-		t.Errorf("expected 1 asset, got %d", len(assets))
+	if len(page.Items) != 1 {
+		t.Errorf("expected 1 asset, got %d", len(page.Items))
 	}
 }
 
@@ -34,15 +43,39 @@ func TestFavouriteService_AddFavourite(t *testing.T) {
 	assetID := "test-asset"
 	assetType := "chart"
 	mockStore := &mocks.MockAssetStore{
-		AddFavouriteFunc: func(uid uuid.UUID, aid string, atype string) bool {
-			return uid == userID && aid == assetID && atype == assetType
+		AddFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string, atype string) error {
+			if uid == userID && aid == assetID && atype == assetType {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 
 	service := favouriteServices.NewFavouriteService(mockStore)
 
-	if !service.AddFavourite(userID, assetID, assetType) {
-		t.Error("AddFavourite returned false")
+	created, err := service.AddFavourite(context.Background(), userID, assetID, assetType)
+	if err != nil {
+		t.Errorf("AddFavourite returned error: %v", err)
+	}
+	if !created {
+		t.Error("expected a new favourite to be reported as created")
+	}
+}
+
+func TestFavouriteService_AddFavouriteAgain(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		AddFavouriteFunc: func(_ context.Context, _ uuid.UUID, aid string, _ string) error {
+			return fmt.Errorf("favourite %s: %w", aid, storage.ErrConflict)
+		},
+	}
+	service := favouriteServices.NewFavouriteService(mockStore)
+
+	created, err := service.AddFavourite(context.Background(), uuid.New(), "test-asset", "chart")
+	if err != nil {
+		t.Errorf("expected favouriting again to succeed, got %v", err)
+	}
+	if created {
+		t.Error("expected an existing favourite not to be reported as created")
 	}
 }
 
@@ -50,15 +83,18 @@ func TestFavouriteService_RemoveFavourite(t *testing.T) {
 	userID := uuid.New()
 	assetID := "test-asset"
 	mockStore := &mocks.MockAssetStore{
-		RemoveFavouriteFunc: func(uid uuid.UUID, aid string) bool {
-			return uid == userID && aid == assetID
+		RemoveFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string) error {
+			if uid == userID && aid == assetID {
+				return nil
+			}
+			return storage.ErrNotFound
 		},
 	}
 
 	service := favouriteServices.NewFavouriteService(mockStore)
 
-	if !service.RemoveFavourite(userID, assetID) {
-		t.Error("RemoveFavourite returned false")
+	if err := service.RemoveFavourite(context.Background(), userID, assetID); err != nil {
+		t.Errorf("RemoveFavourite returned error: %v", err)
 	}
 }
 
@@ -67,15 +103,15 @@ func TestFavouriteService_AddFavouriteForNonExistentAsset(t *testing.T) {
 	assetID := "non-existent-asset"
 	assetType := "chart"
 	mockStore := &mocks.MockAssetStore{
-		AddFavouriteFunc: func(uid uuid.UUID, aid string, atype string) bool {
-			return false // Simulate asset not found
+		AddFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string, atype string) error {
+			return storage.ErrNotFound // Simulate asset not found
 		},
 	}
 
 	service := favouriteServices.NewFavouriteService(mockStore)
 
-	if service.AddFavourite(userID, assetID, assetType) {
-		t.Error("AddFavourite returned true for non-existent asset")
+	if _, err := service.AddFavourite(context.Background(), userID, assetID, assetType); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for non-existent asset, got %v", err)
 	}
 }
 
@@ -83,14 +119,75 @@ func TestFavouriteService_RemoveNonExistentFavourite(t *testing.T) {
 	userID := uuid.New()
 	assetID := "non-existent-asset"
 	mockStore := &mocks.MockAssetStore{
-		RemoveFavouriteFunc: func(uid uuid.UUID, aid string) bool {
-			return false // Simulate favourite not found
+		RemoveFavouriteFunc: func(_ context.Context, uid uuid.UUID, aid string) error {
+			return storage.ErrNotFound // Simulate favourite not found
+		},
+	}
+
+	service := favouriteServices.NewFavouriteService(mockStore)
+
+	if err := service.RemoveFavourite(context.Background(), userID, assetID); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for non-existent favourite, got %v", err)
+	}
+}
+
+func TestFavouriteService_UpdateFavourite(t *testing.T) {
+	userID := uuid.New()
+	var stored models.FavouriteUpdate
+	mockStore := &mocks.MockAssetStore{
+		UpdateFavouriteFunc: func(_ context.Context, _ uuid.UUID, _ string, update models.FavouriteUpdate) error {
+			stored = update
+			return nil
 		},
 	}
+	service := favouriteServices.NewFavouriteService(mockStore)
+
+	pinned, note := true, "  weekly review "
+	if err := service.UpdateFavourite(context.Background(), userID, "chart-1", models.FavouriteUpdate{Pinned: &pinned, Note: &note}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Pinned == nil || !*stored.Pinned || stored.Note == nil || *stored.Note != "weekly review" {
+		t.Errorf("expected a pinned favourite with a trimmed note, got %+v", stored)
+	}
+
+	long := strings.Repeat("x", 1001)
+	err := service.UpdateFavourite(context.Background(), userID, "chart-1", models.FavouriteUpdate{Note: &long})
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) || verr.Violations[0].Field != "note" {
+		t.Errorf("expected a validation error on note, got %v", err)
+	}
+}
 
+func TestFavouriteService_MoveFavourite(t *testing.T) {
+	userID := uuid.New()
+	type move struct {
+		target string
+		after  bool
+	}
+	var got []move
+	mockStore := &mocks.MockAssetStore{
+		MoveFavouriteFunc: func(_ context.Context, _ uuid.UUID, _, targetID string, after bool) error {
+			got = append(got, move{targetID, after})
+			return nil
+		},
+	}
 	service := favouriteServices.NewFavouriteService(mockStore)
+	ctx := context.Background()
+
+	if err := service.MoveFavourite(ctx, userID, "chart-1", "chart-2", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := service.MoveFavourite(ctx, userID, "chart-1", "", "chart-3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []move{{"chart-2", false}, {"chart-3", true}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected moves %v, got %v", want, got)
+	}
 
-	if service.RemoveFavourite(userID, assetID) {
-		t.Error("RemoveFavourite returned true for non-existent favourite")
+	for _, args := range [][2]string{{"", ""}, {"chart-2", "chart-3"}} {
+		var verr *models.ValidationError
+		if err := service.MoveFavourite(ctx, userID, "chart-1", args[0], args[1]); !errors.As(err, &verr) {
+			t.Errorf("before=%q after=%q: expected a validation error, got %v", args[0], args[1], err)
+		}
 	}
 }
@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"assetsApp/internal/models"
+	shareServices "assetsApp/internal/services/share"
+	"assetsApp/internal/storage"
+	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestShareService_ShareAsset(t *testing.T) {
+	ownerID, granteeID := uuid.New(), uuid.New()
+	var stored models.Share
+	mockStore := &mocks.MockAssetStore{
+		ShareFunc: func(_ context.Context, share models.Share) error {
+			stored = share
+			return nil
+		},
+	}
+
+	service := shareServices.NewShareService(mockStore)
+
+	share, err := service.ShareAsset(context.Background(), ownerID, "test-asset", granteeID, models.PermissionEditor)
+	if err != nil {
+		t.Fatalf("ShareAsset returned error: %v", err)
+	}
+	want := models.Share{AssetID: "test-asset", OwnerID: ownerID, UserID: granteeID, Permission: models.PermissionEditor}
+	if share != want || stored != want {
+		t.Errorf("expected %+v to be stored and returned, got %+v and %+v", want, stored, share)
+	}
+}
+
+func TestShareService_ShareAssetRejectsBadGrants(t *testing.T) {
+	ownerID := uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		ShareFunc: func(_ context.Context, _ models.Share) error {
+			t.Fatal("store must not be called for an invalid grant")
+			return nil
+		},
+	}
+
+	service := shareServices.NewShareService(mockStore)
+
+	_, err := service.ShareAsset(context.Background(), ownerID, "test-asset", ownerID, models.PermissionOwner)
+	var verr *models.ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+	if len(verr.Violations) != 2 {
+		t.Errorf("expected permission and user_id violations, got %+v", verr.Violations)
+	}
+}
+
+func TestShareService_OnlyOwnerManagesShares(t *testing.T) {
+	editorID, ownerID := uuid.New(), uuid.New()
+	mockStore := &mocks.MockAssetStore{
+		AccessFunc: func(_ context.Context, _ uuid.UUID, _ string) (uuid.UUID, models.Permission, error) {
+			return ownerID, models.PermissionEditor, nil
+		},
+	}
+
+	service := shareServices.NewShareService(mockStore)
+	ctx := context.Background()
+
+	if _, err := service.GetShares(ctx, editorID, "test-asset"); !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("GetShares: expected ErrForbidden, got %v", err)
+	}
+	if _, err := service.ShareAsset(ctx, editorID, "test-asset", uuid.New(), models.PermissionViewer); !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("ShareAsset: expected ErrForbidden, got %v", err)
+	}
+	if err := service.UnshareAsset(ctx, editorID, "test-asset", uuid.New()); !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("UnshareAsset: expected ErrForbidden, got %v", err)
+	}
+}
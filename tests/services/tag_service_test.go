@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"assetsApp/internal/models"
+	tagServices "assetsApp/internal/services/tag"
+	"assetsApp/internal/storage"
+	"assetsApp/tests/mocks"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestTagService_AddTagTrimsAndTagsTheOwnersAsset(t *testing.T) {
+	editorID, ownerID := uuid.New(), uuid.New()
+	var gotOwner uuid.UUID
+	var gotTag string
+	mockStore := &mocks.MockAssetStore{
+		AccessFunc: func(_ context.Context, _ uuid.UUID, _ string) (uuid.UUID, models.Permission, error) {
+			return ownerID, models.PermissionEditor, nil
+		},
+		AddTagFunc: func(_ context.Context, owner uuid.UUID, _, tag string) error {
+			gotOwner, gotTag = owner, tag
+			return nil
+		},
+	}
+
+	service := tagServices.NewTagService(mockStore)
+
+	if _, err := service.AddTag(context.Background(), editorID, "test-asset", "  EMEA "); err != nil {
+		t.Fatalf("AddTag returned error: %v", err)
+	}
+	if gotOwner != ownerID || gotTag != "EMEA" {
+		t.Errorf("expected EMEA to be added under the owner, got %q under %v", gotTag, gotOwner)
+	}
+}
+
+func TestTagService_AddTagRejectsBadTags(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		AddTagFunc: func(_ context.Context, _ uuid.UUID, _, _ string) error {
+			t.Fatal("store must not be called for an invalid tag")
+			return nil
+		},
+	}
+
+	service := tagServices.NewTagService(mockStore)
+
+	for _, tag := range []string{"", " ", strings.Repeat("x", 65)} {
+		_, err := service.AddTag(context.Background(), uuid.New(), "test-asset", tag)
+		var verr *models.ValidationError
+		if !errors.As(err, &verr) || verr.Violations[0].Field != "tag" {
+			t.Errorf("expected a tag violation for %q, got %v", tag, err)
+		}
+	}
+}
+
+func TestTagService_ViewersCannotTag(t *testing.T) {
+	mockStore := &mocks.MockAssetStore{
+		AccessFunc: func(_ context.Context, _ uuid.UUID, _ string) (uuid.UUID, models.Permission, error) {
+			return uuid.New(), models.PermissionViewer, nil
+		},
+		RemoveTagFunc: func(_ context.Context, _ uuid.UUID, _, _ string) error {
+			t.Fatal("store must not be called without editor access")
+			return nil
+		},
+	}
+
+	service := tagServices.NewTagService(mockStore)
+
+	err := service.RemoveTag(context.Background(), uuid.New(), "test-asset", "EMEA")
+	if !errors.Is(err, storage.ErrForbidden) {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package storage_test
+
+import (
+	"assetsApp/internal/migrations"
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrations_DownAndUpAgain(t *testing.T) {
+	ctx := context.Background()
+	migrator, err := migrations.NewMigrator(pool)
+	assert.NoError(t, err)
+
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+
+	version, err := migrator.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(all), version)
+
+	assert.NoError(t, migrator.Down(ctx, len(all)))
+	version, err = migrator.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	assert.NoError(t, migrator.Up(ctx))
+	version, err = migrator.Version(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, len(all), version)
+}
+
+func TestMigrations_UpIsIdempotent(t *testing.T) {
+	migrator, err := migrations.NewMigrator(pool)
+	assert.NoError(t, err)
+
+	assert.NoError(t, migrator.Up(context.Background()))
+	assert.NoError(t, migrator.Up(context.Background()))
+}
+
+func TestMigrations_DetectsChecksumMismatch(t *testing.T) {
+	ctx := context.Background()
+	migrator, err := migrations.NewMigrator(pool)
+	assert.NoError(t, err)
+
+	var original string
+	assert.NoError(t, pool.QueryRow(ctx, "SELECT checksum FROM schema_migrations WHERE version=1").Scan(&original))
+	_, err = pool.Exec(ctx, "UPDATE schema_migrations SET checksum='tampered' WHERE version=1")
+	assert.NoError(t, err)
+	defer pool.Exec(ctx, "UPDATE schema_migrations SET checksum=$1 WHERE version=1", original)
+
+	assert.ErrorIs(t, migrator.Up(ctx), migrations.ErrChecksumMismatch)
+}
+
+// baselineSchema is the schema databases were created with before
+// migrations, from the original InitQuery.sql.
+const baselineSchema = `
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+CREATE TABLE users (id UUID PRIMARY KEY DEFAULT uuid_generate_v4(), name VARCHAR(255));
+CREATE TABLE assets (
+    asset_id VARCHAR(255) PRIMARY KEY, title VARCHAR(255), description TEXT,
+    asset_type VARCHAR(50), user_id UUID REFERENCES users(id)
+);
+CREATE TABLE favourites (
+    user_id UUID REFERENCES users(id), asset_id VARCHAR(255) REFERENCES assets(asset_id),
+    asset_type VARCHAR(50), PRIMARY KEY (user_id, asset_id)
+);
+CREATE TABLE charts (
+    id VARCHAR(255) PRIMARY KEY, title VARCHAR(255), description TEXT,
+    x_axis_title VARCHAR(255), y_axis_title VARCHAR(255)
+);
+CREATE TABLE chart_data (chart_id VARCHAR(255), datapoint_code VARCHAR(255), value NUMERIC);
+CREATE TABLE insights (id VARCHAR(255) PRIMARY KEY, description TEXT);
+CREATE TABLE audiences (
+    id VARCHAR(255) PRIMARY KEY, gender VARCHAR(50), country VARCHAR(255), age_group VARCHAR(50),
+    social_hours INT, purchases INT, description TEXT
+);
+`
+
+func TestMigrations_UpgradeBaselineDatabase(t *testing.T) {
+	ctx := context.Background()
+	migrator, err := migrations.NewMigrator(pool)
+	assert.NoError(t, err)
+	all, err := migrations.Load()
+	assert.NoError(t, err)
+	defer cleanup()
+
+	assert.NoError(t, migrator.Down(ctx, len(all)))
+	_, err = pool.Exec(ctx, baselineSchema)
+	assert.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		INSERT INTO users (id) VALUES ('a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11');
+		INSERT INTO assets (asset_id, asset_type, user_id) VALUES ('insight-1', 'insight', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11');
+		INSERT INTO insights (id, description) VALUES ('insight-1', 'from before migrations');
+		INSERT INTO assets (asset_id, asset_type, user_id) VALUES ('chart-1', 'chart', 'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11');
+		INSERT INTO charts (id, title) VALUES ('chart-1', 'Sales');
+		INSERT INTO chart_data (chart_id, datapoint_code, value) VALUES
+			('chart-1', 'JAN', 1), ('chart-1', 'JAN', 2), ('chart-1', 'FEB', 3), ('orphan-chart', 'JAN', 4);
+		INSERT INTO charts (id) VALUES ('orphan-chart');
+		INSERT INTO insights (id) VALUES ('orphan-insight');
+		INSERT INTO audiences (id) VALUES ('orphan-audience');
+	`)
+	assert.NoError(t, err)
+
+	// Repeated data points and rows without an asset are dropped rather
+	// than failing the constraints 0002 adds.
+	assert.NoError(t, migrator.Up(ctx))
+	page, err := store.Get(ctx, uuid.MustParse("a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"), storage.ListOptions{Sort: storage.SortByCreated})
+	if assert.NoError(t, err) && assert.Len(t, page.Items, 2) {
+		assert.Equal(t, "chart-1", page.Items[0].GetID())
+		assert.Equal(t, "insight-1", page.Items[1].GetID())
+		assert.Len(t, page.Items[0].(*models.Chart).Data, 2)
+	}
+	var orphans int
+	assert.NoError(t, pool.QueryRow(ctx, `
+		SELECT (SELECT count(*) FROM charts WHERE id = 'orphan-chart')
+		     + (SELECT count(*) FROM insights WHERE id = 'orphan-insight')
+		     + (SELECT count(*) FROM audiences WHERE id = 'orphan-audience')`).Scan(&orphans))
+	assert.Equal(t, 0, orphans)
+}
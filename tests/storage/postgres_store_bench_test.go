@@ -0,0 +1,145 @@
+package storage_test
+
+import (
+	"assetsApp/internal/models"
+	"assetsApp/internal/storage"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// seedAssets adds n assets for userID, cycling through every asset type, and
+// favourites all of them.
+func seedAssets(b *testing.B, userID uuid.UUID, n int) {
+	b.Helper()
+	ctx := context.Background()
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("asset-%04d", i)
+		var asset models.Asset
+		switch i % 3 {
+		case 0:
+			asset = &models.Chart{
+				ID:    id,
+				Title: "Chart " + id,
+				Data: []models.ChartData{
+					{DatapointCode: "SM_AGE_18_24", Value: 1},
+					{DatapointCode: "SM_AGE_25_34", Value: 2},
+					{DatapointCode: "SM_AGE_35_44", Value: 3},
+				},
+			}
+		case 1:
+			asset = &models.Insight{ID: id, Description: "Insight " + id}
+		default:
+			asset = &models.Audience{ID: id, Gender: "Female", Country: "GR", AgeGroup: "24-35"}
+		}
+		if err := store.Add(ctx, userID, asset); err != nil {
+			b.Fatalf("failed to seed asset %s: %v", id, err)
+		}
+		if err := store.AddFavourite(ctx, userID, id, asset.GetType()); err != nil {
+			b.Fatalf("failed to seed favourite %s: %v", id, err)
+		}
+	}
+}
+
+// getNPlusOne reproduces the previous listing strategy: one query for the
+// IDs, then one query per asset plus one per chart for its data points.
+func getNPlusOne(ctx context.Context, userID uuid.UUID) ([]models.Asset, error) {
+	rows, err := pool.Query(ctx, "SELECT asset_id, asset_type FROM assets WHERE user_id=$1", userID)
+	if err != nil {
+		return nil, err
+	}
+	type ref struct{ id, assetType string }
+	var refs []ref
+	for rows.Next() {
+		var r ref
+		if err := rows.Scan(&r.id, &r.assetType); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		refs = append(refs, r)
+	}
+	rows.Close()
+
+	var assets []models.Asset
+	for _, r := range refs {
+		switch r.assetType {
+		case "chart":
+			var c models.Chart
+			if err := pool.QueryRow(ctx, `SELECT id, title, description, x_axis_title, y_axis_title FROM charts WHERE id=$1`, r.id).
+				Scan(&c.ID, &c.Title, &c.Description, &c.XAxisTitle, &c.YAxisTitle); err != nil {
+				return nil, err
+			}
+			dataRows, err := pool.Query(ctx, `SELECT datapoint_code, value FROM chart_data WHERE chart_id=$1`, r.id)
+			if err != nil {
+				return nil, err
+			}
+			for dataRows.Next() {
+				var dp models.ChartData
+				if err := dataRows.Scan(&dp.DatapointCode, &dp.Value); err != nil {
+					dataRows.Close()
+					return nil, err
+				}
+				c.Data = append(c.Data, dp)
+			}
+			dataRows.Close()
+			assets = append(assets, &c)
+		case "insight":
+			var i models.Insight
+			if err := pool.QueryRow(ctx, `SELECT id, description FROM insights WHERE id=$1`, r.id).Scan(&i.ID, &i.Description); err != nil {
+				return nil, err
+			}
+			assets = append(assets, &i)
+		case "audience":
+			var a models.Audience
+			if err := pool.QueryRow(ctx, `SELECT id, gender, country, age_group, social_hours, purchases, description FROM audiences WHERE id=$1`, r.id).
+				Scan(&a.ID, &a.Gender, &a.Country, &a.AgeGroup, &a.SocialHours, &a.Purchases, &a.Description); err != nil {
+				return nil, err
+			}
+			assets = append(assets, &a)
+		}
+	}
+	return assets, nil
+}
+
+// BenchmarkListAssets compares the batched PostgresStore listing against the
+// old N+1 strategy. Run with:
+//
+//	go test ./tests/storage -run '^$' -bench ListAssets
+func BenchmarkListAssets(b *testing.B) {
+	for _, n := range []int{50, 500} {
+		b.Run(fmt.Sprintf("assets=%d", n), func(b *testing.B) {
+			defer cleanup()
+			ctx := context.Background()
+			userID := uuid.New()
+			seedAssets(b, userID, n)
+			opts := storage.ListOptions{Limit: n}
+
+			b.Run("NPlusOne", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					assets, err := getNPlusOne(ctx, userID)
+					if err != nil || len(assets) != n {
+						b.Fatalf("expected %d assets, got %d (err %v)", n, len(assets), err)
+					}
+				}
+			})
+			b.Run("Get", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					page, err := store.Get(ctx, userID, opts)
+					if err != nil || len(page.Items) != n {
+						b.Fatalf("expected %d assets, got %d (err %v)", n, len(page.Items), err)
+					}
+				}
+			})
+			b.Run("GetFavourites", func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					page, err := store.GetFavourites(ctx, userID, opts)
+					if err != nil || len(page.Items) != n {
+						b.Fatalf("expected %d favourites, got %d (err %v)", n, len(page.Items), err)
+					}
+				}
+			})
+		})
+	}
+}
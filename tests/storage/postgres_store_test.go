@@ -1,12 +1,15 @@
-
 package storage_test
 
 import (
+	"assetsApp/internal/migrations"
 	"assetsApp/internal/models"
 	"assetsApp/internal/storage"
+	"assetsApp/tests/contract"
 	"context"
+	"crypto/sha256"
 	"log"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -71,58 +74,21 @@ func TestMain(m *testing.M) {
 }
 
 func createTables() error {
-	ctx := context.Background()
-	_, err := pool.Exec(ctx, `
-		CREATE TABLE users (
-			id UUID PRIMARY KEY,
-			name VARCHAR(255)
-		);
-		CREATE TABLE assets (
-			asset_id VARCHAR(255) PRIMARY KEY,
-			title VARCHAR(255),
-			description TEXT,
-			asset_type VARCHAR(50),
-			user_id UUID REFERENCES users(id)
-		);
-		CREATE TABLE charts (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			title VARCHAR(255),
-			description TEXT,
-			x_axis_title VARCHAR(255),
-			y_axis_title VARCHAR(255)
-		);
-		CREATE TABLE chart_data (
-			chart_id VARCHAR(255) REFERENCES charts(id),
-			datapoint_code VARCHAR(255),
-			value FLOAT,
-			PRIMARY KEY (chart_id, datapoint_code)
-		);
-		CREATE TABLE insights (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			description TEXT
-		);
-		CREATE TABLE audiences (
-			id VARCHAR(255) PRIMARY KEY REFERENCES assets(asset_id),
-			gender VARCHAR(50),
-			country VARCHAR(255),
-			age_group VARCHAR(50),
-			social_hours INT,
-			purchases INT,
-			description TEXT
-		);
-		CREATE TABLE favourites (
-			user_id UUID REFERENCES users(id),
-			asset_id VARCHAR(255) REFERENCES assets(asset_id),
-			asset_type VARCHAR(50),
-			PRIMARY KEY (user_id, asset_id)
-		);
-	`)
-	return err
+	migrator, err := migrations.NewMigrator(pool)
+	if err != nil {
+		return err
+	}
+	return migrator.Up(context.Background())
 }
 
 func cleanup() {
 	ctx := context.Background()
 	_, err := pool.Exec(ctx, `
+		DELETE FROM api_keys;
+		DELETE FROM asset_tags;
+		DELETE FROM collection_assets;
+		DELETE FROM collections;
+		DELETE FROM asset_shares;
 		DELETE FROM favourites;
 		DELETE FROM chart_data;
 		DELETE FROM charts;
@@ -139,6 +105,7 @@ func cleanup() {
 func TestPostgresStore_AddAndGetAsset(t *testing.T) {
 	defer cleanup()
 
+	ctx := context.Background()
 	userID := uuid.New()
 	chart := &models.Chart{
 		ID:          "chart1",
@@ -151,75 +118,734 @@ func TestPostgresStore_AddAndGetAsset(t *testing.T) {
 		},
 	}
 
-	store.Add(userID, chart)
+	assert.NoError(t, store.Add(ctx, userID, chart))
 
-	assets := store.Get(userID)
-	assert.Len(t, assets, 1)
-	assert.Equal(t, chart.ID, assets[0].GetID())
+	page, err := store.Get(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, chart.ID, page.Items[0].GetID())
+}
+
+func TestPostgresStore_GetByID(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	chart := &models.Chart{
+		ID:    "chart1",
+		Title: "Test Chart",
+		Data:  []models.ChartData{{DatapointCode: "dp1", Value: 1}, {DatapointCode: "dp2", Value: 2}},
+	}
+	assert.NoError(t, store.Add(ctx, userID, chart))
+
+	asset, err := store.GetByID(ctx, userID, "chart1")
+	assert.NoError(t, err)
+	assert.Equal(t, chart, asset)
+
+	_, err = store.GetByID(ctx, uuid.New(), "chart1")
+	assert.ErrorIs(t, err, storage.ErrNotFound, "another user's asset must not be visible")
+
+	_, err = store.GetByID(ctx, userID, "missing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresStore_AddDuplicateAsset(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	chart := &models.Chart{ID: "chart1"}
+
+	assert.NoError(t, store.Add(ctx, userID, chart))
+	err := store.Add(ctx, userID, chart)
+	assert.ErrorIs(t, err, storage.ErrConflict)
 }
 
 func TestPostgresStore_RemoveAsset(t *testing.T) {
 	defer cleanup()
 
+	ctx := context.Background()
 	userID := uuid.New()
 	chart := &models.Chart{ID: "chart1"}
 
-	store.Add(userID, chart)
-	assert.Len(t, store.Get(userID), 1)
+	assert.NoError(t, store.Add(ctx, userID, chart))
+	page, err := store.Get(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
 
-	removed := store.Remove(userID, "chart1")
-	assert.True(t, removed)
-	assert.Len(t, store.Get(userID), 0)
+	assert.NoError(t, store.Remove(ctx, userID, "chart1", storage.AnyVersion))
+	page, err = store.Get(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 0)
 }
 
-func TestPostgresStore_EditDescription(t *testing.T) {
+func TestPostgresStore_RemoveMissingAsset(t *testing.T) {
 	defer cleanup()
 
+	err := store.Remove(context.Background(), uuid.New(), "missing", storage.AnyVersion)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresStore_Update(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
 	userID := uuid.New()
-	chart := &models.Chart{ID: "chart1", Description: "Old Description"}
+	chart := &models.Chart{
+		ID:    "chart1",
+		Title: "Old Title",
+		Data:  []models.ChartData{{DatapointCode: "dp1", Value: 1}, {DatapointCode: "dp2", Value: 2}},
+	}
+	assert.NoError(t, store.Add(ctx, userID, chart))
 
-	store.Add(userID, chart)
+	replacement := &models.Chart{
+		ID:          "chart1",
+		Title:       "New Title",
+		Description: "New Description",
+		XAxisTitle:  "X",
+		YAxisTitle:  "Y",
+		Data:        []models.ChartData{{DatapointCode: "dp3", Value: 3}},
+	}
+	assert.NoError(t, store.Update(ctx, userID, replacement))
 
-	edited := store.EditDescription(userID, "chart1", "New Description")
-	assert.True(t, edited)
+	asset, err := store.GetByID(ctx, userID, "chart1")
+	assert.NoError(t, err)
+	assert.Equal(t, replacement, asset)
 
-	assets := store.Get(userID)
-	assert.Len(t, assets, 1)
+	page, err := store.Get(ctx, userID, storage.ListOptions{Sort: storage.SortByTitle})
+	assert.NoError(t, err)
+	assert.Equal(t, "New Title", page.Items[0].GetTitle())
+}
 
-	retrievedChart, ok := assets[0].(*models.Chart)
-	assert.True(t, ok)
-	assert.Equal(t, "New Description", retrievedChart.Description)
+func TestPostgresStore_UpdateRejectsTypeChangeAndMissingAsset(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	assert.NoError(t, store.Add(ctx, userID, &models.Chart{ID: "chart1"}))
+
+	err := store.Update(ctx, userID, &models.Insight{ID: "chart1", Description: "now an insight"})
+	assert.ErrorIs(t, err, storage.ErrValidation)
+
+	err = store.Update(ctx, uuid.New(), &models.Chart{ID: "chart1"})
+	assert.ErrorIs(t, err, storage.ErrNotFound, "another user's asset must not be updated")
+
+	err = store.Update(ctx, userID, &models.Chart{ID: "missing"})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresStore_Versions(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	insight := &models.Insight{ID: "insight1", Description: "v1"}
+	assert.NoError(t, store.Add(ctx, userID, insight))
+	assert.Equal(t, int64(1), insight.GetVersion())
+
+	assert.NoError(t, store.Update(ctx, userID, &models.Insight{ID: "insight1", Description: "v2"}))
+	asset, err := store.GetByID(ctx, userID, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), asset.GetVersion())
+
+	stale := &models.Insight{Meta: models.Meta{Version: 1}, ID: "insight1", Description: "lost update"}
+	assert.ErrorIs(t, store.Update(ctx, userID, stale), storage.ErrPreconditionFailed)
+
+	current := &models.Insight{Meta: models.Meta{Version: 2}, ID: "insight1", Description: "v3"}
+	assert.NoError(t, store.Update(ctx, userID, current))
+	assert.Equal(t, int64(3), current.GetVersion())
+
+	page, err := store.Get(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), page.Items[0].GetVersion())
+
+	assert.ErrorIs(t, store.Remove(ctx, userID, "insight1", 2), storage.ErrPreconditionFailed)
+	assert.NoError(t, store.Remove(ctx, userID, "insight1", 3))
 }
 
 func TestPostgresStore_AddAndGetFavourites(t *testing.T) {
 	defer cleanup()
 
+	ctx := context.Background()
 	userID := uuid.New()
 	chart := &models.Chart{
 		ID:          "chart1",
 		Title:       "Test Chart",
 		Description: "Test Description",
 	}
-	store.Add(userID, chart)
+	assert.NoError(t, store.Add(ctx, userID, chart))
+
+	assert.NoError(t, store.AddFavourite(ctx, userID, "chart1", "chart"))
 
-	added := store.AddFavourite(userID, "chart1", "chart")
-	assert.True(t, added)
+	favourites, err := store.GetFavourites(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, favourites.Items, 1)
+	assert.Equal(t, "chart1", favourites.Items[0].Asset.GetID())
+}
+
+func TestPostgresStore_AddFavouriteMissingAsset(t *testing.T) {
+	defer cleanup()
 
-	favourites := store.GetFavourites(userID)
-	assert.Len(t, favourites, 1)
-	assert.Equal(t, "chart1", favourites[0].Asset.GetID())
+	err := store.AddFavourite(context.Background(), uuid.New(), "missing", "chart")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
 }
 
 func TestPostgresStore_RemoveFavourite(t *testing.T) {
 	defer cleanup()
 
+	ctx := context.Background()
 	userID := uuid.New()
 	chart := &models.Chart{ID: "chart1"}
-	store.Add(userID, chart)
-	store.AddFavourite(userID, "chart1", "chart")
-	assert.Len(t, store.GetFavourites(userID), 1)
+	assert.NoError(t, store.Add(ctx, userID, chart))
+	assert.NoError(t, store.AddFavourite(ctx, userID, "chart1", "chart"))
+	favourites, err := store.GetFavourites(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, favourites.Items, 1)
+
+	assert.NoError(t, store.RemoveFavourite(ctx, userID, "chart1"))
+	favourites, err = store.GetFavourites(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, favourites.Items, 0)
+}
+
+func TestPostgresStore_FavouriteContract(t *testing.T) {
+	defer cleanup()
+
+	contract.Favourites(t, func(t *testing.T) storage.AssetStore {
+		cleanup()
+		return store
+	})
+}
+
+func TestPostgresStore_FavouriteOrder(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	for _, id := range []string{"chart1", "chart2", "chart3"} {
+		assert.NoError(t, store.Add(ctx, userID, &models.Chart{ID: id}))
+	}
+	for _, id := range []string{"chart3", "chart1", "chart2"} {
+		assert.NoError(t, store.AddFavourite(ctx, userID, id, "chart"))
+	}
+	ids := func(opts storage.ListOptions) []string {
+		page, err := store.GetFavourites(ctx, userID, opts)
+		assert.NoError(t, err)
+		var ids []string
+		for _, fav := range page.Items {
+			ids = append(ids, fav.Asset.GetID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"chart3", "chart1", "chart2"}, ids(storage.ListOptions{}))
+
+	assert.NoError(t, store.MoveFavourite(ctx, userID, "chart2", "chart3", false))
+	assert.Equal(t, []string{"chart2", "chart3", "chart1"}, ids(storage.ListOptions{}))
+	assert.ErrorIs(t, store.MoveFavourite(ctx, userID, "chart2", "missing", true), storage.ErrNotFound)
+
+	pinned, note := true, "keep an eye on it"
+	assert.NoError(t, store.UpdateFavourite(ctx, userID, "chart1", models.FavouriteUpdate{Pinned: &pinned, Note: &note}))
+	assert.ErrorIs(t, store.UpdateFavourite(ctx, userID, "missing", models.FavouriteUpdate{Pinned: &pinned}), storage.ErrNotFound)
+	assert.Equal(t, []string{"chart1", "chart2", "chart3"}, ids(storage.ListOptions{}))
+
+	page, err := store.GetFavourites(ctx, userID, storage.ListOptions{Limit: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Items, 1) {
+		fav := page.Items[0]
+		assert.True(t, fav.Pinned)
+		assert.Equal(t, note, fav.Note)
+		assert.Equal(t, 3, fav.Position)
+		assert.False(t, fav.CreatedAt.IsZero())
+	}
+	assert.Equal(t, []string{"chart2", "chart3"}, ids(storage.ListOptions{Limit: 2, Cursor: page.NextCursor}))
+
+	_, err = store.Get(ctx, userID, storage.ListOptions{Sort: storage.SortByPosition})
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestPostgresStore_ConcurrentAddFavouritesGetDistinctPositions(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	ids := []string{"chart1", "chart2", "chart3", "chart4", "chart5", "chart6", "chart7", "chart8"}
+	for _, id := range ids {
+		assert.NoError(t, store.Add(ctx, userID, &models.Chart{ID: id}))
+	}
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, store.AddFavourite(ctx, userID, id, "chart"))
+		}()
+	}
+	wg.Wait()
+
+	page, err := store.GetFavourites(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	positions := make(map[int]bool)
+	for _, fav := range page.Items {
+		positions[fav.Position] = true
+	}
+	assert.Len(t, positions, len(ids), "every favourite should have its own position")
+}
+
+func TestPostgresStore_GetPaginated(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	for _, id := range []string{"c", "a", "e", "b", "d"} {
+		assert.NoError(t, store.Add(ctx, userID, &models.Insight{ID: id}))
+	}
+
+	var ids []string
+	opts := storage.ListOptions{Limit: 2, Sort: storage.SortByID, Desc: true}
+	for {
+		page, err := store.Get(ctx, userID, opts)
+		assert.NoError(t, err)
+		for _, a := range page.Items {
+			ids = append(ids, a.GetID())
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"e", "d", "c", "b", "a"}, ids)
+}
+
+func TestPostgresStore_GetSortedByCreated(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	for _, id := range []string{"z", "y", "x"} {
+		assert.NoError(t, store.Add(ctx, userID, &models.Insight{ID: id}))
+	}
+
+	page, err := store.Get(ctx, userID, storage.ListOptions{Limit: 2, Sort: storage.SortByCreated})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, "z", page.Items[0].GetID())
+	assert.NotEmpty(t, page.NextCursor)
+
+	page, err = store.Get(ctx, userID, storage.ListOptions{Limit: 2, Sort: storage.SortByCreated, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "x", page.Items[0].GetID())
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestPostgresStore_GetLoadsEveryAssetType(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	userID := uuid.New()
+	assert.NoError(t, store.Add(ctx, userID, &models.Chart{
+		ID:   "a-chart",
+		Data: []models.ChartData{{DatapointCode: "dp1", Value: 1}, {DatapointCode: "dp2", Value: 2}},
+	}))
+	assert.NoError(t, store.Add(ctx, userID, &models.Chart{
+		ID:   "b-chart",
+		Data: []models.ChartData{{DatapointCode: "dp3", Value: 3}},
+	}))
+	assert.NoError(t, store.Add(ctx, userID, &models.Insight{ID: "c-insight", Description: "insight"}))
+	assert.NoError(t, store.Add(ctx, userID, &models.Audience{ID: "d-audience", Country: "GR", SocialHours: 4}))
+
+	page, err := store.Get(ctx, userID, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 4)
+
+	first, ok := page.Items[0].(*models.Chart)
+	assert.True(t, ok)
+	assert.Len(t, first.Data, 2)
+	second, ok := page.Items[1].(*models.Chart)
+	assert.True(t, ok)
+	assert.Len(t, second.Data, 1)
+	assert.Equal(t, "insight", page.Items[2].(*models.Insight).Description)
+	assert.Equal(t, 4, page.Items[3].(*models.Audience).SocialHours)
+}
+
+func TestPostgresStore_Sharing(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	owner, grantee, stranger := uuid.New(), uuid.New(), uuid.New()
+	assert.NoError(t, store.Add(ctx, owner, &models.Insight{ID: "insight1", Description: "shared"}))
+
+	ownerID, perm, err := store.Access(ctx, owner, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, owner, ownerID)
+	assert.Equal(t, models.PermissionOwner, perm)
+
+	_, _, err = store.Access(ctx, stranger, "insight1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	share := models.Share{AssetID: "insight1", OwnerID: owner, UserID: grantee, Permission: models.PermissionViewer}
+	assert.NoError(t, store.Share(ctx, share))
+	share.Permission = models.PermissionEditor
+	assert.NoError(t, store.Share(ctx, share))
+
+	ownerID, perm, err = store.Access(ctx, grantee, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, owner, ownerID)
+	assert.Equal(t, models.PermissionEditor, perm)
+
+	shares, err := store.GetShares(ctx, owner, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Share{share}, shares)
+
+	page, err := store.GetSharedWithMe(ctx, grantee, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, owner, page.Items[0].OwnerID)
+	assert.Equal(t, models.PermissionEditor, page.Items[0].Permission)
+	assert.Equal(t, "shared", page.Items[0].Asset.GetDescription())
+
+	// Only the owner's assets can be shared.
+	err = store.Share(ctx, models.Share{AssetID: "insight1", OwnerID: stranger, UserID: grantee, Permission: models.PermissionViewer})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	// Revoking access also drops the grantee's favourite.
+	assert.NoError(t, store.AddFavourite(ctx, grantee, "insight1", "insight"))
+	assert.NoError(t, store.Unshare(ctx, owner, "insight1", grantee))
+	assert.ErrorIs(t, store.Unshare(ctx, owner, "insight1", grantee), storage.ErrNotFound)
+
+	favs, err := store.GetFavourites(ctx, grantee, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, favs.Items)
+	_, _, err = store.Access(ctx, grantee, "insight1")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresStore_APIKeys(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Microsecond)
+	key := models.APIKey{
+		ID:        uuid.New(),
+		Name:      "dashboards",
+		Prefix:    "0123456789ab",
+		Scopes:    []string{"assets:read", "favourites:write"},
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		ExpiresAt: &expires,
+	}
+	hash := sha256.Sum256([]byte("secret"))
+	assert.NoError(t, store.AddAPIKey(ctx, key, hash[:]))
+	assert.ErrorIs(t, store.AddAPIKey(ctx, key, hash[:]), storage.ErrConflict)
+
+	got, gotHash, err := store.GetAPIKey(ctx, key.Prefix)
+	assert.NoError(t, err)
+	assert.Equal(t, hash[:], gotHash)
+	assert.Equal(t, key.Scopes, got.Scopes)
+	assert.True(t, got.ExpiresAt.Equal(expires))
+	assert.Nil(t, got.RevokedAt)
+
+	_, _, err = store.GetAPIKey(ctx, "missing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	revokedAt := time.Now().UTC().Truncate(time.Microsecond)
+	assert.NoError(t, store.RevokeAPIKey(ctx, key.ID, revokedAt))
+	assert.NoError(t, store.RevokeAPIKey(ctx, key.ID, revokedAt.Add(time.Hour)))
+	assert.ErrorIs(t, store.RevokeAPIKey(ctx, uuid.New(), revokedAt), storage.ErrNotFound)
+
+	keys, err := store.ListAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+	assert.True(t, keys[0].RevokedAt.Equal(revokedAt))
+}
+
+func TestPostgresStore_ListUsers(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, store.Add(ctx, uuid.New(), &models.Insight{ID: uuid.NewString()}))
+	}
+
+	page, err := store.ListUsers(ctx, storage.ListOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	rest, err := store.ListUsers(ctx, storage.ListOptions{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, rest.Items, 1)
+	assert.Empty(t, rest.NextCursor)
+	assert.Less(t, page.Items[1].ID.String(), rest.Items[0].ID.String())
 
-	removed := store.RemoveFavourite(userID, "chart1")
-	assert.True(t, removed)
-	assert.Len(t, store.GetFavourites(userID), 0)
+	_, err = store.ListUsers(ctx, storage.ListOptions{Sort: storage.SortByTitle})
+	assert.ErrorIs(t, err, storage.ErrValidation)
+}
+
+func TestPostgresStore_Reassign(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	assert.NoError(t, store.Add(ctx, alice, &models.Insight{ID: "insight1"}))
+	assert.NoError(t, store.Share(ctx, models.Share{AssetID: "insight1", OwnerID: alice, UserID: bob, Permission: models.PermissionViewer}))
+
+	previous, err := store.Reassign(ctx, "insight1", bob)
+	assert.NoError(t, err)
+	assert.Equal(t, alice, previous)
+
+	owner, perm, err := store.Access(ctx, bob, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, bob, owner)
+	assert.Equal(t, models.PermissionOwner, perm)
+	shares, err := store.GetShares(ctx, bob, "insight1")
+	assert.NoError(t, err)
+	assert.Empty(t, shares)
+
+	asset, err := store.GetByID(ctx, bob, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), asset.GetVersion())
+
+	_, err = store.Reassign(ctx, "missing", bob)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestPostgresStore_PurgeUser(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	assert.NoError(t, store.Add(ctx, alice, &models.Chart{ID: "alice-chart", Data: []models.ChartData{{DatapointCode: "dp1", Value: 1}}}))
+	assert.NoError(t, store.Add(ctx, bob, &models.Insight{ID: "bob-insight"}))
+	assert.NoError(t, store.Share(ctx, models.Share{AssetID: "alice-chart", OwnerID: alice, UserID: bob, Permission: models.PermissionViewer}))
+	assert.NoError(t, store.Share(ctx, models.Share{AssetID: "bob-insight", OwnerID: bob, UserID: alice, Permission: models.PermissionEditor}))
+	assert.NoError(t, store.AddFavourite(ctx, bob, "alice-chart", "chart"))
+	assert.NoError(t, store.AddFavourite(ctx, alice, "bob-insight", "insight"))
+
+	assert.NoError(t, store.PurgeUser(ctx, alice))
+	assert.ErrorIs(t, store.PurgeUser(ctx, alice), storage.ErrNotFound)
+
+	favs, err := store.GetFavourites(ctx, bob, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, favs.Items)
+	shares, err := store.GetShares(ctx, bob, "bob-insight")
+	assert.NoError(t, err)
+	assert.Empty(t, shares)
+
+	users, err := store.ListUsers(ctx, storage.ListOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, users.Items, 1)
+	assert.Equal(t, bob, users.Items[0].ID)
+}
+
+func TestPostgresStore_Collections(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	for _, id := range []string{"insight1", "insight2", "insight3"} {
+		assert.NoError(t, store.Add(ctx, alice, &models.Insight{ID: id}))
+	}
+	assert.NoError(t, store.Add(ctx, bob, &models.Insight{ID: "bob-insight"}))
+
+	collection := models.Collection{ID: uuid.New(), Name: "Launch"}
+	assert.NoError(t, store.AddCollection(ctx, alice, &collection))
+	assert.ErrorIs(t, store.AddCollection(ctx, alice, &models.Collection{ID: uuid.New(), Name: "Launch"}), storage.ErrConflict)
+
+	assert.NoError(t, store.AddToCollection(ctx, alice, collection.ID, "insight2"))
+	assert.NoError(t, store.AddToCollection(ctx, alice, collection.ID, "insight1"))
+	assert.NoError(t, store.AddToCollection(ctx, alice, collection.ID, "insight2"))
+	assert.ErrorIs(t, store.AddToCollection(ctx, alice, collection.ID, "bob-insight"), storage.ErrNotFound)
+	assert.ErrorIs(t, store.AddToCollection(ctx, bob, collection.ID, "bob-insight"), storage.ErrNotFound)
+
+	got, err := store.GetCollection(ctx, alice, collection.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insight2", "insight1"}, got.AssetIDs)
+	_, err = store.GetCollection(ctx, bob, collection.ID)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	assert.NoError(t, store.SetCollectionAssets(ctx, alice, collection.ID, []string{"insight3", "insight1"}))
+	assert.ErrorIs(t, store.SetCollectionAssets(ctx, alice, collection.ID, []string{"insight3", "bob-insight"}), storage.ErrNotFound)
+	assets, err := store.GetCollectionAssets(ctx, alice, collection.ID)
+	assert.NoError(t, err)
+	assert.Len(t, assets, 2)
+	assert.Equal(t, "insight3", assets[0].GetID())
+	assert.Equal(t, "insight1", assets[1].GetID())
+
+	page, err := store.Get(ctx, alice, storage.ListOptions{Collection: collection.ID})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, "insight1", page.Items[0].GetID())
+
+	assert.NoError(t, store.Remove(ctx, alice, "insight3", storage.AnyVersion))
+	got, err = store.GetCollection(ctx, alice, collection.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"insight1"}, got.AssetIDs)
+
+	assert.ErrorIs(t, store.RemoveFromCollection(ctx, alice, collection.ID, "insight2"), storage.ErrNotFound)
+	assert.NoError(t, store.RenameCollection(ctx, alice, collection.ID, "Launch v2"))
+	collections, err := store.GetCollections(ctx, alice)
+	assert.NoError(t, err)
+	assert.Len(t, collections, 1)
+	assert.Equal(t, "Launch v2", collections[0].Name)
+
+	assert.NoError(t, store.RemoveCollection(ctx, alice, collection.ID))
+	assert.ErrorIs(t, store.RemoveCollection(ctx, alice, collection.ID), storage.ErrNotFound)
+	_, err = store.GetByID(ctx, alice, "insight1")
+	assert.NoError(t, err)
+}
+
+func TestPostgresStore_Tags(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice, bob := uuid.New(), uuid.New()
+	tagged := map[string][]string{
+		"insight1": {"EMEA", "Q3"},
+		"insight2": {"EMEA"},
+		"insight3": {"APAC", "Q3"},
+	}
+	for id, tags := range tagged {
+		assert.NoError(t, store.Add(ctx, alice, &models.Insight{ID: id}))
+		for _, tag := range tags {
+			assert.NoError(t, store.AddTag(ctx, alice, id, tag))
+		}
+	}
+	assert.NoError(t, store.AddTag(ctx, alice, "insight1", "EMEA"))
+	assert.ErrorIs(t, store.AddTag(ctx, bob, "insight1", "mine"), storage.ErrNotFound)
+	assert.ErrorIs(t, store.AddTag(ctx, alice, "missing", "Q3"), storage.ErrNotFound)
+
+	tags, err := store.GetTags(ctx, alice, "insight1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"EMEA", "Q3"}, tags)
+
+	ids := func(opts storage.ListOptions) []string {
+		page, err := store.Get(ctx, alice, opts)
+		assert.NoError(t, err)
+		var ids []string
+		for _, a := range page.Items {
+			ids = append(ids, a.GetID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"insight1"}, ids(storage.ListOptions{Tags: []string{"EMEA", "Q3"}}))
+	assert.Equal(t, []string{"insight1", "insight2"}, ids(storage.ListOptions{Tags: []string{"EMEA", "EMEA"}}))
+	assert.Equal(t, []string{"insight1", "insight2", "insight3"}, ids(storage.ListOptions{Tags: []string{"EMEA", "APAC"}, AnyTag: true}))
+	assert.Equal(t, []string{"insight3"}, ids(storage.ListOptions{Tags: []string{"Q3"}, Desc: true, Limit: 1}))
+
+	cloud, err := store.TagCounts(ctx, alice)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TagCount{{Tag: "EMEA", Count: 2}, {Tag: "Q3", Count: 2}, {Tag: "APAC", Count: 1}}, cloud)
+
+	assert.NoError(t, store.RemoveTag(ctx, alice, "insight2", "EMEA"))
+	assert.ErrorIs(t, store.RemoveTag(ctx, alice, "insight2", "EMEA"), storage.ErrNotFound)
+
+	// Tags follow a reassigned asset and go with a deleted one
+	_, err = store.Reassign(ctx, "insight3", bob)
+	assert.NoError(t, err)
+	assert.NoError(t, store.Remove(ctx, alice, "insight1", storage.AnyVersion))
+	cloud, err = store.TagCounts(ctx, alice)
+	assert.NoError(t, err)
+	assert.Empty(t, cloud)
+	cloud, err = store.TagCounts(ctx, bob)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TagCount{{Tag: "APAC", Count: 1}, {Tag: "Q3", Count: 1}}, cloud)
+}
+
+func TestPostgresStore_Search(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice := uuid.New()
+	assets := []models.Asset{
+		&models.Chart{ID: "chart1", Title: "Purchases by age", XAxisTitle: "Age group", YAxisTitle: "Purchases",
+			Data: []models.ChartData{{DatapointCode: "SM_AGE_18_24", Value: 1}}},
+		&models.Insight{ID: "insight1", Description: "Gen Z purchases peak on weekends"},
+		&models.Insight{ID: "insight2", Description: "Millennials prefer email"},
+	}
+	for _, a := range assets {
+		assert.NoError(t, store.Add(ctx, alice, a))
+	}
+
+	ids := func(query string) []string {
+		results, err := store.Search(ctx, alice, query, 10)
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, r := range results {
+			ids = append(ids, r.Asset.GetID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"insight1"}, ids("gen z purchases"))
+	assert.Equal(t, []string{"chart1", "insight1"}, ids("purchase"))
+	assert.Equal(t, []string{"chart1"}, ids("SM_AGE_18_24"))
+	assert.Equal(t, []string{}, ids("tractors"))
+
+	results, err := store.Search(ctx, alice, "weekends", 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Snippet, "<mark>weekends</mark>")
+	assert.Greater(t, results[0].Rank, 0.0)
+
+	// Writes reindex the asset
+	assert.NoError(t, store.Update(ctx, alice, &models.Insight{ID: "insight2", Description: "Millennials prefer video"}))
+	assert.Equal(t, []string{"insight2"}, ids("video"))
+	assert.Equal(t, []string{}, ids("email"))
+	assert.NoError(t, store.Update(ctx, alice, &models.Insight{ID: "insight2", Description: "Boomers prefer radio"}))
+	assert.Equal(t, []string{"insight2"}, ids("radio"))
+
+	// Snippets are HTML-escaped
+	assert.NoError(t, store.Update(ctx, alice, &models.Insight{ID: "insight2", Description: "Boomers <script>alert(1)</script> radio"}))
+	results, err = store.Search(ctx, alice, "radio", 10)
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Contains(t, results[0].Snippet, "&lt;script&gt;")
+		assert.Contains(t, results[0].Snippet, "<mark>radio</mark>")
+		assert.NotContains(t, results[0].Snippet, "<script>")
+	}
+
+	results, err = store.Search(ctx, uuid.New(), "purchases", 10)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestPostgresStore_GetFilteredByAttributes(t *testing.T) {
+	defer cleanup()
+
+	ctx := context.Background()
+	alice := uuid.New()
+	assets := []models.Asset{
+		&models.Insight{ID: "insight1"},
+		&models.Audience{ID: "aud1", Gender: "Female", Country: "GR", SocialHours: 3, Purchases: 2},
+		&models.Audience{ID: "aud2", Gender: "Female", Country: "GR", SocialHours: 5, Purchases: 12},
+		&models.Audience{ID: "aud3", Gender: "Male", Country: "DK", SocialHours: 1, Purchases: 4},
+	}
+	for _, a := range assets {
+		assert.NoError(t, store.Add(ctx, alice, a))
+	}
+
+	cond := func(key string, values ...string) storage.Condition {
+		c, err := storage.ParseCondition("audience", key, values)
+		assert.NoError(t, err)
+		return c
+	}
+	ids := func(opts storage.ListOptions) []string {
+		page, err := store.Get(ctx, alice, opts)
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, a := range page.Items {
+			ids = append(ids, a.GetID())
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"insight1"}, ids(storage.ListOptions{Type: "insight"}))
+	assert.Equal(t, []string{"aud1", "aud2"}, ids(storage.ListOptions{Type: "audience", Where: []storage.Condition{cond("country", "GR")}}))
+	assert.Equal(t, []string{"aud1", "aud3"}, ids(storage.ListOptions{Type: "audience", Where: []storage.Condition{cond("country", "GR,DK"), cond("purchases_lt", "10")}}))
+	assert.Equal(t, []string{"aud1"}, ids(storage.ListOptions{Type: "audience", Where: []storage.Condition{
+		cond("country", "GR"), cond("gender", "Female"), cond("social_hours_gte", "3"), cond("purchases_lt", "10"),
+	}}))
+
+	_, err := store.Get(ctx, alice, storage.ListOptions{Where: []storage.Condition{cond("country", "GR")}})
+	assert.ErrorIs(t, err, storage.ErrValidation)
 }